@@ -0,0 +1,80 @@
+package miniorm
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// aggregate scans aggregateExpression over the rows of tableName matching expression into result
+func aggregate(
+	ctx context.Context,
+	db DBWrapper,
+	tableName string,
+	aggregateExpression exp.Expression,
+	expression exp.Expression,
+	result interface{},
+) error {
+	if result == nil {
+		return ErrNilEntry
+	}
+
+	// A non-nullable result is scanned through a pointer to it, which is nil for NULL
+	destination := result
+	resultValue := reflect.ValueOf(result)
+	nullableValue := reflect.Value{}
+
+	if resultValue.Kind() == reflect.Ptr && !isNullableType(resultValue.Type().Elem()) {
+		nullableValue = reflect.New(resultValue.Type())
+		destination = nullableValue.Interface()
+	}
+
+	_, err := db.Select(aggregateExpression).
+		From(tableName).
+		Prepared(true).
+		Where(expression).
+		ScanValContext(ctx, destination)
+	if err != nil || !nullableValue.IsValid() {
+		return err
+	}
+
+	if nullableValue.Elem().IsNil() {
+		return ErrNotFound
+	}
+
+	resultValue.Elem().Set(nullableValue.Elem().Elem())
+
+	return nil
+}
+
+// isNullableType returns whether a value of valueType can be scanned from NULL
+func isNullableType(valueType reflect.Type) bool {
+	switch valueType.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice:
+		return true
+	default:
+		return reflect.PtrTo(valueType).Implements(scannerType)
+	}
+}
+
+// exists returns whether tableName has a row matching expression
+func exists(ctx context.Context, db DBWrapper, tableName string, expression exp.Expression) (bool, error) {
+	var one int64
+
+	return getExistsSelectDataset(db, tableName, expression).ScanValContext(ctx, &one)
+}
+
+// getExistsSelectDataset returns SELECT 1 ... LIMIT 1, or SELECT TOP 1 1 ... on MSSQL, instead of a COUNT(*) which
+// would have to visit all matching rows
+func getExistsSelectDataset(db DBWrapper, tableName string, expression exp.Expression) *goqu.SelectDataset {
+	return db.Select(goqu.L("1")).
+		From(tableName).
+		Prepared(true).
+		Where(expression).
+		Limit(1)
+}
@@ -0,0 +1,30 @@
+package miniorm
+
+import (
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExistsSelectDataset(t *testing.T) {
+	t.Parallel()
+
+	testCaseList := []struct {
+		dialect     string
+		expectedSQL string
+	}{
+		{"mysql", "SELECT 1 FROM `entries` WHERE (`id` = ?) LIMIT ?"},
+		{"postgres", `SELECT 1 FROM "entries" WHERE ("id" = $1) LIMIT $2`},
+		{"sqlite3", "SELECT 1 FROM `entries` WHERE (`id` = ?) LIMIT ?"},
+		{"sqlserver", `SELECT  TOP (@p1) 1 FROM "entries" WHERE ("id" = @p2)`},
+	}
+
+	for _, testCase := range testCaseList {
+		db := goqu.New(testCase.dialect, nil)
+		sql, args, err := getExistsSelectDataset(db, "entries", goqu.C("id").Eq(2)).ToSQL()
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedSQL, sql)
+		assert.ElementsMatch(t, []interface{}{int64(1), int64(2)}, args)
+	}
+}
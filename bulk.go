@@ -0,0 +1,131 @@
+package miniorm
+
+import (
+	"context"
+)
+
+const (
+	defaultCreateManyChunkSize = 100
+)
+
+var (
+	// Maximum number of parameters in a single statement. go-mssqldb sends the statements through sp_executesql, whose
+	// own 2 parameters count toward the limit of 2100.
+	configDriverTypeToMaxParameters = map[DriverType]int{
+		DriverTypeMSSQL:    2098,
+		DriverTypeMySQL:    65535,
+		DriverTypePostgres: 65535,
+		DriverTypeSQLite3:  32766,
+	}
+
+	// Maximum number of rows in a single VALUES list
+	configDriverTypeToMaxRows = map[DriverType]int{
+		DriverTypeMSSQL: 1000,
+	}
+)
+
+type createChunkFunc func(ctx context.Context, db DBWrapper, tableName string, entryList []interface{}) error
+
+// getChunkSize returns the configured chunk size, capped by the parameter and row limits of the database engine
+func getChunkSize(databaseConfig DatabaseConfig, columnCount int) int {
+	chunkSize := databaseConfig.CreateManyChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultCreateManyChunkSize
+	}
+
+	maxParameters, ok := configDriverTypeToMaxParameters[databaseConfig.Driver]
+	if ok && columnCount > 0 && chunkSize*columnCount > maxParameters {
+		chunkSize = maxParameters / columnCount
+	}
+
+	if maxRows, ok := configDriverTypeToMaxRows[databaseConfig.Driver]; ok && chunkSize > maxRows {
+		chunkSize = maxRows
+	}
+
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+
+	return chunkSize
+}
+
+func chunkEntryList(entryList []interface{}, chunkSize int) [][]interface{} {
+	chunks := make([][]interface{}, 0, (len(entryList)+chunkSize-1)/chunkSize)
+
+	for start := 0; start < len(entryList); start += chunkSize {
+		end := start + chunkSize
+		if end > len(entryList) {
+			end = len(entryList)
+		}
+
+		chunks = append(chunks, entryList[start:end])
+	}
+
+	return chunks
+}
+
+// createMany runs the engine specific createChunk for chunks of entries in a single transaction, between the
+// BeforeCreate() and OnCreate() hooks and the AfterCreate() hooks of all entries
+func createMany(
+	ctx context.Context,
+	orm ORM,
+	entryInfoProvider *entryInfoProvider,
+	databaseConfig DatabaseConfig,
+	entries interface{},
+	createChunk createChunkFunc,
+) error {
+	if entries == nil {
+		return ErrNilEntry
+	}
+
+	entryList, err := entryInfoProvider.GetEntryList(entries)
+	if err != nil {
+		return err
+	}
+
+	if len(entryList) == 0 {
+		return nil
+	}
+
+	tableNames, entryGroups, err := entryInfoProvider.GroupEntriesByTableName(entryList)
+	if err != nil {
+		return err
+	}
+
+	return unwrapHookError(orm.WithTxContext(ctx, nil, func(txCtx context.Context, txORM ORM) error {
+		for _, entry := range entryList {
+			if beforeCreatorEntry, ok := entry.(BeforeCreator); ok {
+				if err := runHook(txCtx, txORM, beforeCreatorEntry.BeforeCreate); err != nil {
+					return err
+				}
+			}
+
+			entryInfoProvider.OnCreateIfEntryIsOnCreator(entry)
+		}
+
+		for _, tableName := range tableNames {
+			entryGroup := entryGroups[tableName]
+
+			insertRecord, err := entryInfoProvider.GetInsertRecord(entryGroup[0])
+			if err != nil {
+				return err
+			}
+
+			for _, chunk := range chunkEntryList(entryGroup, getChunkSize(databaseConfig, len(insertRecord))) {
+				if err := createChunk(txCtx, txORM.GetDBWrapper(), tableName, chunk); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, entry := range entryList {
+			if afterCreatorEntry, ok := entry.(AfterCreator); ok {
+				if err := runHook(txCtx, txORM, afterCreatorEntry.AfterCreate); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}))
+}
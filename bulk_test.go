@@ -0,0 +1,27 @@
+package miniorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetChunkSize(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, defaultCreateManyChunkSize, getChunkSize(DatabaseConfig{Driver: DriverTypeMySQL}, 5))
+	assert.Equal(t, 10, getChunkSize(DatabaseConfig{Driver: DriverTypeMySQL, CreateManyChunkSize: 10}, 5))
+	assert.Equal(t, 419, getChunkSize(DatabaseConfig{Driver: DriverTypeMSSQL, CreateManyChunkSize: 1000}, 5))
+	assert.Equal(t, 1, getChunkSize(DatabaseConfig{Driver: DriverTypeMSSQL}, 5000))
+	assert.Equal(t, 1000, getChunkSize(DatabaseConfig{Driver: DriverTypeMSSQL, CreateManyChunkSize: 5000}, 1))
+	assert.Equal(t, 5000, getChunkSize(DatabaseConfig{Driver: DriverTypeMySQL, CreateManyChunkSize: 5000}, 1))
+}
+
+func TestChunkEntryList(t *testing.T) {
+	t.Parallel()
+
+	entryList := []interface{}{1, 2, 3, 4, 5}
+	assert.Equal(t, [][]interface{}{{1, 2}, {3, 4}, {5}}, chunkEntryList(entryList, 2))
+	assert.Equal(t, [][]interface{}{{1, 2, 3, 4, 5}}, chunkEntryList(entryList, 5))
+	assert.Equal(t, [][]interface{}{}, chunkEntryList([]interface{}{}, 2))
+}
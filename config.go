@@ -2,6 +2,7 @@ package miniorm
 
 type DriverType string
 type SQLite3TransactionMode string
+type CreateOrUpdateMode string
 
 type Logger interface {
 	Printf(format string, v ...interface{})
@@ -15,6 +16,9 @@ const (
 
 	SQLite3TransactionModeRetry SQLite3TransactionMode = "retry"
 	SQLite3TransactionModeMutex SQLite3TransactionMode = "mutex"
+
+	CreateOrUpdateModeTransaction CreateOrUpdateMode = "transaction"
+	CreateOrUpdateModeUpsert      CreateOrUpdateMode = "upsert"
 )
 
 type DatabaseConfig struct {
@@ -28,11 +32,24 @@ type DatabaseConfig struct {
 	MaxOpenConnections         int                    `yaml:"maxOpenConnections" json:"maxOpenConnections"`
 	MaxIdleConnections         int                    `yaml:"maxIdleConnections" json:"maxIdleConnections"`
 	ConnMaxLifetimeInMinutes   int                    `yaml:"connMaxLifetimeInMinutes" json:"connMaxLifetimeInMinutes"`
+	CreateOrUpdateMode         CreateOrUpdateMode     `yaml:"createOrUpdateMode" json:"createOrUpdateMode"`
+	CreateManyChunkSize        int                    `yaml:"createManyChunkSize" json:"createManyChunkSize"`
+	PreloadChunkSize           int                    `yaml:"preloadChunkSize" json:"preloadChunkSize"`
 	SQLite3TransactionMode     SQLite3TransactionMode `yaml:"sqlite3TransactionMode" json:"sqlite3TransactionMode"`
 	SQLite3TransactionMaxRetry uint                   `yaml:"sqlite3TransactionMaxRetry" json:"sqlite3TransactionMaxRetry"`
 	//nolint:lll // Long line, cannot be helped
 	SQLite3TransactionRetryDelayInMillisecond int `yaml:"sqlite3TransactionRetryDelayInMillisecond" json:"sqlite3TransactionRetryDelayInMillisecond"`
 	//nolint:lll // Long line, cannot be helped
 	SQLite3TransactionRetryJitterInMillisecond int `yaml:"SQLite3TransactionRetryJitterInMillisecond" json:"SQLite3TransactionRetryJitterInMillisecond"`
+	RetryPolicy                                RetryPolicy
 	Logger                                     Logger
+	StructuredLogger                           StructuredLogger
+	Instrumentation                            Instrumentation
+	Models                                     []interface{}
+
+	SlowQueryThresholdInMillisecond int                  `yaml:"slowQueryThresholdInMillisecond" json:"slowQueryThresholdInMillisecond"`
+	RedactedColumns                 []string             `yaml:"redactedColumns" json:"redactedColumns"`
+	Replicas                        []ReplicaConfig      `yaml:"replicas" json:"replicas"`
+	ReplicaLoadBalancing            ReplicaLoadBalancing `yaml:"replicaLoadBalancing" json:"replicaLoadBalancing"`
+	StartupTimeoutInSeconds         int                  `yaml:"startupTimeoutInSeconds" json:"startupTimeoutInSeconds"`
 }
@@ -1,7 +1,10 @@
 package miniorm
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/doug-martin/goqu/v9"
@@ -25,9 +28,43 @@ var (
 		DriverTypePostgres: "postgres",
 		DriverTypeSQLite3:  "sqlite3",
 	}
+
+	ErrDialectMismatch         = errors.New("expected the dialect of the goqu database to be the one of the driver")
+	ErrUnsupportedGoquDatabase = errors.New("expected the goqu database to be opened on a *sql.DB or a type embedding it")
+
+	configDriverTypeToSavepointStatements = map[DriverType]savepointStatements{
+		DriverTypeMSSQL: {
+			Create:   "SAVE TRANSACTION %s",
+			Rollback: "ROLLBACK TRANSACTION %s",
+		},
+		DriverTypeMySQL: {
+			Create:   "SAVEPOINT %s",
+			Rollback: "ROLLBACK TO SAVEPOINT %s",
+			Release:  "RELEASE SAVEPOINT %s",
+		},
+		DriverTypePostgres: {
+			Create:   "SAVEPOINT %s",
+			Rollback: "ROLLBACK TO SAVEPOINT %s",
+			Release:  "RELEASE SAVEPOINT %s",
+		},
+		DriverTypeSQLite3: {
+			Create:   "SAVEPOINT %s",
+			Rollback: "ROLLBACK TO SAVEPOINT %s",
+			Release:  "RELEASE SAVEPOINT %s",
+		},
+	}
 )
 
-func newSQLDatabase(databaseConfig DatabaseConfig) (*sql.DB, error) {
+// savepointStatements are the format strings of the savepoint statements of a database engine
+type savepointStatements struct {
+	Create   string
+	Rollback string
+	Release  string // Empty if savepoints are not released
+}
+
+// NewSQLDatabase opens the database described by databaseConfig, e.g. for tools like the migrate package that need
+// dedicated connections
+func NewSQLDatabase(databaseConfig DatabaseConfig) (*sql.DB, error) {
 	sourceNameProvider, err := newSourceNameProvider(databaseConfig.Driver)
 	if err != nil {
 		return nil, err
@@ -50,11 +87,146 @@ func newSQLDatabase(databaseConfig DatabaseConfig) (*sql.DB, error) {
 	return db, nil
 }
 
-func newGoquDatabase(databaseConfig DatabaseConfig) (*goqu.Database, error) {
-	db, err := newSQLDatabase(databaseConfig)
+// newGoquDatabase opens the database described by databaseConfig, waiting for it to be reachable if
+// StartupTimeoutInSeconds is set, and returns it along with its goqu database
+func newGoquDatabase(databaseConfig DatabaseConfig) (*goqu.Database, *sql.DB, error) {
+	db, err := NewSQLDatabase(databaseConfig)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	if err := waitForDatabase(db, databaseConfig); err != nil {
+		_ = db.Close()
+		return nil, nil, err
+	}
+
+	return newGoquDatabaseFromSQLDatabase(db, databaseConfig), db, nil
+}
+
+// newGoquDatabaseFromSQLDatabase returns a goqu database on db, which retries, logs and instruments the statements
+// according to databaseConfig
+func newGoquDatabaseFromSQLDatabase(db *sql.DB, databaseConfig DatabaseConfig) *goqu.Database {
+	var sqlDatabase goqu.SQLDatabase = db
+
+	if databaseConfig.RetryPolicy != nil {
+		sqlDatabase = &retrySQLDatabase{
+			DB:          db,
+			retryPolicy: databaseConfig.RetryPolicy,
+			driverType:  databaseConfig.Driver,
+		}
+	}
+
+	if databaseConfig.StructuredLogger != nil || databaseConfig.Logger != nil {
+		sqlDatabase = &loggingSQLDatabase{
+			SQLDatabase:     sqlDatabase,
+			statementLogger: newStatementLogger(databaseConfig),
+		}
+	}
+
+	if databaseConfig.Instrumentation != nil {
+		sqlDatabase = &instrumentedSQLDatabase{SQLDatabase: sqlDatabase}
+	}
+
+	goquDB := goqu.New(configDriverTypeToDialect[databaseConfig.Driver], sqlDatabase)
+	goquDB.Logger(newGoquLogger(databaseConfig))
+
+	return goquDB
+}
+
+// instrumentGoquDatabase returns a goqu database on goquDB which records the statements for the Instrumentation of
+// databaseConfig, or goquDB if there is none. The lines logged by goqu are forwarded to the logger of goquDB.
+func instrumentGoquDatabase(goquDB *goqu.Database, databaseConfig DatabaseConfig) *goqu.Database {
+	if databaseConfig.Instrumentation == nil {
+		return goquDB
 	}
 
-	return goqu.New(configDriverTypeToDialect[databaseConfig.Driver], db), nil
+	instrumentedDB := goqu.New(goquDB.Dialect(), &instrumentedSQLDatabase{SQLDatabase: goquDB.Db})
+	instrumentedDB.Logger(&goquTraceLogger{db: goquDB})
+
+	return instrumentedDB
+}
+
+// getGoquConnectionPool returns the connection pool of goquDB, after verifying that goquDB has the dialect of
+// driverType
+func getGoquConnectionPool(goquDB *goqu.Database, driverType DriverType) (connectionPool, error) {
+	if goquDB.Dialect() != configDriverTypeToDialect[driverType] {
+		return nil, ErrDialectMismatch
+	}
+
+	pool, ok := goquDB.Db.(connectionPool)
+	if !ok {
+		return nil, ErrUnsupportedGoquDatabase
+	}
+
+	return pool, nil
+}
+
+// wrapSQLTx wraps tx, a transaction begun on db, like db wraps the statements executed outside of transactions
+func wrapSQLTx(db goqu.SQLDatabase, tx goqu.SQLTx) goqu.SQLTx {
+	switch wrappedDB := db.(type) {
+	case *instrumentedSQLDatabase:
+		return &instrumentedSQLTx{SQLTx: wrapSQLTx(wrappedDB.SQLDatabase, tx)}
+	case *loggingSQLDatabase:
+		return &loggingSQLTx{SQLTx: wrapSQLTx(wrappedDB.SQLDatabase, tx), statementLogger: wrappedDB.statementLogger}
+	default:
+		return tx
+	}
+}
+
+func withGoquTx(
+	ctx context.Context,
+	nonTXDB *goqu.Database,
+	opts *sql.TxOptions,
+	executeFunc func(*goqu.TxDatabase) error,
+) error {
+	td, err := nonTXDB.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	td.Tx = wrapSQLTx(nonTXDB.Db, td.Tx)
+
+	return td.Wrap(func() error {
+		return executeFunc(td)
+	})
+}
+
+// withSavepoint runs executeFunc in a savepoint of td, which is rolled back to if executeFunc fails
+func withSavepoint(
+	ctx context.Context,
+	td DBWrapper,
+	driverType DriverType,
+	savepointDepth uint,
+	executeFunc func() error,
+) (err error) {
+	statements := configDriverTypeToSavepointStatements[driverType]
+	savepointName := fmt.Sprintf("miniorm_savepoint_%d", savepointDepth)
+
+	if _, err := td.ExecContext(ctx, fmt.Sprintf(statements.Create, savepointName)); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = td.ExecContext(ctx, fmt.Sprintf(statements.Rollback, savepointName))
+			panic(p)
+		}
+
+		if err != nil {
+			if _, rollbackErr := td.ExecContext(ctx, fmt.Sprintf(statements.Rollback, savepointName)); rollbackErr != nil {
+				err = fmt.Errorf("%w (rollback to savepoint failed: %v)", err, rollbackErr)
+			}
+		} else if statements.Release != "" {
+			_, err = td.ExecContext(ctx, fmt.Sprintf(statements.Release, savepointName))
+		}
+	}()
+
+	return executeFunc()
+}
+
+// isEmptyLimit returns whether limit, the limit of UpdateWhereWithLimit() or DeleteWhereWithLimit(), matches no row.
+// The statement is skipped for it, since goqu omits LIMIT 0 and the statement would then affect every matching row.
+// MSSQL, which uses TOP instead, skips it as well.
+func isEmptyLimit(limit uint32) bool {
+	return limit == 0
 }
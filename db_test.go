@@ -0,0 +1,30 @@
+package miniorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSavepointRollbackFailure(t *testing.T) {
+	t.Parallel()
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	executeErr := errors.New("execute error")
+	rollbackErr := errors.New("rollback error")
+
+	td := NewMockDBWrapper(mockController)
+	td.EXPECT().ExecContext(gomock.Any(), "SAVEPOINT miniorm_savepoint_1").Return(nil, nil).Times(1)
+	td.EXPECT().ExecContext(gomock.Any(), "ROLLBACK TO SAVEPOINT miniorm_savepoint_1").Return(nil, rollbackErr).Times(1)
+
+	err := withSavepoint(context.Background(), td, DriverTypeMySQL, 1, func() error {
+		return executeErr
+	})
+	assert.ErrorIs(t, err, executeErr)
+	assert.Contains(t, err.Error(), rollbackErr.Error())
+}
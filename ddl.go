@@ -0,0 +1,343 @@
+package miniorm
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	ErrColumnsNotFound           = errors.New("expected entry to have columns")
+	ErrUnsupportedColumnType     = errors.New("expected column type to be a bool, number, string, []byte, time.Time or sql.Null type")
+	ErrAutoIncrementCompositeKey = errors.New("expected auto incremented key to be the only primary key column")
+
+	configDriverTypeToColumnTypes = map[DriverType]columnTypes{
+		DriverTypeMSSQL: {
+			Bool:     "BIT",
+			SmallInt: "SMALLINT",
+			Int:      "INT",
+			BigInt:   "BIGINT",
+			Float:    "REAL",
+			Double:   "FLOAT",
+			Text:     "NVARCHAR(MAX)",
+			KeyText:  "NVARCHAR(255)",
+			Bytes:    "VARBINARY(MAX)",
+			KeyBytes: "VARBINARY(255)",
+			Time:     "DATETIME2",
+		},
+		DriverTypeMySQL: {
+			Bool:     "BOOLEAN",
+			SmallInt: "SMALLINT",
+			Int:      "INT",
+			BigInt:   "BIGINT",
+			Float:    "FLOAT",
+			Double:   "DOUBLE",
+			Text:     "TEXT",
+			KeyText:  "VARCHAR(255)",
+			Bytes:    "LONGBLOB",
+			KeyBytes: "VARBINARY(255)",
+			Time:     "DATETIME(6)",
+		},
+		DriverTypePostgres: {
+			Bool:     "BOOLEAN",
+			SmallInt: "SMALLINT",
+			Int:      "INTEGER",
+			BigInt:   "BIGINT",
+			Float:    "REAL",
+			Double:   "DOUBLE PRECISION",
+			Text:     "TEXT",
+			KeyText:  "TEXT",
+			Bytes:    "BYTEA",
+			KeyBytes: "BYTEA",
+			Time:     "TIMESTAMP WITH TIME ZONE",
+		},
+		// SQLite3 only auto increments INTEGER PRIMARY KEY columns, so every integer is an INTEGER
+		DriverTypeSQLite3: {
+			Bool:     "BOOLEAN",
+			SmallInt: "INTEGER",
+			Int:      "INTEGER",
+			BigInt:   "INTEGER",
+			Float:    "REAL",
+			Double:   "REAL",
+			Text:     "TEXT",
+			KeyText:  "TEXT",
+			Bytes:    "BLOB",
+			KeyBytes: "BLOB",
+			Time:     "DATETIME",
+		},
+	}
+
+	configDriverTypeToIdentifierQuote = map[DriverType]string{
+		DriverTypeMSSQL:    `"`,
+		DriverTypeMySQL:    "`",
+		DriverTypePostgres: `"`,
+		DriverTypeSQLite3:  `"`,
+	}
+
+	postgresColumnTypeToSerialType = map[string]string{
+		"SMALLINT": "SMALLSERIAL",
+		"INTEGER":  "SERIAL",
+		"BIGINT":   "BIGSERIAL",
+	}
+
+	nullTypeToValueType = map[reflect.Type]reflect.Type{
+		reflect.TypeOf(sql.NullBool{}):    reflect.TypeOf(false),
+		reflect.TypeOf(sql.NullInt32{}):   reflect.TypeOf(int32(0)),
+		reflect.TypeOf(sql.NullInt64{}):   reflect.TypeOf(int64(0)),
+		reflect.TypeOf(sql.NullFloat64{}): reflect.TypeOf(float64(0)),
+		reflect.TypeOf(sql.NullString{}):  reflect.TypeOf(""),
+		reflect.TypeOf(sql.NullTime{}):    reflect.TypeOf(time.Time{}),
+	}
+)
+
+type columnKind int
+
+const (
+	columnKindBool columnKind = iota + 1
+	columnKindSmallInt
+	columnKindInt
+	columnKindBigInt
+	columnKindFloat
+	columnKindDouble
+	columnKindText
+	columnKindBytes
+	columnKindTime
+)
+
+// columnTypes are the column types of a database engine for the Go types of model fields. Key columns, i.e. primary
+// key and unique columns, use KeyText and KeyBytes since some engines cannot index unbounded columns.
+type columnTypes struct {
+	Bool     string
+	SmallInt string
+	Int      string
+	BigInt   string
+	Float    string
+	Double   string
+	Text     string
+	KeyText  string
+	Bytes    string
+	KeyBytes string
+	Time     string
+}
+
+// GenerateCreateTableStatement returns the CREATE TABLE statement of the table of entry for driverType. The columns are
+// derived from the db tags and Go types of the fields of entry, pointer and sql.Null types being nullable. The
+// primary key is taken from the pk tags, KeyGetter or IDGetter, and is auto incremented if the entry implements
+// IDSetter or has an integer autoincrement or generated field. The unique expression of the entry, if it is not the
+// primary key, becomes a UNIQUE constraint.
+func GenerateCreateTableStatement(driverType DriverType, entry interface{}) (string, error) {
+	types, ok := configDriverTypeToColumnTypes[driverType]
+	if !ok {
+		return "", errors.New("invalid driver type")
+	}
+
+	provider := newEntryInfoProvider()
+
+	tableName, err := provider.GetEntryTableName(entry)
+	if err != nil {
+		return "", err
+	}
+
+	metadata, _, ok := getModelMetadata(entry)
+	if !ok || len(metadata.fields) == 0 {
+		return "", ErrColumnsNotFound
+	}
+
+	primaryKeyColumns := getPrimaryKeyColumns(provider, metadata, entry)
+	uniqueColumns, hasUniqueColumns := provider.GetUniqueColumns(entry)
+
+	if hasUniqueColumns && isSameColumnSet(primaryKeyColumns, uniqueColumns) {
+		hasUniqueColumns = false
+	}
+
+	autoIncrementColumn, _, _ := provider.GetKeyDestination(entry)
+
+	keyColumns := make(map[string]bool)
+	for _, column := range append(append([]string{}, primaryKeyColumns...), uniqueColumns...) {
+		keyColumns[column] = true
+	}
+
+	quote := configDriverTypeToIdentifierQuote[driverType]
+	definitions := make([]string, 0, len(metadata.fields)+2)
+	isPrimaryKeyInline := false
+
+	for _, field := range metadata.fields {
+		columnType, isNullable, err := getColumnType(types, field.fieldType, keyColumns[field.column])
+		if err != nil {
+			return "", err
+		}
+
+		definition := quoteIdentifier(quote, field.column) + " " + columnType
+		if isNullable {
+			definition += " NULL"
+		} else {
+			definition += " NOT NULL"
+		}
+
+		if field.column == autoIncrementColumn && isIntKind(field.fieldType.Kind()) {
+			if len(primaryKeyColumns) != 1 || primaryKeyColumns[0] != autoIncrementColumn {
+				return "", ErrAutoIncrementCompositeKey
+			}
+
+			definition, isPrimaryKeyInline = getAutoIncrementDefinition(driverType, quote, field.column, columnType)
+		}
+
+		definitions = append(definitions, definition)
+	}
+
+	if len(primaryKeyColumns) > 0 && !isPrimaryKeyInline {
+		definitions = append(definitions, fmt.Sprintf("PRIMARY KEY (%s)", quoteIdentifiers(quote, primaryKeyColumns)))
+	}
+
+	if hasUniqueColumns {
+		definitions = append(definitions, fmt.Sprintf("UNIQUE (%s)", quoteIdentifiers(quote, uniqueColumns)))
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (\n\t%s\n)", quoteIdentifier(quote, tableName), strings.Join(definitions, ",\n\t")), nil
+}
+
+// getPrimaryKeyColumns returns the pk tagged columns of entry, or its key column, or its unique columns
+func getPrimaryKeyColumns(provider *entryInfoProvider, metadata *modelMetadata, entry interface{}) []string {
+	if len(metadata.primaryKeyFields) > 0 {
+		primaryKeyColumns := make([]string, 0, len(metadata.primaryKeyFields))
+		for _, primaryKeyField := range metadata.primaryKeyFields {
+			primaryKeyColumns = append(primaryKeyColumns, primaryKeyField.column)
+		}
+
+		return primaryKeyColumns
+	}
+
+	if keyColumn, _, err := provider.GetKey(entry); err == nil {
+		return []string{keyColumn}
+	}
+
+	uniqueColumns, _ := provider.GetUniqueColumns(entry)
+
+	return uniqueColumns
+}
+
+// getColumnType returns the column type of fieldType, and whether the column is nullable
+func getColumnType(types columnTypes, fieldType reflect.Type, isKey bool) (columnType string, isNullable bool, err error) {
+	kind, isNullable, ok := getColumnKind(fieldType)
+	if !ok {
+		return "", false, ErrUnsupportedColumnType
+	}
+
+	switch kind {
+	case columnKindBool:
+		return types.Bool, isNullable, nil
+	case columnKindSmallInt:
+		return types.SmallInt, isNullable, nil
+	case columnKindInt:
+		return types.Int, isNullable, nil
+	case columnKindBigInt:
+		return types.BigInt, isNullable, nil
+	case columnKindFloat:
+		return types.Float, isNullable, nil
+	case columnKindDouble:
+		return types.Double, isNullable, nil
+	case columnKindText:
+		if isKey {
+			return types.KeyText, isNullable, nil
+		}
+
+		return types.Text, isNullable, nil
+	case columnKindBytes:
+		if isKey {
+			return types.KeyBytes, isNullable, nil
+		}
+
+		return types.Bytes, isNullable, nil
+	default:
+		return types.Time, isNullable, nil
+	}
+}
+
+// getColumnKind returns the kind of column storing fieldType, and whether the column is nullable, i.e. fieldType is a
+// pointer or a sql.Null type. ok is false if fieldType is not supported.
+func getColumnKind(fieldType reflect.Type) (kind columnKind, isNullable, ok bool) {
+	if fieldType.Kind() == reflect.Ptr {
+		isNullable = true
+		fieldType = fieldType.Elem()
+	}
+
+	if valueType, ok := nullTypeToValueType[fieldType]; ok {
+		isNullable = true
+		fieldType = valueType
+	}
+
+	switch fieldKind := fieldType.Kind(); {
+	case fieldType == reflect.TypeOf(time.Time{}):
+		return columnKindTime, isNullable, true
+	case fieldKind == reflect.Bool:
+		return columnKindBool, isNullable, true
+	case fieldKind == reflect.Int8 || fieldKind == reflect.Int16 || fieldKind == reflect.Uint8:
+		return columnKindSmallInt, isNullable, true
+	case fieldKind == reflect.Int32 || fieldKind == reflect.Uint16:
+		return columnKindInt, isNullable, true
+	case isIntKind(fieldKind) || isUintKind(fieldKind):
+		return columnKindBigInt, isNullable, true
+	case fieldKind == reflect.Float32:
+		return columnKindFloat, isNullable, true
+	case fieldKind == reflect.Float64:
+		return columnKindDouble, isNullable, true
+	case fieldKind == reflect.String:
+		return columnKindText, isNullable, true
+	case fieldKind == reflect.Slice && fieldType.Elem().Kind() == reflect.Uint8:
+		return columnKindBytes, isNullable, true
+	default:
+		return 0, false, false
+	}
+}
+
+// getAutoIncrementDefinition returns the definition of an auto incremented column, and whether the definition
+// declares the primary key, which is the case on SQLite3
+func getAutoIncrementDefinition(driverType DriverType, quote, column, columnType string) (string, bool) {
+	quotedColumn := quoteIdentifier(quote, column)
+
+	switch driverType {
+	case DriverTypeMySQL:
+		return quotedColumn + " " + columnType + " NOT NULL AUTO_INCREMENT", false
+	case DriverTypePostgres:
+		return quotedColumn + " " + postgresColumnTypeToSerialType[columnType] + " NOT NULL", false
+	case DriverTypeMSSQL:
+		return quotedColumn + " " + columnType + " IDENTITY(1,1) NOT NULL", false
+	default:
+		return quotedColumn + " INTEGER PRIMARY KEY AUTOINCREMENT", true
+	}
+}
+
+func quoteIdentifier(quote, identifier string) string {
+	return quote + strings.ReplaceAll(identifier, quote, quote+quote) + quote
+}
+
+func quoteIdentifiers(quote string, identifiers []string) string {
+	quotedIdentifiers := make([]string, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		quotedIdentifiers = append(quotedIdentifiers, quoteIdentifier(quote, identifier))
+	}
+
+	return strings.Join(quotedIdentifiers, ", ")
+}
+
+func isSameColumnSet(columns, otherColumns []string) bool {
+	if len(columns) != len(otherColumns) {
+		return false
+	}
+
+	columnSet := make(map[string]bool, len(columns))
+	for _, column := range columns {
+		columnSet[column] = true
+	}
+
+	for _, column := range otherColumns {
+		if !columnSet[column] {
+			return false
+		}
+	}
+
+	return true
+}
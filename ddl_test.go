@@ -0,0 +1,140 @@
+package miniorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCreateTableStatement(t *testing.T) {
+	t.Parallel()
+
+	testCaseList := []struct {
+		driverType        DriverType
+		entry             interface{}
+		expectedStatement string
+	}{
+		{
+			driverType: DriverTypeMySQL,
+			entry:      &ddlEntry{},
+			expectedStatement: "CREATE TABLE `ddl_entries` (\n" +
+				"\t`id` BIGINT NOT NULL AUTO_INCREMENT,\n" +
+				"\t`code` VARCHAR(255) NOT NULL,\n" +
+				"\t`bool_col` BOOLEAN NOT NULL,\n" +
+				"\t`int32_col` INT NOT NULL,\n" +
+				"\t`float_col` DOUBLE NOT NULL,\n" +
+				"\t`string_col` TEXT NOT NULL,\n" +
+				"\t`bytes_col` LONGBLOB NOT NULL,\n" +
+				"\t`null_int_col` BIGINT NULL,\n" +
+				"\t`time_col` DATETIME(6) NULL,\n" +
+				"\tPRIMARY KEY (`id`),\n" +
+				"\tUNIQUE (`code`)\n" +
+				")",
+		},
+		{
+			driverType: DriverTypePostgres,
+			entry:      &ddlEntry{},
+			expectedStatement: "CREATE TABLE \"ddl_entries\" (\n" +
+				"\t\"id\" BIGSERIAL NOT NULL,\n" +
+				"\t\"code\" TEXT NOT NULL,\n" +
+				"\t\"bool_col\" BOOLEAN NOT NULL,\n" +
+				"\t\"int32_col\" INTEGER NOT NULL,\n" +
+				"\t\"float_col\" DOUBLE PRECISION NOT NULL,\n" +
+				"\t\"string_col\" TEXT NOT NULL,\n" +
+				"\t\"bytes_col\" BYTEA NOT NULL,\n" +
+				"\t\"null_int_col\" BIGINT NULL,\n" +
+				"\t\"time_col\" TIMESTAMP WITH TIME ZONE NULL,\n" +
+				"\tPRIMARY KEY (\"id\"),\n" +
+				"\tUNIQUE (\"code\")\n" +
+				")",
+		},
+		{
+			driverType: DriverTypeMSSQL,
+			entry:      &ddlEntry{},
+			expectedStatement: "CREATE TABLE \"ddl_entries\" (\n" +
+				"\t\"id\" BIGINT IDENTITY(1,1) NOT NULL,\n" +
+				"\t\"code\" NVARCHAR(255) NOT NULL,\n" +
+				"\t\"bool_col\" BIT NOT NULL,\n" +
+				"\t\"int32_col\" INT NOT NULL,\n" +
+				"\t\"float_col\" FLOAT NOT NULL,\n" +
+				"\t\"string_col\" NVARCHAR(MAX) NOT NULL,\n" +
+				"\t\"bytes_col\" VARBINARY(MAX) NOT NULL,\n" +
+				"\t\"null_int_col\" BIGINT NULL,\n" +
+				"\t\"time_col\" DATETIME2 NULL,\n" +
+				"\tPRIMARY KEY (\"id\"),\n" +
+				"\tUNIQUE (\"code\")\n" +
+				")",
+		},
+		{
+			driverType: DriverTypeSQLite3,
+			entry:      &ddlEntry{},
+			expectedStatement: "CREATE TABLE \"ddl_entries\" (\n" +
+				"\t\"id\" INTEGER PRIMARY KEY AUTOINCREMENT,\n" +
+				"\t\"code\" TEXT NOT NULL,\n" +
+				"\t\"bool_col\" BOOLEAN NOT NULL,\n" +
+				"\t\"int32_col\" INTEGER NOT NULL,\n" +
+				"\t\"float_col\" REAL NOT NULL,\n" +
+				"\t\"string_col\" TEXT NOT NULL,\n" +
+				"\t\"bytes_col\" BLOB NOT NULL,\n" +
+				"\t\"null_int_col\" INTEGER NULL,\n" +
+				"\t\"time_col\" DATETIME NULL,\n" +
+				"\tUNIQUE (\"code\")\n" +
+				")",
+		},
+		{
+			driverType: DriverTypeMySQL,
+			entry:      &getIDEntry{},
+			expectedStatement: "CREATE TABLE `get_id_entries` (\n" +
+				"\t`id` BIGINT NOT NULL AUTO_INCREMENT,\n" +
+				"\t`string_col` TEXT NOT NULL,\n" +
+				"\t`bytes_col` LONGBLOB NOT NULL,\n" +
+				"\t`on_create_count` BIGINT NOT NULL,\n" +
+				"\t`on_update_count` BIGINT NOT NULL,\n" +
+				"\tPRIMARY KEY (`id`)\n" +
+				")",
+		},
+		{
+			driverType: DriverTypeMSSQL,
+			entry:      &getUniqueEntryWithOnCreateAndOnUpdate{},
+			expectedStatement: "CREATE TABLE \"get_unique_entries\" (\n" +
+				"\t\"id_1\" BIGINT NOT NULL,\n" +
+				"\t\"id_2\" BIGINT NOT NULL,\n" +
+				"\t\"string_col\" NVARCHAR(MAX) NOT NULL,\n" +
+				"\t\"bytes_col\" VARBINARY(MAX) NOT NULL,\n" +
+				"\t\"on_create_count\" BIGINT NOT NULL,\n" +
+				"\t\"on_update_count\" BIGINT NOT NULL,\n" +
+				"\tPRIMARY KEY (\"id_1\", \"id_2\")\n" +
+				")",
+		},
+		{
+			driverType: DriverTypePostgres,
+			entry:      &taggedKeyEntry{},
+			expectedStatement: "CREATE TABLE \"get_key_entries\" (\n" +
+				"\t\"id\" TEXT NOT NULL,\n" +
+				"\t\"string_col\" TEXT NOT NULL,\n" +
+				"\tPRIMARY KEY (\"id\")\n" +
+				")",
+		},
+	}
+
+	for _, testCase := range testCaseList {
+		statement, err := GenerateCreateTableStatement(testCase.driverType, testCase.entry)
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedStatement, statement)
+	}
+
+	_, err := GenerateCreateTableStatement("unknown", &ddlEntry{})
+	assert.NotNil(t, err)
+
+	_, err = GenerateCreateTableStatement(DriverTypeMySQL, struct{}{})
+	assert.ErrorIs(t, err, ErrTableNameGetterExpected)
+
+	_, err = GenerateCreateTableStatement(DriverTypeMySQL, &tableNameGetterWithoutUniqueSelector{})
+	assert.ErrorIs(t, err, ErrColumnsNotFound)
+
+	_, err = GenerateCreateTableStatement(DriverTypeMySQL, &struct {
+		_   struct{}          `miniorm:"table=entries"`
+		Map map[string]string `db:"map"`
+	}{})
+	assert.ErrorIs(t, err, ErrUnsupportedColumnType)
+}
@@ -0,0 +1,245 @@
+package miniorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrUniqueViolation      = errors.New("unique constraint violated")
+	ErrForeignKeyViolation  = errors.New("foreign key constraint violated")
+	ErrNotNullViolation     = errors.New("not null constraint violated")
+	ErrDeadlock             = errors.New("deadlock detected")
+	ErrLockTimeout          = errors.New("lock wait timeout exceeded")
+	ErrSerializationFailure = errors.New("could not serialize access due to concurrent update")
+	ErrConnection           = errors.New("database connection failed")
+
+	// Error numbers of MySQL, see https://dev.mysql.com/doc/mysql-errors/8.0/en/server-error-reference.html
+	mysqlErrorNumberToDriverError = map[int64]error{
+		1022: ErrUniqueViolation,
+		1062: ErrUniqueViolation,
+		1586: ErrUniqueViolation,
+		1216: ErrForeignKeyViolation,
+		1217: ErrForeignKeyViolation,
+		1451: ErrForeignKeyViolation,
+		1452: ErrForeignKeyViolation,
+		1048: ErrNotNullViolation,
+		1213: ErrDeadlock,
+		1205: ErrLockTimeout,
+	}
+
+	// SQLSTATEs of Postgres, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+	postgresSQLStateToDriverError = map[string]error{
+		"23505": ErrUniqueViolation,
+		"23503": ErrForeignKeyViolation,
+		"23502": ErrNotNullViolation,
+		"40P01": ErrDeadlock,
+		"55P03": ErrLockTimeout,
+		"40001": ErrSerializationFailure,
+	}
+
+	// Error numbers of MSSQL, see
+	// https://learn.microsoft.com/en-us/sql/relational-databases/errors-events/database-engine-events-and-errors
+	mssqlErrorNumberToDriverError = map[int64]error{
+		2601: ErrUniqueViolation,
+		2627: ErrUniqueViolation,
+		547:  ErrForeignKeyViolation,
+		515:  ErrNotNullViolation,
+		1205: ErrDeadlock,
+		1222: ErrLockTimeout,
+		3960: ErrSerializationFailure,
+	}
+
+	// Extended error codes of SQLite constraint violations, see https://www.sqlite.org/rescode.html
+	sqlite3ExtendedErrorCodeToDriverError = map[int64]error{
+		1555: ErrUniqueViolation,
+		2067: ErrUniqueViolation,
+		787:  ErrForeignKeyViolation,
+		1299: ErrNotNullViolation,
+	}
+
+	// Primary error codes of SQLite, SQLITE_BUSY (5) and SQLITE_LOCKED (6)
+	sqlite3ErrorCodeToDriverError = map[int64]error{
+		5: ErrLockTimeout,
+		6: ErrLockTimeout,
+	}
+
+	// Messages of connection errors which the drivers do not wrap
+	connectionErrorMessages = []string{
+		"invalid connection",                      // MySQL
+		"unable to open tcp connection with host", // MSSQL
+	}
+
+	// Patterns of the constraint names in the messages of the drivers without a dedicated field
+	mysqlConstraintRegexes = []*regexp.Regexp{
+		regexp.MustCompile("for key '([^']+)'"),
+		regexp.MustCompile("CONSTRAINT `([^`]+)`"),
+		regexp.MustCompile("Column '([^']+)' cannot be null"),
+	}
+	mssqlConstraintRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`constraint ['"]([^'"]+)['"]`),
+		regexp.MustCompile(`unique index '([^']+)'`),
+		regexp.MustCompile(`into column '([^']+)'`),
+	}
+	sqlite3ConstraintRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`constraint failed: (.+)$`),
+	}
+)
+
+// DriverError is a driver error classified as one of ErrUniqueViolation, ErrForeignKeyViolation,
+// ErrNotNullViolation, ErrDeadlock, ErrLockTimeout, ErrSerializationFailure or ErrConnection. errors.Is() matches both
+// the classification and the driver error, and errors.As() can still be used to retrieve the driver error.
+type DriverError struct {
+	// Kind is the classification of the error, e.g. ErrUniqueViolation
+	Kind error
+	// Constraint is the name of the violated constraint or, for not null violations, the column, if reported by the
+	// database engine
+	Constraint string
+	// Err is the original driver error
+	Err error
+}
+
+func (err *DriverError) Error() string {
+	if err.Constraint != "" {
+		return fmt.Sprintf("%s (%s): %s", err.Kind, err.Constraint, err.Err)
+	}
+
+	return fmt.Sprintf("%s: %s", err.Kind, err.Err)
+}
+
+func (err *DriverError) Unwrap() error {
+	return err.Err
+}
+
+func (err *DriverError) Is(target error) bool {
+	return target == err.Kind
+}
+
+// wrapDriverError wraps err into a DriverError if it is a driver error of driverType that can be classified, otherwise
+// err is returned as is
+func wrapDriverError(driverType DriverType, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var driverError *DriverError
+	if errors.As(err, &driverError) {
+		return err
+	}
+
+	kind, constraint := classifyDriverError(driverType, err)
+	if kind == nil {
+		return err
+	}
+
+	return &DriverError{Kind: kind, Constraint: constraint, Err: err}
+}
+
+func classifyDriverError(driverType DriverType, err error) (kind error, constraint string) {
+	switch driverType {
+	case DriverTypeMySQL:
+		if errorNumber, ok := getErrorCodeField(err, "MySQLError", "Number"); ok {
+			kind = mysqlErrorNumberToDriverError[errorNumber]
+			constraint = findConstraint(mysqlConstraintRegexes, err.Error())
+		}
+	case DriverTypePostgres:
+		var postgresError interface{ SQLState() string }
+		if errors.As(err, &postgresError) {
+			sqlState := postgresError.SQLState()
+			kind = postgresSQLStateToDriverError[sqlState]
+
+			if strings.HasPrefix(sqlState, "08") {
+				kind = ErrConnection
+			}
+
+			constraint = getErrorStringField(err, "PgError", "ConstraintName")
+			if kind == ErrNotNullViolation {
+				constraint = getErrorStringField(err, "PgError", "ColumnName")
+			}
+		}
+	case DriverTypeMSSQL:
+		var mssqlError interface{ SQLErrorNumber() int32 }
+		if errors.As(err, &mssqlError) {
+			kind = mssqlErrorNumberToDriverError[int64(mssqlError.SQLErrorNumber())]
+			constraint = findConstraint(mssqlConstraintRegexes, err.Error())
+		}
+	case DriverTypeSQLite3:
+		if errorCode, ok := getErrorCodeField(err, "Error", "ExtendedCode"); ok {
+			kind = sqlite3ExtendedErrorCodeToDriverError[errorCode]
+			constraint = findConstraint(sqlite3ConstraintRegexes, err.Error())
+		}
+
+		if errorCode, ok := getErrorCodeField(err, "Error", "Code"); ok && kind == nil {
+			kind = sqlite3ErrorCodeToDriverError[errorCode]
+		}
+	}
+
+	if kind == nil && isConnectionError(err) {
+		return ErrConnection, ""
+	}
+
+	if kind == nil || kind == ErrDeadlock || kind == ErrLockTimeout || kind == ErrSerializationFailure {
+		constraint = ""
+	}
+
+	return kind, constraint
+}
+
+func isConnectionError(err error) bool {
+	// context.DeadlineExceeded implements net.Error as well
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var netError net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.As(err, &netError) {
+		return true
+	}
+
+	for _, message := range connectionErrorMessages {
+		if strings.Contains(err.Error(), message) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findConstraint returns the first submatch of constraintRegexes in message, or an empty string
+func findConstraint(constraintRegexes []*regexp.Regexp, message string) string {
+	for _, constraintRegex := range constraintRegexes {
+		if match := constraintRegex.FindStringSubmatch(message); match != nil {
+			return match[1]
+		}
+	}
+
+	return ""
+}
+
+// getErrorStringField returns the string field fieldName of the first error in the chain of err whose struct type is
+// named typeName, see getErrorCodeField()
+func getErrorStringField(err error, typeName, fieldName string) string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		errorValue := reflect.Indirect(reflect.ValueOf(err))
+		if errorValue.Kind() != reflect.Struct || errorValue.Type().Name() != typeName {
+			continue
+		}
+
+		if field := errorValue.FieldByName(fieldName); field.IsValid() && field.Kind() == reflect.String {
+			return field.String()
+		}
+	}
+
+	return ""
+}
+
+// wrapReturnedDriverError wraps the error returned by an ORM method, see wrapDriverError(). It is meant to be deferred.
+func wrapReturnedDriverError(driverType DriverType, err *error) {
+	*err = wrapDriverError(driverType, *err)
+}
@@ -0,0 +1,93 @@
+package miniorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapDriverError(t *testing.T) {
+	t.Parallel()
+
+	testCaseList := []struct {
+		driverType DriverType
+		err        error
+		kind       error
+		constraint string
+	}{
+		{DriverTypeMySQL, &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'PRIMARY'"}, ErrUniqueViolation, "PRIMARY"},
+		{
+			DriverTypeMySQL,
+			&mysql.MySQLError{
+				Number:  1452,
+				Message: "Cannot add or update a child row: a foreign key constraint fails (`db`.`child`, CONSTRAINT `fk_parent` ...)",
+			},
+			ErrForeignKeyViolation,
+			"fk_parent",
+		},
+		{DriverTypeMySQL, &mysql.MySQLError{Number: 1048, Message: "Column 'name' cannot be null"}, ErrNotNullViolation, "name"},
+		{DriverTypeMySQL, fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: 1213}), ErrDeadlock, ""},
+		{DriverTypeMySQL, mysql.ErrInvalidConn, ErrConnection, ""},
+		{DriverTypePostgres, &testPostgresError{sqlState: "23505"}, ErrUniqueViolation, ""},
+		{DriverTypePostgres, &testPostgresError{sqlState: "40001"}, ErrSerializationFailure, ""},
+		{DriverTypePostgres, &testPostgresError{sqlState: "08006"}, ErrConnection, ""},
+		{DriverTypeMSSQL, testMSSQLError{number: 2627}, ErrUniqueViolation, ""},
+		{DriverTypeMSSQL, testMSSQLError{number: 1222}, ErrLockTimeout, ""},
+		{DriverTypeSQLite3, sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}, ErrUniqueViolation, ""},
+		{
+			DriverTypeSQLite3,
+			sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey},
+			ErrForeignKeyViolation,
+			"",
+		},
+		{DriverTypeSQLite3, &sqlite3.Error{Code: sqlite3.ErrBusy}, ErrLockTimeout, ""},
+		{DriverTypeMySQL, driver.ErrBadConn, ErrConnection, ""},
+	}
+
+	for _, testCase := range testCaseList {
+		err := wrapDriverError(testCase.driverType, testCase.err)
+		assert.ErrorIs(t, err, testCase.kind, testCase.err)
+		assert.ErrorIs(t, err, testCase.err)
+
+		var driverError *DriverError
+		assert.True(t, errors.As(err, &driverError))
+		assert.Equal(t, testCase.kind, driverError.Kind)
+		assert.Equal(t, testCase.constraint, driverError.Constraint)
+		assert.Same(t, err, wrapDriverError(testCase.driverType, err))
+	}
+
+	var mysqlError *mysql.MySQLError
+	assert.True(t, errors.As(wrapDriverError(DriverTypeMySQL, &mysql.MySQLError{Number: 1062}), &mysqlError))
+	assert.Equal(t, uint16(1062), mysqlError.Number)
+
+	testCaseList2 := []struct {
+		driverType DriverType
+		err        error
+	}{
+		{DriverTypeMySQL, nil},
+		{DriverTypeMySQL, ErrNotFound},
+		{DriverTypeMySQL, &mysql.MySQLError{Number: 1064}},
+		{DriverTypePostgres, &mysql.MySQLError{Number: 1062}},
+		{DriverTypeMySQL, context.DeadlineExceeded},
+		{DriverTypeMySQL, context.Canceled},
+	}
+
+	for _, testCase := range testCaseList2 {
+		assert.Equal(t, testCase.err, wrapDriverError(testCase.driverType, testCase.err))
+	}
+
+	assert.Equal(t, "get_unique_entries.id_1, get_unique_entries.id_2", findConstraint(
+		sqlite3ConstraintRegexes,
+		"UNIQUE constraint failed: get_unique_entries.id_1, get_unique_entries.id_2",
+	))
+	assert.Equal(t, "IX_name", findConstraint(
+		mssqlConstraintRegexes,
+		"Cannot insert duplicate key row in object 'dbo.entries' with unique index 'IX_name'.",
+	))
+}
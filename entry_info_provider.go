@@ -1,7 +1,12 @@
 package miniorm
 
 import (
+	"database/sql"
 	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exp"
@@ -10,49 +15,206 @@ import (
 var (
 	ErrTableNameGetterExpected        = errors.New("expected entry to implement TableNameGetter interface")
 	ErrIDGetterExpected               = errors.New("expected entry to implement IDGetter interface")
+	ErrKeyGetterExpected              = errors.New("expected entry to implement KeyGetter or IDGetter interface")
 	ErrUniqueGetterOrIDGetterExpected = errors.New("expected entry to implement UniqueGetter of IDGetter interface")
+	ErrSliceExpected                  = errors.New("expected entries to be a slice of structs or pointers to structs")
 )
 
-type entryInfoProvider struct{}
+type entryInfoProvider struct {
+	// Soft deleted models, by table, used where only the table name is known
+	tableNameToSoftDeleteModel map[string]interface{}
+	// Tables of the soft deleted models seen in entries but missing from the models
+	unregisteredSoftDeleteTableNames sync.Map
+}
+
+func newEntryInfoProvider(models ...interface{}) *entryInfoProvider {
+	provider := &entryInfoProvider{tableNameToSoftDeleteModel: make(map[string]interface{})}
+
+	for _, model := range models {
+		if _, ok := provider.GetSoftDeleteColumn(model); !ok {
+			continue
+		}
+
+		if tableName, err := getEntryTableName(model); err == nil {
+			provider.tableNameToSoftDeleteModel[tableName] = model
+		}
+	}
+
+	return provider
+}
+
+// GetEntryTableName returns the table name of entry, from TableNameGetter or from the `miniorm:"table=..."` tag. The
+// tables of soft deleted entries missing from the models are remembered, see IsUnregisteredSoftDeleteTable().
+func (provider *entryInfoProvider) GetEntryTableName(entry interface{}) (string, error) {
+	tableName, err := getEntryTableName(entry)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := provider.tableNameToSoftDeleteModel[tableName]; !ok {
+		if _, ok := provider.GetSoftDeleteColumn(entry); ok {
+			provider.unregisteredSoftDeleteTableNames.Store(tableName, true)
+		}
+	}
+
+	return tableName, nil
+}
 
-func newEntryInfoProvider() *entryInfoProvider {
-	return &entryInfoProvider{}
+// IsUnregisteredSoftDeleteTable returns whether tableName is the table of a soft deleted entry seen by the ORM, whose
+// model is missing from the models
+func (provider *entryInfoProvider) IsUnregisteredSoftDeleteTable(tableName string) bool {
+	_, ok := provider.unregisteredSoftDeleteTableNames.Load(tableName)
+
+	return ok
 }
 
-func (*entryInfoProvider) GetEntryTableName(entry interface{}) (string, error) {
+func getEntryTableName(entry interface{}) (string, error) {
 	tableNameGetterEntry, ok := entry.(TableNameGetter)
-	if !ok {
-		return "", ErrTableNameGetterExpected
+	if ok {
+		return tableNameGetterEntry.GetTableName(), nil
 	}
 
-	return tableNameGetterEntry.GetTableName(), nil
+	metadata, _, ok := getModelMetadata(entry)
+	if ok && metadata.tableName != "" {
+		return metadata.tableName, nil
+	}
+
+	return "", ErrTableNameGetterExpected
 }
 
+// GetID returns the ID column and value of entry, from IDGetter or from its only primary key field, if it is an integer
 func (*entryInfoProvider) GetID(entry interface{}) (idColumn string, idValue int64, err error) {
 	idGetterEntry, ok := entry.(IDGetter)
-	if !ok {
-		return "", 0, ErrIDGetterExpected
+	if ok {
+		columnName, id := idGetterEntry.GetID()
+
+		return columnName, id, nil
 	}
 
-	columnName, id := idGetterEntry.GetID()
+	metadata, entryValue, ok := getModelMetadata(entry)
+	if ok && len(metadata.primaryKeyFields) == 1 && isIntKind(metadata.primaryKeyFields[0].fieldType.Kind()) {
+		idField := metadata.primaryKeyFields[0]
+
+		return idField.column, entryValue.FieldByIndex(idField.index).Int(), nil
+	}
 
-	return columnName, id, nil
+	return "", 0, ErrIDGetterExpected
+}
+
+// GetKey returns the key column and value of entry, from KeyGetter, IDGetter or from its only primary key field
+func (*entryInfoProvider) GetKey(entry interface{}) (keyColumn string, keyValue interface{}, err error) {
+	if keyGetterEntry, ok := entry.(KeyGetter); ok {
+		keyColumn, keyValue := keyGetterEntry.GetKey()
+
+		return keyColumn, keyValue, nil
+	}
+
+	if idGetterEntry, ok := entry.(IDGetter); ok {
+		idColumn, idValue := idGetterEntry.GetID()
+
+		return idColumn, idValue, nil
+	}
+
+	metadata, entryValue, ok := getModelMetadata(entry)
+	if ok && len(metadata.primaryKeyFields) == 1 {
+		keyField := metadata.primaryKeyFields[0]
+
+		return keyField.column, entryValue.FieldByIndex(keyField.index).Interface(), nil
+	}
+
+	return "", nil, ErrKeyGetterExpected
+}
+
+// GetKeyDestination returns the column of the key generated by the database and the destination the key is scanned
+// into, from KeySetter, IDSetter or from the generated primary key field of entry. keyColumn is empty if entry only
+// implements IDSetter without KeyGetter or IDGetter.
+func (provider *entryInfoProvider) GetKeyDestination(entry interface{}) (keyColumn string, keyDestination interface{}, ok bool) {
+	if keySetterEntry, ok := entry.(KeySetter); ok {
+		keyColumn, keyDestination := keySetterEntry.GetKeyDestination()
+
+		return keyColumn, keyDestination, true
+	}
+
+	if idSetterEntry, ok := entry.(IDSetter); ok {
+		keyColumn, _, _ := provider.GetKey(entry)
+
+		return keyColumn, &idSetterScanner{idSetter: idSetterEntry}, true
+	}
+
+	metadata, entryValue, ok := getModelMetadata(entry)
+	if !ok || metadata.generatedKeyField == nil {
+		return "", nil, false
+	}
+
+	keyField := entryValue.FieldByIndex(metadata.generatedKeyField.index)
+	if !keyField.CanAddr() {
+		return "", nil, false
+	}
+
+	return metadata.generatedKeyField.column, keyField.Addr().Interface(), true
 }
 
 func (manipulator *entryInfoProvider) GetEntrySelectExpression(entry interface{}) (exp.Ex, error) {
-	uniqueGetter, ok := entry.(UniqueGetter)
+	uniqueExpression, ok := manipulator.GetUniqueExpression(entry)
 	if ok {
-		return uniqueGetter.GetUniqueExpression(), nil
+		return uniqueExpression, nil
 	}
 
-	idColumn, idValue, err := manipulator.GetID(entry)
+	keyColumn, keyValue, err := manipulator.GetKey(entry)
 	if err == nil {
-		return goqu.Ex{idColumn: idValue}, nil
+		return goqu.Ex{keyColumn: keyValue}, nil
 	}
 
 	return nil, ErrUniqueGetterOrIDGetterExpected
 }
 
+// GetEntrySelectColumns returns the sorted column names of the select expression of entry, i.e. the columns
+// identifying its row, see GetEntrySelectExpression()
+func (provider *entryInfoProvider) GetEntrySelectColumns(entry interface{}) ([]string, error) {
+	selectExpression, err := provider.GetEntrySelectExpression(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	selectColumns := make([]string, 0, len(selectExpression))
+	for column := range selectExpression {
+		selectColumns = append(selectColumns, column)
+	}
+
+	sort.Strings(selectColumns)
+
+	return selectColumns, nil
+}
+
+// GetUniqueExpression returns the unique expression of entry, from UniqueGetter or, if entry implements neither
+// UniqueGetter, KeyGetter nor IDGetter, from its primary key fields unless one of them is generated by the database
+func (*entryInfoProvider) GetUniqueExpression(entry interface{}) (goqu.Ex, bool) {
+	uniqueGetter, ok := entry.(UniqueGetter)
+	if ok {
+		return uniqueGetter.GetUniqueExpression(), true
+	}
+
+	if _, ok := entry.(KeyGetter); ok {
+		return nil, false
+	}
+
+	if _, ok := entry.(IDGetter); ok {
+		return nil, false
+	}
+
+	metadata, entryValue, ok := getModelMetadata(entry)
+	if !ok || len(metadata.primaryKeyFields) == 0 || metadata.generatedKeyField != nil {
+		return nil, false
+	}
+
+	uniqueExpression := make(goqu.Ex, len(metadata.primaryKeyFields))
+	for _, primaryKeyField := range metadata.primaryKeyFields {
+		uniqueExpression[primaryKeyField.column] = entryValue.FieldByIndex(primaryKeyField.index).Interface()
+	}
+
+	return uniqueExpression, true
+}
+
 func (*entryInfoProvider) OnCreateIfEntryIsOnCreator(entry interface{}) {
 	onCreatorEntry, ok := entry.(OnCreator)
 	if ok {
@@ -66,3 +228,362 @@ func (*entryInfoProvider) OnUpdateIfEntryIsOnCreator(entry interface{}) {
 		onUpdaterEntry.OnUpdate()
 	}
 }
+
+// GetUniqueColumns returns the sorted column names of the unique expression of entry, see GetUniqueExpression()
+func (provider *entryInfoProvider) GetUniqueColumns(entry interface{}) ([]string, bool) {
+	uniqueExpression, ok := provider.GetUniqueExpression(entry)
+	if !ok || len(uniqueExpression) == 0 {
+		return nil, false
+	}
+
+	uniqueColumns := make([]string, 0, len(uniqueExpression))
+	for column := range uniqueExpression {
+		uniqueColumns = append(uniqueColumns, column)
+	}
+
+	sort.Strings(uniqueColumns)
+
+	return uniqueColumns, true
+}
+
+// GetUpsertEntries returns copies of entry with OnCreate() and OnUpdate() applied, unless entry is versioned or soft deleted
+func (provider *entryInfoProvider) GetUpsertEntries(entry interface{}) (createEntry, updateEntry interface{}, ok bool) {
+	entryValue := reflect.ValueOf(entry)
+	if entryValue.Kind() != reflect.Ptr || entryValue.IsNil() || entryValue.Elem().Kind() != reflect.Struct {
+		return nil, nil, false
+	}
+
+	if _, _, isVersioned := provider.GetVersion(entry); isVersioned {
+		return nil, nil, false
+	}
+
+	if _, isSoftDeleter := provider.GetSoftDeleteColumn(entry); isSoftDeleter {
+		return nil, nil, false
+	}
+
+	createEntry, _ = provider.CopyEntry(entry)
+	provider.OnCreateIfEntryIsOnCreator(createEntry)
+
+	updateEntry, _ = provider.CopyEntry(entry)
+	provider.OnUpdateIfEntryIsOnCreator(updateEntry)
+
+	return createEntry, updateEntry, true
+}
+
+// CopyEntry returns a shallow copy of entry
+func (*entryInfoProvider) CopyEntry(entry interface{}) (entryCopy interface{}, ok bool) {
+	entryValue := reflect.ValueOf(entry)
+	if entryValue.Kind() != reflect.Ptr || entryValue.IsNil() || entryValue.Elem().Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	entryCopyValue := reflect.New(entryValue.Elem().Type())
+	entryCopyValue.Elem().Set(entryValue.Elem())
+
+	return entryCopyValue.Interface(), true
+}
+
+// SetEntry overwrites entry with the value of source, both must be pointers to the same struct type
+func (*entryInfoProvider) SetEntry(entry, source interface{}) {
+	reflect.ValueOf(entry).Elem().Set(reflect.ValueOf(source).Elem())
+}
+
+// GetInsertRecord returns the columns and values of entry to be inserted, skipping fields tagged with skipinsert
+func (*entryInfoProvider) GetInsertRecord(entry interface{}) (exp.Record, error) {
+	return exp.NewRecordFromStruct(reflect.Indirect(reflect.ValueOf(entry)).Interface(), true, false)
+}
+
+// GetUpdateRecord returns the columns and values of entry to be updated, skipping fields tagged with skipupdate
+func (*entryInfoProvider) GetUpdateRecord(entry interface{}) (exp.Record, error) {
+	return exp.NewRecordFromStruct(reflect.Indirect(reflect.ValueOf(entry)).Interface(), false, true)
+}
+
+// OnUpdateColumns runs OnUpdate() of entry, and returns the update record of columns, plus the columns changed by
+// OnUpdate(), so that they are written as well
+func (provider *entryInfoProvider) OnUpdateColumns(entry interface{}, columns []string) (exp.Record, error) {
+	recordBeforeOnUpdate, err := provider.GetUpdateRecord(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	provider.OnUpdateIfEntryIsOnCreator(entry)
+
+	recordAfterOnUpdate, err := provider.GetUpdateRecord(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	updateRecord := make(exp.Record, len(columns))
+
+	for _, column := range columns {
+		value, ok := recordAfterOnUpdate[column]
+		if !ok {
+			return nil, ErrColumnNotUpdatable
+		}
+
+		updateRecord[column] = value
+	}
+
+	for column, value := range recordAfterOnUpdate {
+		if !reflect.DeepEqual(value, recordBeforeOnUpdate[column]) {
+			updateRecord[column] = value
+		}
+	}
+
+	return updateRecord, nil
+}
+
+// GetEntryList returns pointers to the elements of entries, a slice of structs or pointers to structs
+func (*entryInfoProvider) GetEntryList(entries interface{}) ([]interface{}, error) {
+	entriesValue := reflect.ValueOf(entries)
+	if entriesValue.Kind() == reflect.Ptr {
+		entriesValue = entriesValue.Elem()
+	}
+
+	if entriesValue.Kind() != reflect.Slice {
+		return nil, ErrSliceExpected
+	}
+
+	entryList := make([]interface{}, 0, entriesValue.Len())
+
+	for i := 0; i < entriesValue.Len(); i++ {
+		entryValue := entriesValue.Index(i)
+
+		switch {
+		case entryValue.Kind() == reflect.Struct:
+			entryList = append(entryList, entryValue.Addr().Interface())
+		case entryValue.Kind() == reflect.Ptr && entryValue.Type().Elem().Kind() == reflect.Struct:
+			if entryValue.IsNil() {
+				return nil, ErrNilEntry
+			}
+
+			entryList = append(entryList, entryValue.Interface())
+		case entryValue.Kind() == reflect.Interface && !entryValue.IsNil():
+			entryList = append(entryList, entryValue.Interface())
+		case entryValue.Kind() == reflect.Interface:
+			return nil, ErrNilEntry
+		default:
+			return nil, ErrSliceExpected
+		}
+	}
+
+	return entryList, nil
+}
+
+// GroupEntriesByTableName groups entryList by table name, in the order of their first appearance
+func (provider *entryInfoProvider) GroupEntriesByTableName(
+	entryList []interface{},
+) (tableNames []string, entryGroups map[string][]interface{}, err error) {
+	entryGroups = make(map[string][]interface{})
+
+	for _, entry := range entryList {
+		tableName, err := provider.GetEntryTableName(entry)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if _, ok := entryGroups[tableName]; !ok {
+			tableNames = append(tableNames, tableName)
+		}
+
+		entryGroups[tableName] = append(entryGroups[tableName], entry)
+	}
+
+	return tableNames, entryGroups, nil
+}
+
+// GetVersion returns the version column and the current version of entry, if entry implements Versioned or has an
+// integer field tagged with `miniorm:"version"`
+func (*entryInfoProvider) GetVersion(entry interface{}) (versionColumn string, version int64, ok bool) {
+	if versionedEntry, ok := entry.(Versioned); ok {
+		versionColumn, version := versionedEntry.GetVersion()
+
+		return versionColumn, version, true
+	}
+
+	metadata, entryValue, ok := getModelMetadata(entry)
+	if !ok || metadata.versionField == nil {
+		return "", 0, false
+	}
+
+	return metadata.versionField.column, entryValue.FieldByIndex(metadata.versionField.index).Int(), true
+}
+
+// SetVersion sets the version of entry, if entry implements Versioned or has a settable version field
+func (*entryInfoProvider) SetVersion(entry interface{}, version int64) {
+	if versionedEntry, ok := entry.(Versioned); ok {
+		versionedEntry.SetVersion(version)
+
+		return
+	}
+
+	metadata, entryValue, ok := getModelMetadata(entry)
+	if !ok || metadata.versionField == nil {
+		return
+	}
+
+	if versionField := entryValue.FieldByIndex(metadata.versionField.index); versionField.CanSet() {
+		versionField.SetInt(version)
+	}
+}
+
+// GetSoftDeleteColumn returns the soft delete column of entry
+func (*entryInfoProvider) GetSoftDeleteColumn(entry interface{}) (softDeleteColumn string, ok bool) {
+	if softDeleterEntry, ok := entry.(SoftDeleter); ok {
+		return softDeleterEntry.GetSoftDeleteColumn(), true
+	}
+
+	metadata, _, ok := getModelMetadata(entry)
+	if !ok || metadata.softDeleteField == nil {
+		return "", false
+	}
+
+	return metadata.softDeleteField.column, true
+}
+
+// GetTableSoftDeleteModel returns the soft deleted model of tableName and its soft delete column
+func (provider *entryInfoProvider) GetTableSoftDeleteModel(tableName string) (model interface{}, softDeleteColumn string, ok bool) {
+	model, ok = provider.tableNameToSoftDeleteModel[tableName]
+	if !ok {
+		return nil, "", false
+	}
+
+	softDeleteColumn, _ = provider.GetSoftDeleteColumn(model)
+
+	return model, softDeleteColumn, true
+}
+
+// GetEntryListEntryType returns the type of the elements of entryList, a pointer to a slice of structs or pointers to
+// structs
+func (*entryInfoProvider) GetEntryListEntryType(entryList interface{}) (entryType reflect.Type, ok bool) {
+	entryListType := reflect.TypeOf(entryList)
+	for entryListType != nil && entryListType.Kind() == reflect.Ptr {
+		entryListType = entryListType.Elem()
+	}
+
+	if entryListType == nil || entryListType.Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	entryType = entryListType.Elem()
+
+	structType := entryType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	if structType.Kind() != reflect.Struct {
+		return nil, false
+	}
+
+	return entryType, true
+}
+
+// GetEntryListSoftDeleteColumn returns the soft delete column of the elements of entryList
+func (provider *entryInfoProvider) GetEntryListSoftDeleteColumn(entryList interface{}) (softDeleteColumn string, ok bool) {
+	entryType, ok := provider.GetEntryListEntryType(entryList)
+	if !ok {
+		return "", false
+	}
+
+	if entryType.Kind() == reflect.Ptr {
+		entryType = entryType.Elem()
+	}
+
+	return provider.GetSoftDeleteColumn(reflect.New(entryType).Interface())
+}
+
+// GetRelation returns the relation of entry loaded into relationField, from RelationsGetter or from the hasmany and
+// belongsto tags
+func (*entryInfoProvider) GetRelation(entry interface{}, relationField string) (Relation, bool) {
+	relations := []Relation(nil)
+	if relationsGetter, ok := entry.(RelationsGetter); ok {
+		relations = relationsGetter.GetRelations()
+	} else if metadata, _, ok := getModelMetadata(entry); ok {
+		relations = metadata.relations
+	}
+
+	for _, relation := range relations {
+		if relation.Field == relationField {
+			return relation, true
+		}
+	}
+
+	return Relation{}, false
+}
+
+// GetEntryScopedSelectExpression returns the select expression of entry, excluding soft deleted rows unless unscoped
+func (provider *entryInfoProvider) GetEntryScopedSelectExpression(
+	entry interface{},
+	unscoped bool,
+) (exp.Expression, error) {
+	selectEntryUniqueExpression, err := provider.GetEntrySelectExpression(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if unscoped {
+		return selectEntryUniqueExpression, nil
+	}
+
+	softDeleteColumn, _ := provider.GetSoftDeleteColumn(entry)
+
+	return withSoftDeleteFilter(selectEntryUniqueExpression, softDeleteColumn), nil
+}
+
+// SetDeletedAt marks entry as deleted at deletedAt
+func (*entryInfoProvider) SetDeletedAt(entry interface{}, deletedAt time.Time) {
+	if softDeleterEntry, ok := entry.(SoftDeleter); ok {
+		softDeleterEntry.SetDeletedAt(deletedAt)
+
+		return
+	}
+
+	metadata, entryValue, ok := getModelMetadata(entry)
+	if !ok || metadata.softDeleteField == nil {
+		return
+	}
+
+	deletedAtField := entryValue.FieldByIndex(metadata.softDeleteField.index)
+	if !deletedAtField.CanSet() {
+		return
+	}
+
+	switch deletedAtField.Interface().(type) {
+	case *time.Time:
+		deletedAtField.Set(reflect.ValueOf(&deletedAt))
+	case sql.NullTime:
+		deletedAtField.Set(reflect.ValueOf(sql.NullTime{Time: deletedAt, Valid: true}))
+	case sql.NullInt64:
+		deletedAtField.Set(reflect.ValueOf(sql.NullInt64{Int64: deletedAt.Unix(), Valid: true}))
+	default:
+		if deletedAtField.Kind() != reflect.Ptr {
+			return
+		}
+
+		if isIntKind(deletedAtField.Type().Elem().Kind()) {
+			unixTime := reflect.New(deletedAtField.Type().Elem())
+			unixTime.Elem().SetInt(deletedAt.Unix())
+			deletedAtField.Set(unixTime)
+		}
+	}
+}
+
+// GetSoftDeleteValue returns the value of softDeleteColumn of entry, or deletedAt if entry has no field for it
+func (provider *entryInfoProvider) GetSoftDeleteValue(
+	entry interface{},
+	softDeleteColumn string,
+	deletedAt time.Time,
+) interface{} {
+	updateRecord, err := provider.GetUpdateRecord(entry)
+	if err != nil {
+		return deletedAt
+	}
+
+	softDeleteValue, ok := updateRecord[softDeleteColumn]
+	if !ok {
+		return deletedAt
+	}
+
+	return softDeleteValue
+}
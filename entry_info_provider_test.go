@@ -1,7 +1,10 @@
 package miniorm
 
 import (
+	"database/sql"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/golang/mock/gomock"
@@ -75,6 +78,90 @@ func TestEntryInfoProviderGetID(t *testing.T) {
 	}
 }
 
+func TestEntryInfoProviderGetKey(t *testing.T) {
+	t.Parallel()
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	expectedKeyColumn := "column name 1"
+	expectedKeyValue := "key 1"
+	keyGetter := NewMockKeyGetter(mockController)
+	keyGetter.EXPECT().GetKey().Return(expectedKeyColumn, expectedKeyValue).Times(1)
+	keyColumn, keyValue, err := entryInfoProvider.GetKey(keyGetter)
+	assert.Equal(t, expectedKeyColumn, keyColumn)
+	assert.Equal(t, expectedKeyValue, keyValue)
+	assert.Nil(t, err)
+
+	keyColumn, keyValue, err = entryInfoProvider.GetKey(&getIDEntry{ID: 2})
+	assert.Equal(t, getIDEntryIDColumnName, keyColumn)
+	assert.Equal(t, int64(2), keyValue)
+	assert.Nil(t, err)
+
+	keyColumn, keyValue, err = entryInfoProvider.GetKey(&taggedKeyEntry{ID: "key 3"})
+	assert.Equal(t, getKeyEntryIDColumnName, keyColumn)
+	assert.Equal(t, "key 3", keyValue)
+	assert.Nil(t, err)
+
+	testCaseList := []interface{}{
+		1,
+		"string",
+		&struct{}{},
+		&taggedUniqueEntry{},
+	}
+
+	for _, testCase := range testCaseList {
+		keyColumn, keyValue, err := entryInfoProvider.GetKey(testCase)
+		assert.Empty(t, keyColumn)
+		assert.Nil(t, keyValue)
+		assert.ErrorIs(t, err, ErrKeyGetterExpected)
+	}
+}
+
+func TestEntryInfoProviderGetKeyDestination(t *testing.T) {
+	t.Parallel()
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	keyEntry := &getKeyEntry{}
+	keyColumn, keyDestination, ok := entryInfoProvider.GetKeyDestination(keyEntry)
+	assert.True(t, ok)
+	assert.Equal(t, getKeyEntryIDColumnName, keyColumn)
+	assert.Equal(t, &keyEntry.ID, keyDestination)
+
+	idEntry := &getIDEntry{}
+	keyColumn, keyDestination, ok = entryInfoProvider.GetKeyDestination(idEntry)
+	assert.True(t, ok)
+	assert.Equal(t, getIDEntryIDColumnName, keyColumn)
+	assert.Nil(t, keyDestination.(sql.Scanner).Scan(int64(4)))
+	assert.Equal(t, int64(4), idEntry.ID)
+
+	generatedKeyEntry := &struct {
+		UUID string `db:"uuid" miniorm:"pk,generated"`
+	}{}
+	keyColumn, keyDestination, ok = entryInfoProvider.GetKeyDestination(generatedKeyEntry)
+	assert.True(t, ok)
+	assert.Equal(t, "uuid", keyColumn)
+	assert.Equal(t, &generatedKeyEntry.UUID, keyDestination)
+
+	testCaseList := []interface{}{
+		1,
+		"string",
+		&struct{}{},
+		&taggedKeyEntry{},
+		taggedIDEntry{},
+	}
+
+	for _, testCase := range testCaseList {
+		keyColumn, keyDestination, ok := entryInfoProvider.GetKeyDestination(testCase)
+		assert.Empty(t, keyColumn)
+		assert.Nil(t, keyDestination)
+		assert.False(t, ok)
+	}
+}
+
 func TestEntryInfoProviderGetEntrySelectExpression(t *testing.T) {
 	t.Parallel()
 
@@ -181,3 +268,324 @@ func TestEntryInfoProviderOnCreateIfEntryIsOnUpdater(t *testing.T) {
 		})
 	}
 }
+
+func TestEntryInfoProviderOnUpdateColumns(t *testing.T) {
+	t.Parallel()
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	entry := &getIDEntryWithOnCreateAndOnUpdate{ID: 1, StringCol: "value", BytesCol: ([]byte)("bytes")}
+	updateRecord, err := entryInfoProvider.OnUpdateColumns(entry, []string{"string_col"})
+	assert.Nil(t, err)
+	assert.Equal(t, goqu.Record{"string_col": "value", getIDEntryOnUpdateCountColumnName: int64(1)}, updateRecord)
+
+	updateRecord, err = entryInfoProvider.OnUpdateColumns(&getIDEntry{}, []string{"bytes_col"})
+	assert.Nil(t, err)
+	assert.Equal(t, goqu.Record{"bytes_col": ([]byte)(nil)}, updateRecord)
+
+	_, err = entryInfoProvider.OnUpdateColumns(&getIDEntry{}, []string{getIDEntryIDColumnName})
+	assert.ErrorIs(t, err, ErrColumnNotUpdatable)
+}
+
+func TestEntryInfoProviderGetUniqueColumns(t *testing.T) {
+	t.Parallel()
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	uniqueGetter := NewMockUniqueGetter(mockController)
+	uniqueGetter.EXPECT().GetUniqueExpression().Return(goqu.Ex{"column 2": 2, "column 1": 1}).Times(1)
+	uniqueColumns, ok := entryInfoProvider.GetUniqueColumns(uniqueGetter)
+	assert.Equal(t, []string{"column 1", "column 2"}, uniqueColumns)
+	assert.True(t, ok)
+
+	emptyUniqueGetter := NewMockUniqueGetter(mockController)
+	emptyUniqueGetter.EXPECT().GetUniqueExpression().Return(goqu.Ex{}).Times(1)
+	uniqueColumns, ok = entryInfoProvider.GetUniqueColumns(emptyUniqueGetter)
+	assert.Nil(t, uniqueColumns)
+	assert.False(t, ok)
+
+	testCaseList := []interface{}{
+		1,
+		1.0,
+		"string",
+		true,
+		false,
+		&struct{}{},
+		struct{}{},
+		&getIDEntry{},
+	}
+
+	for _, testCase := range testCaseList {
+		uniqueColumns, ok := entryInfoProvider.GetUniqueColumns(testCase)
+		assert.Nil(t, uniqueColumns)
+		assert.False(t, ok)
+	}
+}
+
+func TestEntryInfoProviderGetUpsertEntries(t *testing.T) {
+	t.Parallel()
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	entry := &getUniqueEntryWithOnCreateAndOnUpdate{ID1: 1, ID2: 2, StringCol: "value 1"}
+	createEntry, updateEntry, ok := entryInfoProvider.GetUpsertEntries(entry)
+	assert.True(t, ok)
+	assert.Equal(t, &getUniqueEntryWithOnCreateAndOnUpdate{ID1: 1, ID2: 2, StringCol: "value 1"}, entry)
+	assert.Equal(t, &getUniqueEntryWithOnCreateAndOnUpdate{ID1: 1, ID2: 2, StringCol: "value 1", OnCreateCount: 1}, createEntry)
+	assert.Equal(t, &getUniqueEntryWithOnCreateAndOnUpdate{ID1: 1, ID2: 2, StringCol: "value 1", OnUpdateCount: 1}, updateEntry)
+
+	entryInfoProvider.SetEntry(entry, updateEntry)
+	assert.Equal(t, &getUniqueEntryWithOnCreateAndOnUpdate{ID1: 1, ID2: 2, StringCol: "value 1", OnUpdateCount: 1}, entry)
+
+	testCaseList := []interface{}{
+		1,
+		1.0,
+		"string",
+		true,
+		false,
+		struct{}{},
+		(*getIDEntry)(nil),
+		&versionedIDEntry{},
+	}
+
+	for _, testCase := range testCaseList {
+		createEntry, updateEntry, ok := entryInfoProvider.GetUpsertEntries(testCase)
+		assert.Nil(t, createEntry)
+		assert.Nil(t, updateEntry)
+		assert.False(t, ok)
+	}
+}
+
+func TestEntryInfoProviderGetEntryList(t *testing.T) {
+	t.Parallel()
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	entry1 := &getIDEntry{ID: 1}
+	entry2 := &getUniqueEntryWithOnCreateAndOnUpdate{ID1: 1, ID2: 2}
+	entryList, err := entryInfoProvider.GetEntryList([]interface{}{entry1, entry2})
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{entry1, entry2}, entryList)
+
+	entries := []getIDEntry{{ID: 1}, {ID: 2}}
+	entryList, err = entryInfoProvider.GetEntryList(&entries)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{&entries[0], &entries[1]}, entryList)
+
+	tableNames, entryGroups, err := entryInfoProvider.GroupEntriesByTableName(
+		[]interface{}{entry1, entry2, &entries[0]},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{getIDEntryTableName, getUniqueEntryTableName}, tableNames)
+	assert.Equal(t, map[string][]interface{}{
+		getIDEntryTableName:     {entry1, &entries[0]},
+		getUniqueEntryTableName: {entry2},
+	}, entryGroups)
+
+	testCaseList := []interface{}{
+		1,
+		1.0,
+		"string",
+		true,
+		false,
+		&struct{}{},
+		struct{}{},
+	}
+
+	for _, testCase := range testCaseList {
+		entryList, err := entryInfoProvider.GetEntryList(testCase)
+		assert.Nil(t, entryList)
+		assert.ErrorIs(t, err, ErrSliceExpected)
+	}
+}
+
+func TestEntryInfoProviderGetEntryListEntryType(t *testing.T) {
+	t.Parallel()
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	entryType, ok := entryInfoProvider.GetEntryListEntryType(&[]getIDEntry{})
+	assert.True(t, ok)
+	assert.Equal(t, reflect.TypeOf(getIDEntry{}), entryType)
+
+	entryType, ok = entryInfoProvider.GetEntryListEntryType(&[]*getIDEntry{})
+	assert.True(t, ok)
+	assert.Equal(t, reflect.TypeOf(&getIDEntry{}), entryType)
+
+	testCaseList := []interface{}{
+		nil,
+		1,
+		"string",
+		&[]int64{},
+		&[]*int64{},
+		&getIDEntry{},
+	}
+
+	for _, testCase := range testCaseList {
+		entryType, ok := entryInfoProvider.GetEntryListEntryType(testCase)
+		assert.Nil(t, entryType)
+		assert.False(t, ok)
+	}
+}
+
+func TestEntryInfoProviderGetRelation(t *testing.T) {
+	t.Parallel()
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	relation, ok := entryInfoProvider.GetRelation(&getParentEntry{}, "Children")
+	assert.True(t, ok)
+	assert.Equal(t, Relation{Field: "Children", Type: RelationTypeHasMany, ForeignKey: "parent_id"}, relation)
+
+	relation, ok = entryInfoProvider.GetRelation(&getChildEntry{}, "Parent")
+	assert.True(t, ok)
+	assert.Equal(t, Relation{Field: "Parent", Type: RelationTypeBelongsTo, ForeignKey: "parent_id"}, relation)
+
+	relation, ok = entryInfoProvider.GetRelation(&getParentEntryWithRelations{}, "Children")
+	assert.True(t, ok)
+	assert.Equal(t, Relation{Field: "Children", Type: RelationTypeHasMany, ForeignKey: "parent_id"}, relation)
+
+	_, ok = entryInfoProvider.GetRelation(&getParentEntry{}, "StringCol")
+	assert.False(t, ok)
+
+	_, ok = entryInfoProvider.GetRelation(&getIDEntry{}, "Children")
+	assert.False(t, ok)
+}
+
+func TestEntryInfoProviderGetVersion(t *testing.T) {
+	t.Parallel()
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	versioned := NewMockVersioned(mockController)
+	versioned.EXPECT().GetVersion().Return("revision", int64(3)).Times(1)
+	versioned.EXPECT().SetVersion(int64(4)).Times(1)
+	versionColumn, version, ok := entryInfoProvider.GetVersion(versioned)
+	assert.Equal(t, "revision", versionColumn)
+	assert.Equal(t, int64(3), version)
+	assert.True(t, ok)
+	entryInfoProvider.SetVersion(versioned, 4)
+
+	entry := &versionedIDEntry{Version: 5}
+	versionColumn, version, ok = entryInfoProvider.GetVersion(entry)
+	assert.Equal(t, "version", versionColumn)
+	assert.Equal(t, int64(5), version)
+	assert.True(t, ok)
+	entryInfoProvider.SetVersion(entry, 6)
+	assert.Equal(t, int64(6), entry.Version)
+
+	testCaseList := []interface{}{
+		1,
+		1.0,
+		"string",
+		true,
+		false,
+		&struct{}{},
+		struct{}{},
+		&getIDEntry{},
+		(*versionedIDEntry)(nil),
+		&struct {
+			Version string `miniorm:"version"`
+		}{},
+	}
+
+	for _, testCase := range testCaseList {
+		versionColumn, version, ok := entryInfoProvider.GetVersion(testCase)
+		assert.Empty(t, versionColumn)
+		assert.Zero(t, version)
+		assert.False(t, ok)
+		assert.NotPanics(t, func() {
+			entryInfoProvider.SetVersion(testCase, 1)
+		})
+	}
+}
+
+func TestEntryInfoProviderGetSoftDeleteColumn(t *testing.T) {
+	t.Parallel()
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	softDeleter := NewMockSoftDeleter(mockController)
+	softDeleter.EXPECT().GetSoftDeleteColumn().Return("removed_at").Times(1)
+	softDeleteColumn, ok := entryInfoProvider.GetSoftDeleteColumn(softDeleter)
+	assert.Equal(t, "removed_at", softDeleteColumn)
+	assert.True(t, ok)
+
+	softDeleteColumn, ok = entryInfoProvider.GetSoftDeleteColumn(&softDeleteIDEntry{})
+	assert.Equal(t, "deleted_at", softDeleteColumn)
+	assert.True(t, ok)
+
+	softDeleteColumn, ok = entryInfoProvider.GetEntryListSoftDeleteColumn(&[]*softDeleteIDEntry{})
+	assert.Equal(t, "deleted_at", softDeleteColumn)
+	assert.True(t, ok)
+
+	softDeleteColumn, ok = entryInfoProvider.GetEntryListSoftDeleteColumn(&[]softDeleteUniqueEntry{})
+	assert.Equal(t, "deleted_at", softDeleteColumn)
+	assert.True(t, ok)
+
+	testCaseList := []interface{}{
+		1,
+		"string",
+		&struct{}{},
+		&getIDEntry{},
+		&[]getIDEntry{},
+		[]int{},
+		nil,
+	}
+
+	for _, testCase := range testCaseList {
+		softDeleteColumn, ok := entryInfoProvider.GetSoftDeleteColumn(testCase)
+		assert.Empty(t, softDeleteColumn)
+		assert.False(t, ok)
+
+		softDeleteColumn, ok = entryInfoProvider.GetEntryListSoftDeleteColumn(testCase)
+		assert.Empty(t, softDeleteColumn)
+		assert.False(t, ok)
+	}
+}
+
+func TestEntryInfoProviderSetDeletedAt(t *testing.T) {
+	t.Parallel()
+
+	entryInfoProvider := newEntryInfoProvider()
+	deletedAt := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	unixDeletedAt := deletedAt.Unix()
+
+	entry := &softDeleteIDEntry{}
+	entryInfoProvider.SetDeletedAt(entry, deletedAt)
+	assert.Equal(t, &unixDeletedAt, entry.DeletedAt)
+	assert.Equal(t, &unixDeletedAt, entryInfoProvider.GetSoftDeleteValue(entry, "deleted_at", deletedAt))
+
+	timeEntry := &struct {
+		DeletedAt *time.Time `db:"deleted_at" miniorm:"softdelete"`
+	}{}
+	entryInfoProvider.SetDeletedAt(timeEntry, deletedAt)
+	assert.Equal(t, &deletedAt, timeEntry.DeletedAt)
+
+	nullTimeEntry := &struct {
+		DeletedAt sql.NullTime `db:"deleted_at" miniorm:"softdelete"`
+	}{}
+	entryInfoProvider.SetDeletedAt(nullTimeEntry, deletedAt)
+	assert.Equal(t, sql.NullTime{Time: deletedAt, Valid: true}, nullTimeEntry.DeletedAt)
+
+	nullInt64Entry := &struct {
+		DeletedAt sql.NullInt64 `db:"deleted_at" miniorm:"softdelete"`
+	}{}
+	entryInfoProvider.SetDeletedAt(nullInt64Entry, deletedAt)
+	assert.Equal(t, sql.NullInt64{Int64: unixDeletedAt, Valid: true}, nullInt64Entry.DeletedAt)
+
+	uniqueEntry := &softDeleteUniqueEntry{}
+	entryInfoProvider.SetDeletedAt(uniqueEntry, deletedAt)
+	assert.Equal(t, &unixDeletedAt, uniqueEntry.RemovedAt)
+
+	assert.Equal(t, deletedAt, entryInfoProvider.GetSoftDeleteValue(&getIDEntry{}, "deleted_at", deletedAt))
+}
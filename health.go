@@ -0,0 +1,147 @@
+package miniorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	startupRetryInitialDelay = 100 * time.Millisecond
+	startupRetryMaxDelay     = 5 * time.Second
+)
+
+var (
+	configDriverTypeToServerVersionStatement = map[DriverType]string{
+		DriverTypeMSSQL:    "SELECT @@VERSION",
+		DriverTypeMySQL:    "SELECT VERSION()",
+		DriverTypePostgres: "SELECT version()",
+		DriverTypeSQLite3:  "SELECT sqlite_version()",
+	}
+
+	ErrCloseInTransaction = errors.New("expected Close() to be called outside of transactions")
+)
+
+// connectionPool is the part of *sql.DB used to check and close the connections of an ORM, also implemented by the
+// types embedding it, like *sqlx.DB
+type connectionPool interface {
+	PingContext(ctx context.Context) error
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	Stats() sql.DBStats
+	Close() error
+}
+
+// HealthStatus is the result of the HealthCheck() of a database and of its replicas
+type HealthStatus struct {
+	Driver DriverType
+	// ServerVersion is the version reported by the database engine, or empty if the probe failed
+	ServerVersion string
+	// Latency is the duration of the probe
+	Latency time.Duration
+	// Stats are the statistics of the connection pool, taken after the probe
+	Stats sql.DBStats
+	// Err is the error of the probe, if any
+	Err error
+	// Replicas are the statuses of the read replicas, in the order of the configuration
+	Replicas []HealthStatus
+}
+
+// checkHealth probes db and its replicas, returning the status of each and the first error
+func checkHealth(ctx context.Context, driverType DriverType, db connectionPool, replicas *replicaSet) (HealthStatus, error) {
+	status := checkDatabaseHealth(ctx, driverType, db)
+	err := status.Err
+
+	if replicas != nil {
+		for _, replica := range replicas.replicas {
+			replicaStatus := checkDatabaseHealth(ctx, driverType, replica.sqlDB)
+			status.Replicas = append(status.Replicas, replicaStatus)
+
+			if err == nil {
+				err = replicaStatus.Err
+			}
+		}
+	}
+
+	return status, err
+}
+
+// checkDatabaseHealth runs the cheap server version statement of driverType on db, bypassing the retries, logging and
+// instrumentation of the statements of the ORM
+func checkDatabaseHealth(ctx context.Context, driverType DriverType, db connectionPool) HealthStatus {
+	status := HealthStatus{Driver: driverType}
+
+	startTime := time.Now()
+	status.Err = db.QueryRowContext(ctx, configDriverTypeToServerVersionStatement[driverType]).Scan(&status.ServerVersion)
+	status.Latency = time.Since(startTime)
+	status.Stats = db.Stats()
+
+	return status
+}
+
+// pingDatabases verifies the connections to db and to its replicas
+func pingDatabases(ctx context.Context, db connectionPool, replicas *replicaSet) error {
+	if err := db.PingContext(ctx); err != nil {
+		return err
+	}
+
+	if replicas != nil {
+		for _, replica := range replicas.replicas {
+			if err := replica.sqlDB.PingContext(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// closeDatabases closes the replicas, and db unless it was opened by the caller of the constructor, returning the
+// error of closing db
+func closeDatabases(db connectionPool, ownsDB bool, replicas *replicaSet) error {
+	if replicas != nil {
+		replicas.close()
+	}
+
+	if !ownsDB {
+		return nil
+	}
+
+	return db.Close()
+}
+
+// waitForDatabase pings db until it succeeds or StartupTimeoutInSeconds elapses, e.g. while the database engine is
+// still starting alongside the application. It returns immediately if StartupTimeoutInSeconds is not set.
+func waitForDatabase(db connectionPool, databaseConfig DatabaseConfig) error {
+	if databaseConfig.StartupTimeoutInSeconds <= 0 {
+		return nil
+	}
+
+	timeout := time.Duration(databaseConfig.StartupTimeoutInSeconds) * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return withRetry(ctx, &startupRetryPolicy{timeout: timeout}, databaseConfig.Driver, func() error {
+		return db.PingContext(ctx)
+	})
+}
+
+// startupRetryPolicy retries every error of waitForDatabase, with a delay doubling from startupRetryInitialDelay up
+// to startupRetryMaxDelay, as long as the next attempt starts before timeout
+type startupRetryPolicy struct {
+	timeout time.Duration
+}
+
+func (policy *startupRetryPolicy) NextDelay(_ DriverType, _ error, attempt uint, elapsed time.Duration) (time.Duration, bool) {
+	delay := startupRetryMaxDelay
+	if attempt < 32 && startupRetryInitialDelay<<(attempt-1) < startupRetryMaxDelay {
+		delay = startupRetryInitialDelay << (attempt - 1)
+	}
+
+	if elapsed+delay >= policy.timeout {
+		return 0, false
+	}
+
+	return delay, true
+}
@@ -0,0 +1,85 @@
+package miniorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartupRetryPolicy(t *testing.T) {
+	policy := &startupRetryPolicy{timeout: 30 * time.Second}
+
+	for _, testCase := range []struct {
+		attempt uint
+		elapsed time.Duration
+		delay   time.Duration
+		ok      bool
+	}{
+		{attempt: 1, delay: 100 * time.Millisecond, ok: true},
+		{attempt: 2, delay: 200 * time.Millisecond, ok: true},
+		{attempt: 6, delay: 3200 * time.Millisecond, ok: true},
+		{attempt: 7, delay: 5 * time.Second, ok: true},
+		{attempt: 100, delay: 5 * time.Second, ok: true},
+		{attempt: 7, elapsed: 25 * time.Second, ok: false},
+	} {
+		delay, ok := policy.NextDelay(DriverTypeMySQL, assert.AnError, testCase.attempt, testCase.elapsed)
+		assert.Equal(t, testCase.ok, ok, "attempt %d", testCase.attempt)
+
+		if testCase.ok {
+			assert.Equal(t, testCase.delay, delay, "attempt %d", testCase.attempt)
+		}
+	}
+}
+
+func TestNewORMStartupTimeout(t *testing.T) {
+	databaseConfig := mysqlTestConfig
+	databaseConfig.Host = "127.0.0.1"
+	databaseConfig.Port = 1
+	databaseConfig.StartupTimeoutInSeconds = 1
+
+	startTime := time.Now()
+	orm, err := NewORM(databaseConfig)
+	elapsed := time.Since(startTime)
+
+	assert.Nil(t, orm)
+	assert.NotNil(t, err)
+	assert.GreaterOrEqual(t, elapsed, 500*time.Millisecond)
+	assert.Less(t, elapsed, 3*time.Second)
+}
+
+func TestNewORMStartupTimeoutReachable(t *testing.T) {
+	databaseConfig := sqlite3TestConfigRetry
+	databaseConfig.StartupTimeoutInSeconds = 1
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+	assert.Nil(t, orm.Close())
+}
+
+func TestHealthInTransaction(t *testing.T) {
+	for _, databaseConfig := range []DatabaseConfig{sqlite3TestConfigRetry, sqlite3TestConfigMutex} {
+		orm, err := NewORM(databaseConfig)
+		if !assert.Nil(t, err) {
+			continue
+		}
+
+		// The ORM of a transaction checks the connection pool, on which the transaction holds a connection
+		err = orm.WithTxContext(context.Background(), nil, func(ctx context.Context, txORM ORM) error {
+			if err := txORM.Ping(ctx); err != nil {
+				return err
+			}
+
+			status, err := txORM.HealthCheck(ctx)
+			assert.Equal(t, DriverTypeSQLite3, status.Driver)
+			assert.NotEmpty(t, status.ServerVersion)
+			assert.Positive(t, status.Stats.InUse)
+
+			return err
+		})
+		assert.Nil(t, err, databaseConfig.SQLite3TransactionMode)
+
+		assert.Nil(t, orm.Close())
+	}
+}
@@ -0,0 +1,181 @@
+package miniorm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// hookFunc is a hook of an entry, or an operation run between the hooks, called with the active ORM
+type hookFunc func(ctx context.Context, orm ORM) error
+
+var (
+	afterLoaderType = reflect.TypeOf((*AfterLoader)(nil)).Elem()
+)
+
+// hookError wraps the error of a hook while its transaction is rolled back, so that the transaction is not retried by
+// SQLite3TransactionModeRetry. It is unwrapped before being returned to the caller.
+type hookError struct {
+	err error
+}
+
+func (err *hookError) Error() string {
+	return err.err.Error()
+}
+
+func (err *hookError) Unwrap() error {
+	return err.err
+}
+
+// runHook runs hook, wrapping its error into a hookError
+func runHook(ctx context.Context, orm ORM, hook hookFunc) error {
+	if err := hook(ctx, orm); err != nil {
+		return &hookError{err: err}
+	}
+
+	return nil
+}
+
+// unwrapHookError returns the error of the hook if err is a hookError, or err otherwise
+func unwrapHookError(err error) error {
+	var wrappedHookError *hookError
+	if errors.As(err, &wrappedHookError) {
+		return wrappedHookError.err
+	}
+
+	return err
+}
+
+// withHooks runs operation between beforeHook and afterHook, either of which may be nil. If there is a hook, the hooks
+// and operation run in a transaction, or a savepoint if orm is already in a transaction, and are called with its ORM,
+// so that an error of any of them rolls back the writes of all of them.
+func withHooks(ctx context.Context, orm ORM, beforeHook, afterHook, operation hookFunc) error {
+	if beforeHook == nil && afterHook == nil {
+		return operation(ctx, orm)
+	}
+
+	return unwrapHookError(orm.WithTxContext(ctx, nil, func(txCtx context.Context, txORM ORM) error {
+		if beforeHook != nil {
+			if err := runHook(txCtx, txORM, beforeHook); err != nil {
+				return err
+			}
+		}
+
+		if err := operation(txCtx, txORM); err != nil {
+			return err
+		}
+
+		if afterHook != nil {
+			return runHook(txCtx, txORM, afterHook)
+		}
+
+		return nil
+	}))
+}
+
+// withCreateHooks runs operation, which creates entry, between the BeforeCreate() and AfterCreate() hooks of entry
+func withCreateHooks(ctx context.Context, orm ORM, entry interface{}, operation hookFunc) error {
+	var beforeHook, afterHook hookFunc
+
+	if beforeCreatorEntry, ok := entry.(BeforeCreator); ok {
+		beforeHook = beforeCreatorEntry.BeforeCreate
+	}
+
+	if afterCreatorEntry, ok := entry.(AfterCreator); ok {
+		afterHook = afterCreatorEntry.AfterCreate
+	}
+
+	return withHooks(ctx, orm, beforeHook, afterHook, operation)
+}
+
+// withUpdateHooks runs operation, which updates entry, between the BeforeUpdate() and AfterUpdate() hooks of entry
+func withUpdateHooks(ctx context.Context, orm ORM, entry interface{}, operation hookFunc) error {
+	var beforeHook, afterHook hookFunc
+
+	if beforeUpdaterEntry, ok := entry.(BeforeUpdater); ok {
+		beforeHook = beforeUpdaterEntry.BeforeUpdate
+	}
+
+	if afterUpdaterEntry, ok := entry.(AfterUpdater); ok {
+		afterHook = afterUpdaterEntry.AfterUpdate
+	}
+
+	return withHooks(ctx, orm, beforeHook, afterHook, operation)
+}
+
+// withDeleteHooks runs operation, which deletes entry, between the BeforeDelete() and AfterDelete() hooks of entry
+func withDeleteHooks(ctx context.Context, orm ORM, entry interface{}, operation hookFunc) error {
+	var beforeHook, afterHook hookFunc
+
+	if beforeDeleterEntry, ok := entry.(BeforeDeleter); ok {
+		beforeHook = beforeDeleterEntry.BeforeDelete
+	}
+
+	if afterDeleterEntry, ok := entry.(AfterDeleter); ok {
+		afterHook = afterDeleterEntry.AfterDelete
+	}
+
+	return withHooks(ctx, orm, beforeHook, afterHook, operation)
+}
+
+// hasCreateOrUpdateHooks returns whether entry has any create or update hook, in which case CreateOrUpdate() has to
+// know whether the entry is created or updated before writing it
+func hasCreateOrUpdateHooks(entry interface{}) bool {
+	switch entry.(type) {
+	case BeforeCreator, AfterCreator, BeforeUpdater, AfterUpdater:
+		return true
+	default:
+		return false
+	}
+}
+
+// runAfterLoadHooks runs the AfterLoad() hooks of entries, either a pointer to an entry or a pointer to a slice of
+// entries, stopping at the first error
+func runAfterLoadHooks(ctx context.Context, orm ORM, entries interface{}) error {
+	entriesValue := reflect.ValueOf(entries)
+	if entriesValue.Kind() != reflect.Ptr || entriesValue.IsNil() {
+		return nil
+	}
+
+	if entriesValue.Elem().Kind() != reflect.Slice {
+		if afterLoaderEntry, ok := entries.(AfterLoader); ok {
+			return afterLoaderEntry.AfterLoad(ctx, orm)
+		}
+
+		return nil
+	}
+
+	entriesValue = entriesValue.Elem()
+
+	// Avoid walking through the entries if they cannot have hooks, e.g. for grouped results
+	switch elementType := entriesValue.Type().Elem(); elementType.Kind() {
+	case reflect.Struct:
+		if !reflect.PtrTo(elementType).Implements(afterLoaderType) {
+			return nil
+		}
+	case reflect.Ptr:
+		if !elementType.Implements(afterLoaderType) {
+			return nil
+		}
+	case reflect.Interface:
+	default:
+		return nil
+	}
+
+	for i := 0; i < entriesValue.Len(); i++ {
+		entryValue := entriesValue.Index(i)
+		if entryValue.Kind() == reflect.Struct {
+			entryValue = entryValue.Addr()
+		} else if entryValue.IsNil() {
+			continue
+		}
+
+		if afterLoaderEntry, ok := entryValue.Interface().(AfterLoader); ok {
+			if err := afterLoaderEntry.AfterLoad(ctx, orm); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package miniorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunAfterLoadHooks(t *testing.T) {
+	t.Parallel()
+
+	entry := &hookEntry{}
+	err := runAfterLoadHooks(context.Background(), nil, entry)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"AfterLoad"}, entry.Hooks)
+
+	entries := []hookEntry{{}, {FailingHook: "AfterLoad"}, {}}
+	err = runAfterLoadHooks(context.Background(), nil, &entries)
+	assert.ErrorIs(t, err, errHookFailed)
+	assert.Equal(t, []string{"AfterLoad"}, entries[0].Hooks)
+	assert.Equal(t, []string{"AfterLoad"}, entries[1].Hooks)
+	assert.Nil(t, entries[2].Hooks)
+
+	entryPointers := []*hookEntry{{}, nil}
+	err = runAfterLoadHooks(context.Background(), nil, &entryPointers)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"AfterLoad"}, entryPointers[0].Hooks)
+
+	entryInterfaces := []interface{}{&hookEntry{}, &getIDEntry{}, nil}
+	err = runAfterLoadHooks(context.Background(), nil, &entryInterfaces)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"AfterLoad"}, entryInterfaces[0].(*hookEntry).Hooks)
+
+	err = runAfterLoadHooks(context.Background(), nil, &[]getIDEntry{{}})
+	assert.Nil(t, err)
+
+	err = runAfterLoadHooks(context.Background(), nil, &[]int64{1})
+	assert.Nil(t, err)
+}
+
+func TestHookErrorIsNotRetried(t *testing.T) {
+	t.Parallel()
+
+	policy := &sqlite3TransactionRetryPolicy{databaseConfig: sqlite3TestConfigRetry}
+
+	_, shouldRetry := policy.NextDelay(DriverTypeSQLite3, &hookError{err: errHookFailed}, 0, 0)
+	assert.False(t, shouldRetry)
+
+	assert.Equal(t, errHookFailed, unwrapHookError(&hookError{err: errHookFailed}))
+	assert.Equal(t, errHookFailed, unwrapHookError(errHookFailed))
+}
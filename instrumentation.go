@@ -0,0 +1,526 @@
+package miniorm
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+// Operations of QueryEvent, named after the ORM methods
+const (
+	OperationCreate               = "Create"
+	OperationCreateMany           = "CreateMany"
+	OperationGet                  = "Get"
+	OperationGetWithXLock         = "GetWithXLock"
+	OperationQuery                = "Query"
+	OperationQueryWithXLock       = "QueryWithXLock"
+	OperationIterate              = "Iterate"
+	OperationIterateWithXLock     = "IterateWithXLock"
+	OperationQueryPage            = "QueryPage"
+	OperationCount                = "Count"
+	OperationSum                  = "Sum"
+	OperationMin                  = "Min"
+	OperationMax                  = "Max"
+	OperationAvg                  = "Avg"
+	OperationExists               = "Exists"
+	OperationVerifySchema         = "VerifySchema"
+	OperationUpdate               = "Update"
+	OperationUpdateColumns        = "UpdateColumns"
+	OperationUpdateWhere          = "UpdateWhere"
+	OperationUpdateWhereWithLimit = "UpdateWhereWithLimit"
+	OperationCreateOrUpdate       = "CreateOrUpdate"
+	OperationDelete               = "Delete"
+	OperationHardDelete           = "HardDelete"
+	OperationDeleteWhere          = "DeleteWhere"
+	OperationDeleteWhereWithLimit = "DeleteWhereWithLimit"
+	OperationWithTx               = "WithTx"
+)
+
+// Instrumentation observes the operations of the ORM, e.g. to trace or measure them
+type Instrumentation interface {
+	// OnQueryStart is called before an operation, returning the context of the operation
+	OnQueryStart(ctx context.Context, event *QueryEvent) context.Context
+	// OnQueryEnd is called after the operation with the completed event
+	OnQueryEnd(ctx context.Context, event *QueryEvent)
+}
+
+// QueryEvent describes an operation of the ORM
+type QueryEvent struct {
+	Driver    DriverType
+	Operation string
+	// TableName is the table of the entries, or empty for operations without a table like WithTx
+	TableName string
+	// SQL contains the statements executed by the operation, separated by ";\n"
+	SQL      string
+	ArgCount int
+	// RowsAffected is the number of rows written by the operation, or loaded into entries for reads
+	RowsAffected int64
+	StartTime    time.Time
+	Duration     time.Duration
+	Err          error
+}
+
+// MultiInstrumentation returns an Instrumentation calling each of instrumentations in order
+func MultiInstrumentation(instrumentations ...Instrumentation) Instrumentation {
+	return multiInstrumentation(instrumentations)
+}
+
+type multiInstrumentation []Instrumentation
+
+func (instrumentations multiInstrumentation) OnQueryStart(ctx context.Context, event *QueryEvent) context.Context {
+	for _, instrumentation := range instrumentations {
+		ctx = instrumentation.OnQueryStart(ctx, event)
+	}
+
+	return ctx
+}
+
+func (instrumentations multiInstrumentation) OnQueryEnd(ctx context.Context, event *QueryEvent) {
+	for i := len(instrumentations) - 1; i >= 0; i-- {
+		instrumentations[i].OnQueryEnd(ctx, event)
+	}
+}
+
+type queryRecorderContextKey struct{}
+
+// queryRecorder collects the statements executed for the operation of a context
+type queryRecorder struct {
+	lock       sync.Mutex
+	statements []string
+	argCount   int
+}
+
+// recordStatement adds a statement to the queryRecorder of ctx, if any
+func recordStatement(ctx context.Context, query string, argCount int) {
+	recorder, ok := ctx.Value(queryRecorderContextKey{}).(*queryRecorder)
+	if !ok {
+		return
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.statements = append(recorder.statements, query)
+	recorder.argCount += argCount
+}
+
+// instrumentedSQLDatabase records the statements executed outside of transactions
+type instrumentedSQLDatabase struct {
+	goqu.SQLDatabase
+}
+
+func (db *instrumentedSQLDatabase) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	recordStatement(ctx, query, len(args))
+
+	return db.SQLDatabase.ExecContext(ctx, query, args...)
+}
+
+func (db *instrumentedSQLDatabase) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	recordStatement(ctx, query, len(args))
+
+	return db.SQLDatabase.QueryContext(ctx, query, args...)
+}
+
+// instrumentedSQLTx records the statements executed in a transaction into the queryRecorder of their context
+type instrumentedSQLTx struct {
+	goqu.SQLTx
+}
+
+func (tx *instrumentedSQLTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	recordStatement(ctx, query, len(args))
+
+	return tx.SQLTx.ExecContext(ctx, query, args...)
+}
+
+func (tx *instrumentedSQLTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	recordStatement(ctx, query, len(args))
+
+	return tx.SQLTx.QueryContext(ctx, query, args...)
+}
+
+// instrumentedORM emits a QueryEvent to instrumentation for every operation of orm
+type instrumentedORM struct {
+	orm               ORM
+	entryInfoProvider *entryInfoProvider
+	driverType        DriverType
+	instrumentation   Instrumentation
+}
+
+// newInstrumentedORM returns orm, instrumented if databaseConfig has an Instrumentation
+func newInstrumentedORM(orm ORM, databaseConfig DatabaseConfig) ORM {
+	if databaseConfig.Instrumentation == nil {
+		return orm
+	}
+
+	return &instrumentedORM{
+		orm:               orm,
+		entryInfoProvider: newEntryInfoProvider(),
+		driverType:        databaseConfig.Driver,
+		instrumentation:   databaseConfig.Instrumentation,
+	}
+}
+
+func (orm *instrumentedORM) wrap(innerORM ORM) ORM {
+	wrappedORM := *orm
+	wrappedORM.orm = innerORM
+
+	return &wrappedORM
+}
+
+// observe runs operation between the OnQueryStart() and OnQueryEnd() of the instrumentation
+func (orm *instrumentedORM) observe(
+	ctx context.Context,
+	operationName string,
+	tableName string,
+	operation func(ctx context.Context) (rowsAffected int64, err error),
+) error {
+	event := &QueryEvent{
+		Driver:    orm.driverType,
+		Operation: operationName,
+		TableName: tableName,
+		StartTime: time.Now(),
+	}
+
+	ctx = orm.instrumentation.OnQueryStart(ctx, event)
+
+	recorder := &queryRecorder{}
+	rowsAffected, err := operation(context.WithValue(ctx, queryRecorderContextKey{}, recorder))
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	event.Duration = time.Since(event.StartTime)
+	event.SQL = strings.Join(recorder.statements, ";\n")
+	event.ArgCount = recorder.argCount
+	event.RowsAffected = rowsAffected
+	event.Err = err
+
+	orm.instrumentation.OnQueryEnd(ctx, event)
+
+	return err
+}
+
+// getEntryTableName returns the table name of entry, or an empty string if it has none
+func (orm *instrumentedORM) getEntryTableName(entry interface{}) string {
+	if entry == nil {
+		return ""
+	}
+
+	tableName, _ := orm.entryInfoProvider.GetEntryTableName(entry)
+
+	return tableName
+}
+
+// getEntryListLength returns the length of entryList, a pointer to a slice, or 0 if it is not a slice
+func getEntryListLength(entryList interface{}) int64 {
+	entryListValue := reflect.Indirect(reflect.ValueOf(entryList))
+	if entryListValue.Kind() != reflect.Slice {
+		return 0
+	}
+
+	return int64(entryListValue.Len())
+}
+
+func (orm *instrumentedORM) Create(ctx context.Context, entry interface{}) error {
+	return orm.write(ctx, OperationCreate, entry, orm.orm.Create)
+}
+
+func (orm *instrumentedORM) CreateMany(ctx context.Context, entries interface{}) error {
+	tableName := ""
+
+	entryList, err := orm.entryInfoProvider.GetEntryList(entries)
+	if err == nil && len(entryList) > 0 {
+		tableName = orm.getEntryTableName(entryList[0])
+	}
+
+	return orm.observe(ctx, OperationCreateMany, tableName, func(ctx context.Context) (int64, error) {
+		if err := orm.orm.CreateMany(ctx, entries); err != nil {
+			return 0, err
+		}
+
+		return int64(len(entryList)), nil
+	})
+}
+
+// write runs write, which writes entry, reporting a row affected if it succeeds
+func (orm *instrumentedORM) write(
+	ctx context.Context,
+	operationName string,
+	entry interface{},
+	write func(context.Context, interface{}) error,
+) error {
+	return orm.observe(ctx, operationName, orm.getEntryTableName(entry), func(ctx context.Context) (int64, error) {
+		if err := write(ctx, entry); err != nil {
+			return 0, err
+		}
+
+		return 1, nil
+	})
+}
+
+func (orm *instrumentedORM) get(
+	ctx context.Context,
+	operationName string,
+	entry interface{},
+	get func(context.Context, interface{}) error,
+) error {
+	return orm.observe(ctx, operationName, orm.getEntryTableName(entry), func(ctx context.Context) (int64, error) {
+		if err := get(ctx, entry); err != nil {
+			return 0, err
+		}
+
+		return 1, nil
+	})
+}
+
+func (orm *instrumentedORM) Get(ctx context.Context, entry interface{}) error {
+	return orm.get(ctx, OperationGet, entry, orm.orm.Get)
+}
+
+func (orm *instrumentedORM) GetWithXLock(ctx context.Context, entry interface{}) error {
+	return orm.get(ctx, OperationGetWithXLock, entry, orm.orm.GetWithXLock)
+}
+
+func (orm *instrumentedORM) query(
+	ctx context.Context,
+	operationName string,
+	params QueryParams,
+	query func(context.Context, QueryParams) error,
+) error {
+	return orm.observe(ctx, operationName, params.TableName, func(ctx context.Context) (int64, error) {
+		if err := query(ctx, params); err != nil {
+			return 0, err
+		}
+
+		return getEntryListLength(params.EntryList), nil
+	})
+}
+
+func (orm *instrumentedORM) Query(ctx context.Context, params QueryParams) error {
+	return orm.query(ctx, OperationQuery, params, orm.orm.Query)
+}
+
+func (orm *instrumentedORM) QueryWithXLock(ctx context.Context, params QueryParams) error {
+	return orm.query(ctx, OperationQueryWithXLock, params, orm.orm.QueryWithXLock)
+}
+
+func (orm *instrumentedORM) iterate(
+	ctx context.Context,
+	operationName string,
+	params QueryParams,
+	iterateFunc IterateFunc,
+	iterate func(context.Context, QueryParams, IterateFunc) error,
+) error {
+	return orm.observe(ctx, operationName, params.TableName, func(ctx context.Context) (int64, error) {
+		rowsLoaded := int64(0)
+		err := iterate(ctx, params, func(entry interface{}) error {
+			rowsLoaded++
+
+			return iterateFunc(entry)
+		})
+
+		return rowsLoaded, err
+	})
+}
+
+func (orm *instrumentedORM) Iterate(ctx context.Context, params QueryParams, iterateFunc IterateFunc) error {
+	return orm.iterate(ctx, OperationIterate, params, iterateFunc, orm.orm.Iterate)
+}
+
+func (orm *instrumentedORM) IterateWithXLock(ctx context.Context, params QueryParams, iterateFunc IterateFunc) error {
+	return orm.iterate(ctx, OperationIterateWithXLock, params, iterateFunc, orm.orm.IterateWithXLock)
+}
+
+func (orm *instrumentedORM) QueryPage(ctx context.Context, params QueryParams, cursor Cursor) (nextCursor Cursor, err error) {
+	err = orm.observe(ctx, OperationQueryPage, params.TableName, func(ctx context.Context) (int64, error) {
+		if nextCursor, err = orm.orm.QueryPage(ctx, params, cursor); err != nil {
+			return 0, err
+		}
+
+		return getEntryListLength(params.EntryList), nil
+	})
+
+	return nextCursor, err
+}
+
+func (orm *instrumentedORM) Count(ctx context.Context, tableName string, expression goqu.Expression) (count int64, err error) {
+	err = orm.observe(ctx, OperationCount, tableName, func(ctx context.Context) (int64, error) {
+		count, err = orm.orm.Count(ctx, tableName, expression)
+
+		return 0, err
+	})
+
+	return count, err
+}
+
+func (orm *instrumentedORM) aggregate(
+	ctx context.Context,
+	operationName string,
+	tableName string,
+	column string,
+	expression goqu.Expression,
+	result interface{},
+	aggregate func(context.Context, string, string, goqu.Expression, interface{}) error,
+) error {
+	return orm.observe(ctx, operationName, tableName, func(ctx context.Context) (int64, error) {
+		return 0, aggregate(ctx, tableName, column, expression, result)
+	})
+}
+
+func (orm *instrumentedORM) Sum(ctx context.Context, tableName, column string, expression goqu.Expression, result interface{}) error {
+	return orm.aggregate(ctx, OperationSum, tableName, column, expression, result, orm.orm.Sum)
+}
+
+func (orm *instrumentedORM) Min(ctx context.Context, tableName, column string, expression goqu.Expression, result interface{}) error {
+	return orm.aggregate(ctx, OperationMin, tableName, column, expression, result, orm.orm.Min)
+}
+
+func (orm *instrumentedORM) Max(ctx context.Context, tableName, column string, expression goqu.Expression, result interface{}) error {
+	return orm.aggregate(ctx, OperationMax, tableName, column, expression, result, orm.orm.Max)
+}
+
+func (orm *instrumentedORM) Avg(ctx context.Context, tableName, column string, expression goqu.Expression, result interface{}) error {
+	return orm.aggregate(ctx, OperationAvg, tableName, column, expression, result, orm.orm.Avg)
+}
+
+func (orm *instrumentedORM) Exists(ctx context.Context, tableName string, expression goqu.Expression) (found bool, err error) {
+	err = orm.observe(ctx, OperationExists, tableName, func(ctx context.Context) (int64, error) {
+		found, err = orm.orm.Exists(ctx, tableName, expression)
+
+		return 0, err
+	})
+
+	return found, err
+}
+
+func (orm *instrumentedORM) VerifySchema(ctx context.Context, models ...interface{}) error {
+	return orm.observe(ctx, OperationVerifySchema, "", func(ctx context.Context) (int64, error) {
+		return 0, orm.orm.VerifySchema(ctx, models...)
+	})
+}
+
+func (orm *instrumentedORM) Update(ctx context.Context, entry interface{}) error {
+	return orm.write(ctx, OperationUpdate, entry, orm.orm.Update)
+}
+
+func (orm *instrumentedORM) UpdateColumns(ctx context.Context, entry interface{}, columns ...string) error {
+	return orm.write(ctx, OperationUpdateColumns, entry, func(ctx context.Context, entry interface{}) error {
+		return orm.orm.UpdateColumns(ctx, entry, columns...)
+	})
+}
+
+func (orm *instrumentedORM) UpdateWhere(
+	ctx context.Context,
+	tableName string,
+	expression goqu.Expression,
+	record goqu.Record,
+) (rowsAffected int64, err error) {
+	err = orm.observe(ctx, OperationUpdateWhere, tableName, func(ctx context.Context) (int64, error) {
+		rowsAffected, err = orm.orm.UpdateWhere(ctx, tableName, expression, record)
+
+		return rowsAffected, err
+	})
+
+	return rowsAffected, err
+}
+
+func (orm *instrumentedORM) UpdateWhereWithLimit(
+	ctx context.Context,
+	tableName string,
+	expression goqu.Expression,
+	record goqu.Record,
+	limit uint32,
+) (rowsAffected int64, err error) {
+	err = orm.observe(ctx, OperationUpdateWhereWithLimit, tableName, func(ctx context.Context) (int64, error) {
+		rowsAffected, err = orm.orm.UpdateWhereWithLimit(ctx, tableName, expression, record, limit)
+
+		return rowsAffected, err
+	})
+
+	return rowsAffected, err
+}
+
+func (orm *instrumentedORM) CreateOrUpdate(ctx context.Context, entry interface{}) error {
+	return orm.write(ctx, OperationCreateOrUpdate, entry, orm.orm.CreateOrUpdate)
+}
+
+func (orm *instrumentedORM) Delete(ctx context.Context, entry interface{}) error {
+	return orm.write(ctx, OperationDelete, entry, orm.orm.Delete)
+}
+
+func (orm *instrumentedORM) HardDelete(ctx context.Context, entry interface{}) error {
+	return orm.write(ctx, OperationHardDelete, entry, orm.orm.HardDelete)
+}
+
+func (orm *instrumentedORM) DeleteWhere(ctx context.Context, tableName string, expression exp.Expression) (rowsAffected int64, err error) {
+	err = orm.observe(ctx, OperationDeleteWhere, tableName, func(ctx context.Context) (int64, error) {
+		rowsAffected, err = orm.orm.DeleteWhere(ctx, tableName, expression)
+
+		return rowsAffected, err
+	})
+
+	return rowsAffected, err
+}
+
+func (orm *instrumentedORM) DeleteWhereWithLimit(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	limit uint32,
+) (rowsAffected int64, err error) {
+	err = orm.observe(ctx, OperationDeleteWhereWithLimit, tableName, func(ctx context.Context) (int64, error) {
+		rowsAffected, err = orm.orm.DeleteWhereWithLimit(ctx, tableName, expression, limit)
+
+		return rowsAffected, err
+	})
+
+	return rowsAffected, err
+}
+
+func (orm *instrumentedORM) GetDBWrapper() DBWrapper {
+	return orm.orm.GetDBWrapper()
+}
+
+func (orm *instrumentedORM) Ping(ctx context.Context) error {
+	return orm.orm.Ping(ctx)
+}
+
+func (orm *instrumentedORM) HealthCheck(ctx context.Context) (HealthStatus, error) {
+	return orm.orm.HealthCheck(ctx)
+}
+
+func (orm *instrumentedORM) Close() error {
+	return orm.orm.Close()
+}
+
+func (orm *instrumentedORM) Unscoped() ORM {
+	return orm.wrap(orm.orm.Unscoped())
+}
+
+func (orm *instrumentedORM) Preload(relationFields ...string) ORM {
+	return orm.wrap(orm.orm.Preload(relationFields...))
+}
+
+func (orm *instrumentedORM) WithTx(executeFunc func(ORM) error) error {
+	return orm.WithTxContext(context.Background(), nil, func(_ context.Context, txORM ORM) error {
+		return executeFunc(txORM)
+	})
+}
+
+func (orm *instrumentedORM) WithTxContext(
+	ctx context.Context,
+	opts *sql.TxOptions,
+	executeFunc func(context.Context, ORM) error,
+) error {
+	return orm.observe(ctx, OperationWithTx, "", func(ctx context.Context) (int64, error) {
+		return 0, orm.orm.WithTxContext(ctx, opts, func(txCtx context.Context, txORM ORM) error {
+			return executeFunc(txCtx, orm.wrap(txORM))
+		})
+	})
+}
@@ -0,0 +1,119 @@
+package miniorm
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingInstrumentationContextKey struct{}
+
+// recordingInstrumentation records the completed events, and the order of its calls in Calls
+type recordingInstrumentation struct {
+	Name   string
+	Calls  *[]string
+	lock   sync.Mutex
+	events []QueryEvent
+}
+
+func (instrumentation *recordingInstrumentation) OnQueryStart(ctx context.Context, _ *QueryEvent) context.Context {
+	if instrumentation.Calls != nil {
+		*instrumentation.Calls = append(*instrumentation.Calls, "start "+instrumentation.Name)
+	}
+
+	return context.WithValue(ctx, recordingInstrumentationContextKey{}, instrumentation.Name)
+}
+
+func (instrumentation *recordingInstrumentation) OnQueryEnd(ctx context.Context, event *QueryEvent) {
+	if instrumentation.Calls != nil {
+		*instrumentation.Calls = append(
+			*instrumentation.Calls,
+			"end "+instrumentation.Name+" "+ctx.Value(recordingInstrumentationContextKey{}).(string),
+		)
+	}
+
+	instrumentation.lock.Lock()
+	defer instrumentation.lock.Unlock()
+
+	instrumentation.events = append(instrumentation.events, *event)
+}
+
+func (instrumentation *recordingInstrumentation) getEvents() []QueryEvent {
+	instrumentation.lock.Lock()
+	defer instrumentation.lock.Unlock()
+
+	return append([]QueryEvent{}, instrumentation.events...)
+}
+
+func TestMultiInstrumentation(t *testing.T) {
+	calls := make([]string, 0)
+	instrumentation := MultiInstrumentation(
+		&recordingInstrumentation{Name: "first", Calls: &calls},
+		&recordingInstrumentation{Name: "second", Calls: &calls},
+	)
+
+	event := &QueryEvent{Operation: OperationGet}
+	ctx := instrumentation.OnQueryStart(context.Background(), event)
+	instrumentation.OnQueryEnd(ctx, event)
+
+	assert.Equal(t, []string{"start first", "start second", "end second second", "end first second"}, calls)
+}
+
+func TestNewInstrumentedORM(t *testing.T) {
+	orm := &SQLite3ORM{}
+	assert.Same(t, orm, newInstrumentedORM(orm, DatabaseConfig{}))
+
+	instrumentedORM, ok := newInstrumentedORM(orm, DatabaseConfig{Instrumentation: &recordingInstrumentation{}}).(*instrumentedORM)
+	assert.True(t, ok)
+	assert.Same(t, orm, instrumentedORM.orm)
+}
+
+func TestInstrumentedORMRowsAffected(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	instrumentation := &recordingInstrumentation{}
+	mockORM := NewMockORM(mockController)
+	orm := newInstrumentedORM(mockORM, DatabaseConfig{Instrumentation: instrumentation})
+
+	ctx := gomock.Any()
+	entry := &getIDEntry{ID: 1}
+	entries := []getIDEntry{{}, {}}
+
+	mockORM.EXPECT().Create(ctx, entry).Return(nil).Times(1)
+	mockORM.EXPECT().CreateMany(ctx, &entries).Return(nil).Times(1)
+	assert.Nil(t, orm.Create(context.Background(), entry))
+	assert.Nil(t, orm.CreateMany(context.Background(), &entries))
+
+	// A failed write affects no rows
+	mockORM.EXPECT().Create(ctx, entry).Return(ErrNilEntry).Times(1)
+	mockORM.EXPECT().CreateMany(ctx, &entries).Return(ErrNilEntry).Times(1)
+	mockORM.EXPECT().Update(ctx, entry).Return(ErrStaleEntry).Times(1)
+	mockORM.EXPECT().UpdateColumns(ctx, entry, "string_col").Return(ErrStaleEntry).Times(1)
+	mockORM.EXPECT().CreateOrUpdate(ctx, entry).Return(ErrStaleEntry).Times(1)
+	mockORM.EXPECT().Delete(ctx, entry).Return(ErrStaleEntry).Times(1)
+	mockORM.EXPECT().HardDelete(ctx, entry).Return(ErrNotFound).Times(1)
+	assert.ErrorIs(t, orm.Create(context.Background(), entry), ErrNilEntry)
+	assert.ErrorIs(t, orm.CreateMany(context.Background(), &entries), ErrNilEntry)
+	assert.ErrorIs(t, orm.Update(context.Background(), entry), ErrStaleEntry)
+	assert.ErrorIs(t, orm.UpdateColumns(context.Background(), entry, "string_col"), ErrStaleEntry)
+	assert.ErrorIs(t, orm.CreateOrUpdate(context.Background(), entry), ErrStaleEntry)
+	assert.ErrorIs(t, orm.Delete(context.Background(), entry), ErrStaleEntry)
+	assert.ErrorIs(t, orm.HardDelete(context.Background(), entry), ErrNotFound)
+
+	events := instrumentation.getEvents()
+	if !assert.Len(t, events, 9) {
+		return
+	}
+
+	assert.Equal(t, int64(1), events[0].RowsAffected)
+	assert.Equal(t, int64(2), events[1].RowsAffected)
+
+	for _, event := range events[2:] {
+		assert.NotNil(t, event.Err, event.Operation)
+		assert.Equal(t, int64(0), event.RowsAffected, event.Operation)
+	}
+}
@@ -0,0 +1,83 @@
+package miniorm
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exec"
+)
+
+// IterateFunc is called by Iterate() and IterateWithXLock() for each row, with a new entry of the type of the elements
+// of QueryParams.EntryList. Iterating stops at the first error returned, which is then returned by Iterate().
+type IterateFunc func(entry interface{}) error
+
+// iterate runs selectDataset on db, optionally rewriting its SQL statement with wrapSQLStatement, and scans the rows one
+// at a time into new entries of the type of the elements of params.EntryList, which are passed to iterateFunc after
+// running their AfterLoad() hooks
+func iterate(
+	ctx context.Context,
+	orm ORM,
+	db DBWrapper,
+	entryInfoProvider *entryInfoProvider,
+	params QueryParams,
+	selectDataset *goqu.SelectDataset,
+	wrapSQLStatement func(sqlStatement string) string,
+	iterateFunc IterateFunc,
+) error {
+	entryType, ok := entryInfoProvider.GetEntryListEntryType(params.EntryList)
+	if !ok {
+		return ErrSliceExpected
+	}
+
+	if selectDataset.GetClauses().IsDefaultSelect() {
+		selectDataset = selectDataset.Select(params.EntryList)
+	}
+
+	sqlStatement, sqlParams, err := selectDataset.ToSQL()
+	if err != nil {
+		return err
+	}
+
+	if wrapSQLStatement != nil {
+		sqlStatement = wrapSQLStatement(sqlStatement)
+	}
+
+	rows, err := db.QueryContext(ctx, sqlStatement, sqlParams...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	isPointer := entryType.Kind() == reflect.Ptr
+	if isPointer {
+		entryType = entryType.Elem()
+	}
+
+	scanner := exec.NewScanner(rows)
+	for scanner.Next() {
+		entryValue := reflect.New(entryType)
+		if err := scanner.ScanStruct(entryValue.Interface()); err != nil {
+			return err
+		}
+
+		if err := runAfterLoadHooks(ctx, orm, entryValue.Interface()); err != nil {
+			return err
+		}
+
+		if !isPointer {
+			entryValue = entryValue.Elem()
+		}
+
+		if err := iterateFunc(entryValue.Interface()); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
@@ -0,0 +1,86 @@
+package miniorm
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+)
+
+var (
+	ErrGeneratedKeyNotSupported = errors.New("expected generated key to be an integer, as it is read from LastInsertId()")
+)
+
+// idSetterScanner scans a generated key into an IDSetter, so that IDSetter entries can be handled like KeySetter entries
+type idSetterScanner struct {
+	idSetter IDSetter
+}
+
+func (scanner *idSetterScanner) Scan(src interface{}) error {
+	var id sql.NullInt64
+	if err := id.Scan(src); err != nil {
+		return err
+	}
+
+	scanner.idSetter.SetID(id.Int64)
+
+	return nil
+}
+
+// getIntegerKeySetter returns a function setting an integer key, e.g. from LastInsertId(), into keyDestination.
+// ErrGeneratedKeyNotSupported is returned if keyDestination cannot hold an integer key.
+func getIntegerKeySetter(keyDestination interface{}) (func(key int64) error, error) {
+	switch destination := keyDestination.(type) {
+	case *idSetterScanner:
+		return func(key int64) error {
+			destination.idSetter.SetID(key)
+			return nil
+		}, nil
+	case sql.Scanner:
+		return func(key int64) error {
+			return destination.Scan(key)
+		}, nil
+	}
+
+	destinationValue := reflect.ValueOf(keyDestination)
+	if destinationValue.Kind() != reflect.Ptr || destinationValue.IsNil() {
+		return nil, ErrGeneratedKeyNotSupported
+	}
+
+	keyValue := destinationValue.Elem()
+
+	switch {
+	case isIntKind(keyValue.Kind()):
+		return func(key int64) error {
+			keyValue.SetInt(key)
+			return nil
+		}, nil
+	case isUintKind(keyValue.Kind()):
+		return func(key int64) error {
+			keyValue.SetUint(uint64(key))
+			return nil
+		}, nil
+	default:
+		return nil, ErrGeneratedKeyNotSupported
+	}
+}
+
+// setIntegerKeys sets integer keys, starting at firstKey and spaced by increment, into the key destinations of entryList
+func setIntegerKeys(entryInfoProvider *entryInfoProvider, entryList []interface{}, firstKey int64, increment int64) error {
+	for i, entry := range entryList {
+		_, keyDestination, ok := entryInfoProvider.GetKeyDestination(entry)
+		if !ok {
+			continue
+		}
+
+		setKey, err := getIntegerKeySetter(keyDestination)
+		if err != nil {
+			return err
+		}
+
+		if err := setKey(firstKey + int64(i)*increment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
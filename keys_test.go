@@ -0,0 +1,64 @@
+package miniorm
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetIntegerKeySetter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		intKey    int64
+		uintKey   uint32
+		nullKey   sql.NullInt64
+		stringKey string
+	)
+
+	idEntry := &getIDEntry{}
+	keyDestinationList := []interface{}{&intKey, &uintKey, &nullKey, &idSetterScanner{idSetter: idEntry}}
+
+	for _, keyDestination := range keyDestinationList {
+		setKey, err := getIntegerKeySetter(keyDestination)
+		assert.Nil(t, err)
+		assert.Nil(t, setKey(5))
+	}
+
+	assert.Equal(t, int64(5), intKey)
+	assert.Equal(t, uint32(5), uintKey)
+	assert.Equal(t, sql.NullInt64{Int64: 5, Valid: true}, nullKey)
+	assert.Equal(t, int64(5), idEntry.ID)
+
+	testCaseList := []interface{}{
+		nil,
+		intKey,
+		&stringKey,
+		(*int64)(nil),
+	}
+
+	for _, testCase := range testCaseList {
+		setKey, err := getIntegerKeySetter(testCase)
+		assert.Nil(t, setKey)
+		assert.ErrorIs(t, err, ErrGeneratedKeyNotSupported)
+	}
+}
+
+func TestSetIntegerKeys(t *testing.T) {
+	t.Parallel()
+
+	entryList := []interface{}{&taggedIDEntry{}, &getIDEntry{}, &taggedUniqueEntry{}}
+	err := setIntegerKeys(newEntryInfoProvider(), entryList, 3, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), entryList[0].(*taggedIDEntry).ID)
+	assert.Equal(t, int64(4), entryList[1].(*getIDEntry).ID)
+
+	err = setIntegerKeys(newEntryInfoProvider(), entryList, 3, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), entryList[0].(*taggedIDEntry).ID)
+	assert.Equal(t, int64(5), entryList[1].(*getIDEntry).ID)
+
+	err = setIntegerKeys(newEntryInfoProvider(), []interface{}{&getKeyEntry{}}, 3, 1)
+	assert.ErrorIs(t, err, ErrGeneratedKeyNotSupported)
+}
@@ -0,0 +1,254 @@
+package miniorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// Keys of the fields of the statements logged to StructuredLogger
+const (
+	LogFieldDriver   = "driver"
+	LogFieldSQL      = "sql"
+	LogFieldArgs     = "args"
+	LogFieldDuration = "duration"
+	LogFieldError    = "error"
+)
+
+// StructuredLogger receives the failed, slow or debug statements executed by the ORM, with their arguments redacted
+type StructuredLogger interface {
+	Log(ctx context.Context, level LogLevel, message string, fields ...LogField)
+}
+
+type LogField struct {
+	Key   string
+	Value interface{}
+}
+
+func (level LogLevel) String() string {
+	switch level {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(level))
+	}
+}
+
+// NewPrintfStructuredLogger returns a StructuredLogger writing a line per entry to logger, e.g. a *log.Logger
+func NewPrintfStructuredLogger(logger Logger) StructuredLogger {
+	return &printfStructuredLogger{logger: logger}
+}
+
+type printfStructuredLogger struct {
+	logger Logger
+}
+
+func (logger *printfStructuredLogger) Log(_ context.Context, level LogLevel, message string, fields ...LogField) {
+	line := &strings.Builder{}
+	line.WriteString(level.String())
+	line.WriteString(" ")
+	line.WriteString(message)
+
+	for _, field := range fields {
+		if value, ok := field.Value.(string); ok {
+			fmt.Fprintf(line, " %s=%q", field.Key, value)
+		} else {
+			fmt.Fprintf(line, " %s=%v", field.Key, field.Value)
+		}
+	}
+
+	logger.logger.Printf("%s", line.String())
+}
+
+// statementLogger logs the statements executed through the loggingSQLDatabase and loggingSQLTx wrappers, to the
+// StructuredLogger of the configuration or else to its Logger
+type statementLogger struct {
+	logger             StructuredLogger
+	printfLogger       Logger
+	driverType         DriverType
+	slowQueryThreshold time.Duration
+}
+
+func newStatementLogger(databaseConfig DatabaseConfig) *statementLogger {
+	return &statementLogger{
+		logger:             databaseConfig.StructuredLogger,
+		printfLogger:       databaseConfig.Logger,
+		driverType:         databaseConfig.Driver,
+		slowQueryThreshold: time.Duration(databaseConfig.SlowQueryThresholdInMillisecond) * time.Millisecond,
+	}
+}
+
+// log logs a statement executed by operation, i.e. EXEC or QUERY, with the values bound to its redacted columns by ctx
+// replaced in args
+func (logger *statementLogger) log(
+	ctx context.Context,
+	operation string,
+	isInTransaction bool,
+	query string,
+	args []interface{},
+	duration time.Duration,
+	err error,
+) {
+	if logger.logger == nil {
+		logger.printf(operation, isInTransaction, query, redactArgs(ctx, args))
+		return
+	}
+
+	var level LogLevel
+	var message string
+
+	switch {
+	// sql.ErrNoRows is how an entry not found is reported, not a failure of the statement
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		level, message = LogLevelError, "statement failed"
+	case logger.slowQueryThreshold > 0 && duration >= logger.slowQueryThreshold:
+		level, message = LogLevelWarn, "slow statement"
+	case logger.slowQueryThreshold > 0:
+		return
+	default:
+		level, message = LogLevelDebug, "statement executed"
+	}
+
+	fields := []LogField{
+		{Key: LogFieldDriver, Value: string(logger.driverType)},
+		{Key: LogFieldSQL, Value: query},
+		{Key: LogFieldArgs, Value: redactArgs(ctx, args)},
+		{Key: LogFieldDuration, Value: duration},
+	}
+
+	if level == LogLevelError {
+		fields = append(fields, LogField{Key: LogFieldError, Value: err})
+	}
+
+	logger.logger.Log(ctx, level, message, fields...)
+}
+
+// printf logs a statement to the Logger in the format of goqu
+func (logger *statementLogger) printf(operation string, isInTransaction bool, query string, args []interface{}) {
+	prefix, suffix := "[goqu]", ""
+	if isInTransaction {
+		prefix, suffix = "[goqu - transaction]", " "
+	}
+
+	if len(args) != 0 {
+		logger.printfLogger.Printf(prefix+" %s [query:=`%s` args:=%+v]"+suffix, operation, query, args)
+	} else {
+		logger.printfLogger.Printf(prefix+" %s [query:=`%s`]"+suffix, operation, query)
+	}
+}
+
+// loggingSQLDatabase logs the statements executed outside of transactions
+type loggingSQLDatabase struct {
+	goqu.SQLDatabase
+	statementLogger *statementLogger
+}
+
+func (db *loggingSQLDatabase) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	startTime := time.Now()
+	result, err := db.SQLDatabase.ExecContext(ctx, query, args...)
+	db.statementLogger.log(ctx, "EXEC", false, query, args, time.Since(startTime), err)
+
+	return result, err
+}
+
+func (db *loggingSQLDatabase) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	startTime := time.Now()
+	rows, err := db.SQLDatabase.QueryContext(ctx, query, args...)
+	db.statementLogger.log(ctx, "QUERY", false, query, args, time.Since(startTime), err)
+
+	return rows, err
+}
+
+// loggingSQLTx logs the statements executed in a transaction
+type loggingSQLTx struct {
+	goqu.SQLTx
+	statementLogger *statementLogger
+}
+
+func (tx *loggingSQLTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	startTime := time.Now()
+	result, err := tx.SQLTx.ExecContext(ctx, query, args...)
+	tx.statementLogger.log(ctx, "EXEC", true, query, args, time.Since(startTime), err)
+
+	return result, err
+}
+
+func (tx *loggingSQLTx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	startTime := time.Now()
+	rows, err := tx.SQLTx.QueryContext(ctx, query, args...)
+	tx.statementLogger.log(ctx, "QUERY", true, query, args, time.Since(startTime), err)
+
+	return rows, err
+}
+
+// goquOperationLogger forwards the lines that goqu logs to the Logger of the configuration, except for the EXEC and
+// QUERY statements, which are logged by loggingSQLDatabase and loggingSQLTx with the context of their bound values
+type goquOperationLogger struct {
+	logger Logger
+}
+
+// newGoquLogger returns the Logger of databaseConfig for goqu, unless it has a StructuredLogger
+func newGoquLogger(databaseConfig DatabaseConfig) goqu.Logger {
+	if databaseConfig.Logger == nil || databaseConfig.StructuredLogger != nil {
+		return nil
+	}
+
+	return &goquOperationLogger{logger: databaseConfig.Logger}
+}
+
+// Printf forwards v, which goqu formats as the operation, the statement and its arguments, if any
+func (logger *goquOperationLogger) Printf(format string, v ...interface{}) {
+	if len(v) >= 2 && (v[0] == "EXEC" || v[0] == "QUERY") {
+		return
+	}
+
+	logger.logger.Printf(format, v...)
+}
+
+// goquTraceLogger forwards the lines that goqu logs to the Trace() of db, which logs them with the logger of db
+type goquTraceLogger struct {
+	db *goqu.Database
+}
+
+// Printf forwards v, which goqu formats as the operation, the statement and its arguments, if any
+func (logger *goquTraceLogger) Printf(_ string, v ...interface{}) {
+	var (
+		operation string
+		query     string
+		args      []interface{}
+	)
+
+	if len(v) > 0 {
+		operation, _ = v[0].(string)
+	}
+
+	if len(v) > 1 {
+		query, _ = v[1].(string)
+	}
+
+	if len(v) > 2 {
+		args, _ = v[2].([]interface{})
+	}
+
+	logger.db.Trace(operation, query, args...)
+}
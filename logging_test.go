@@ -0,0 +1,179 @@
+package miniorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedLogEntry struct {
+	Level   LogLevel
+	Message string
+	Fields  map[string]interface{}
+}
+
+type recordingStructuredLogger struct {
+	lock    sync.Mutex
+	entries []recordedLogEntry
+}
+
+func (logger *recordingStructuredLogger) Log(_ context.Context, level LogLevel, message string, fields ...LogField) {
+	entry := recordedLogEntry{Level: level, Message: message, Fields: make(map[string]interface{}, len(fields))}
+	for _, field := range fields {
+		entry.Fields[field.Key] = field.Value
+	}
+
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	logger.entries = append(logger.entries, entry)
+}
+
+func (logger *recordingStructuredLogger) getEntries() []recordedLogEntry {
+	logger.lock.Lock()
+	defer logger.lock.Unlock()
+
+	return append([]recordedLogEntry{}, logger.entries...)
+}
+
+type printfLogger struct {
+	lines []string
+}
+
+func (logger *printfLogger) Printf(format string, v ...interface{}) {
+	logger.lines = append(logger.lines, fmt.Sprintf(format, v...))
+}
+
+func TestStatementLoggerLog(t *testing.T) {
+	logger := &recordingStructuredLogger{}
+	databaseConfig := DatabaseConfig{
+		Driver:           DriverTypeMySQL,
+		StructuredLogger: logger,
+		RedactedColumns:  []string{"Password"},
+	}
+	statementLogger := newStatementLogger(databaseConfig)
+	errFailed := errors.New("failed")
+
+	ctx := newLogRedactor(databaseConfig).bindRecord(context.Background(), "users", goqu.Record{"password": "secret"})
+
+	statementLogger.log(
+		ctx,
+		"EXEC",
+		false,
+		"UPDATE `users` SET `password`=? WHERE (`id` = ?)",
+		[]interface{}{"secret", 1},
+		time.Second,
+		nil,
+	)
+	statementLogger.log(context.Background(), "QUERY", false, "SELECT 1", nil, time.Millisecond, sql.ErrNoRows)
+	statementLogger.log(context.Background(), "QUERY", true, "SELECT 1", nil, time.Millisecond, errFailed)
+
+	assert.Equal(t, []recordedLogEntry{
+		{
+			Level:   LogLevelDebug,
+			Message: "statement executed",
+			Fields: map[string]interface{}{
+				LogFieldDriver:   "mysql",
+				LogFieldSQL:      "UPDATE `users` SET `password`=? WHERE (`id` = ?)",
+				LogFieldArgs:     []interface{}{RedactedValue, 1},
+				LogFieldDuration: time.Second,
+			},
+		},
+		{
+			Level:   LogLevelDebug,
+			Message: "statement executed",
+			Fields: map[string]interface{}{
+				LogFieldDriver:   "mysql",
+				LogFieldSQL:      "SELECT 1",
+				LogFieldArgs:     []interface{}(nil),
+				LogFieldDuration: time.Millisecond,
+			},
+		},
+		{
+			Level:   LogLevelError,
+			Message: "statement failed",
+			Fields: map[string]interface{}{
+				LogFieldDriver:   "mysql",
+				LogFieldSQL:      "SELECT 1",
+				LogFieldArgs:     []interface{}(nil),
+				LogFieldDuration: time.Millisecond,
+				LogFieldError:    errFailed,
+			},
+		},
+	}, logger.getEntries())
+}
+
+func TestStatementLoggerLogSlowQueryThreshold(t *testing.T) {
+	logger := &recordingStructuredLogger{}
+	statementLogger := newStatementLogger(DatabaseConfig{
+		Driver:                          DriverTypeSQLite3,
+		StructuredLogger:                logger,
+		SlowQueryThresholdInMillisecond: 100,
+	})
+
+	statementLogger.log(context.Background(), "QUERY", false, "SELECT 1", nil, 99*time.Millisecond, nil)
+	statementLogger.log(context.Background(), "QUERY", false, "SELECT 2", nil, 100*time.Millisecond, nil)
+	statementLogger.log(context.Background(), "QUERY", false, "SELECT 3", nil, time.Millisecond, errors.New("failed"))
+
+	entries := logger.getEntries()
+	if !assert.Len(t, entries, 2) {
+		return
+	}
+
+	assert.Equal(t, LogLevelWarn, entries[0].Level)
+	assert.Equal(t, "slow statement", entries[0].Message)
+	assert.Equal(t, "SELECT 2", entries[0].Fields[LogFieldSQL])
+	assert.Equal(t, LogLevelError, entries[1].Level)
+	assert.Equal(t, "SELECT 3", entries[1].Fields[LogFieldSQL])
+}
+
+func TestPrintfStructuredLogger(t *testing.T) {
+	logger := &printfLogger{}
+	NewPrintfStructuredLogger(logger).Log(
+		context.Background(),
+		LogLevelWarn,
+		"slow statement",
+		LogField{Key: LogFieldSQL, Value: "SELECT 1"},
+		LogField{Key: LogFieldDuration, Value: time.Second},
+	)
+
+	assert.Equal(t, []string{`WARN slow statement sql="SELECT 1" duration=1s`}, logger.lines)
+	assert.Equal(t, "LogLevel(7)", LogLevel(7).String())
+}
+
+func TestStatementLoggerPrintf(t *testing.T) {
+	logger := &printfLogger{}
+	databaseConfig := DatabaseConfig{Logger: logger, RedactedColumns: []string{"password"}}
+	statementLogger := newStatementLogger(databaseConfig)
+
+	ctx := newLogRedactor(databaseConfig).bindRecord(context.Background(), "users", goqu.Record{"password": "secret"})
+
+	statementLogger.log(ctx, "EXEC", false, "UPDATE `users` SET `password`=?", []interface{}{"secret"}, time.Second, nil)
+	statementLogger.log(ctx, "QUERY", true, "SELECT 1", nil, time.Second, errors.New("failed"))
+
+	assert.Equal(t, []string{
+		"[goqu] EXEC [query:=`UPDATE `users` SET `password`=?` args:=[[REDACTED]]]",
+		"[goqu - transaction] QUERY [query:=`SELECT 1`] ",
+	}, logger.lines)
+}
+
+func TestNewGoquLogger(t *testing.T) {
+	assert.Nil(t, newGoquLogger(DatabaseConfig{}))
+	assert.Nil(t, newGoquLogger(DatabaseConfig{Logger: &printfLogger{}, StructuredLogger: &recordingStructuredLogger{}}))
+
+	// The statements are logged by the logging wrappers, which know the values bound to the redacted columns
+	logger := &printfLogger{}
+	goquLogger := newGoquLogger(DatabaseConfig{Logger: logger, RedactedColumns: []string{"password"}})
+	goquLogger.Printf("[goqu] %s [query:=`%s` args:=%+v]", "EXEC", "UPDATE `users` SET `password`=?", []interface{}{"secret"})
+	goquLogger.Printf("[goqu - transaction] %s [query:=`%s`] ", "QUERY", "SELECT 1")
+	goquLogger.Printf("[goqu - transaction] %s", "COMMIT")
+
+	assert.Equal(t, []string{"[goqu - transaction] COMMIT"}, logger.lines)
+}
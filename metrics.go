@@ -0,0 +1,182 @@
+package miniorm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	MetricsStatusOK       = "ok"
+	MetricsStatusNotFound = "not_found"
+	MetricsStatusError    = "error"
+
+	metricsDurationName     = "miniorm_operation_duration_seconds"
+	metricsRowsAffectedName = "miniorm_operation_rows_affected_total"
+)
+
+var (
+	// DefaultMetricsBuckets are the duration histogram buckets in seconds of NewMetricsInstrumentation()
+	DefaultMetricsBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+	metricsLabelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+)
+
+type metricsLabels struct {
+	operation string
+	table     string
+	status    string
+}
+
+type metricsSeries struct {
+	bucketCounts []uint64
+	count        uint64
+	durationSum  float64
+	rowsAffected int64
+}
+
+// MetricsInstrumentation is an Instrumentation exposing the operations in the Prometheus text format
+type MetricsInstrumentation struct {
+	buckets []float64
+	lock    sync.Mutex
+	series  map[metricsLabels]*metricsSeries
+}
+
+// NewMetricsInstrumentation returns a MetricsInstrumentation with buckets, or DefaultMetricsBuckets if none are given
+func NewMetricsInstrumentation(buckets ...float64) *MetricsInstrumentation {
+	if len(buckets) == 0 {
+		buckets = DefaultMetricsBuckets
+	}
+
+	sortedBuckets := append([]float64{}, buckets...)
+	sort.Float64s(sortedBuckets)
+
+	return &MetricsInstrumentation{
+		buckets: sortedBuckets,
+		series:  make(map[metricsLabels]*metricsSeries),
+	}
+}
+
+func (instrumentation *MetricsInstrumentation) OnQueryStart(ctx context.Context, _ *QueryEvent) context.Context {
+	return ctx
+}
+
+func (instrumentation *MetricsInstrumentation) OnQueryEnd(_ context.Context, event *QueryEvent) {
+	labels := metricsLabels{operation: event.Operation, table: event.TableName, status: MetricsStatusOK}
+
+	switch {
+	case errors.Is(event.Err, ErrNotFound):
+		labels.status = MetricsStatusNotFound
+	case event.Err != nil:
+		labels.status = MetricsStatusError
+	}
+
+	duration := event.Duration.Seconds()
+
+	instrumentation.lock.Lock()
+	defer instrumentation.lock.Unlock()
+
+	series, ok := instrumentation.series[labels]
+	if !ok {
+		series = &metricsSeries{bucketCounts: make([]uint64, len(instrumentation.buckets))}
+		instrumentation.series[labels] = series
+	}
+
+	for i, bucket := range instrumentation.buckets {
+		if duration <= bucket {
+			series.bucketCounts[i]++
+		}
+	}
+
+	series.count++
+	series.durationSum += duration
+	series.rowsAffected += event.RowsAffected
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (instrumentation *MetricsInstrumentation) WriteTo(w io.Writer) (int64, error) {
+	return instrumentation.format().WriteTo(w)
+}
+
+func (instrumentation *MetricsInstrumentation) format() *bytes.Buffer {
+	instrumentation.lock.Lock()
+	defer instrumentation.lock.Unlock()
+
+	labelsList := make([]metricsLabels, 0, len(instrumentation.series))
+	for labels := range instrumentation.series {
+		labelsList = append(labelsList, labels)
+	}
+
+	sort.Slice(labelsList, func(i, j int) bool {
+		if labelsList[i].operation != labelsList[j].operation {
+			return labelsList[i].operation < labelsList[j].operation
+		}
+
+		if labelsList[i].table != labelsList[j].table {
+			return labelsList[i].table < labelsList[j].table
+		}
+
+		return labelsList[i].status < labelsList[j].status
+	})
+
+	buffer := &bytes.Buffer{}
+
+	fmt.Fprintf(buffer, "# HELP %s Duration of the operations of go-miniorm.\n", metricsDurationName)
+	fmt.Fprintf(buffer, "# TYPE %s histogram\n", metricsDurationName)
+
+	for _, labels := range labelsList {
+		series := instrumentation.series[labels]
+		formattedLabels := labels.format()
+
+		for i, bucket := range instrumentation.buckets {
+			fmt.Fprintf(
+				buffer,
+				"%s_bucket{%s,le=\"%s\"} %d\n",
+				metricsDurationName,
+				formattedLabels,
+				strconv.FormatFloat(bucket, 'g', -1, 64),
+				series.bucketCounts[i],
+			)
+		}
+
+		fmt.Fprintf(buffer, "%s_bucket{%s,le=\"+Inf\"} %d\n", metricsDurationName, formattedLabels, series.count)
+		fmt.Fprintf(buffer, "%s_sum{%s} %s\n", metricsDurationName, formattedLabels, strconv.FormatFloat(series.durationSum, 'g', -1, 64))
+		fmt.Fprintf(buffer, "%s_count{%s} %d\n", metricsDurationName, formattedLabels, series.count)
+	}
+
+	fmt.Fprintf(buffer, "# HELP %s Rows written or loaded by the operations of go-miniorm.\n", metricsRowsAffectedName)
+	fmt.Fprintf(buffer, "# TYPE %s counter\n", metricsRowsAffectedName)
+
+	for _, labels := range labelsList {
+		fmt.Fprintf(buffer, "%s{%s} %d\n", metricsRowsAffectedName, labels.format(), instrumentation.series[labels].rowsAffected)
+	}
+
+	return buffer
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format
+func (instrumentation *MetricsInstrumentation) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	_, _ = instrumentation.WriteTo(w)
+}
+
+func (labels metricsLabels) format() string {
+	return fmt.Sprintf(
+		`operation="%s",table="%s",status="%s"`,
+		escapeMetricsLabelValue(labels.operation),
+		escapeMetricsLabelValue(labels.table),
+		escapeMetricsLabelValue(labels.status),
+	)
+}
+
+func escapeMetricsLabelValue(value string) string {
+	return metricsLabelValueReplacer.Replace(value)
+}
@@ -0,0 +1,65 @@
+package miniorm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsInstrumentation(t *testing.T) {
+	instrumentation := NewMetricsInstrumentation(1, 0.1)
+
+	for _, event := range []*QueryEvent{
+		{Operation: OperationGet, TableName: "entry", Duration: 50 * time.Millisecond, RowsAffected: 1},
+		{Operation: OperationGet, TableName: "entry", Duration: 500 * time.Millisecond, RowsAffected: 1},
+		{Operation: OperationGet, TableName: "entry", Duration: time.Millisecond, Err: ErrNotFound},
+		{Operation: OperationUpdate, TableName: `"entry"`, Duration: 2 * time.Second, Err: errors.New("failed")},
+	} {
+		ctx := instrumentation.OnQueryStart(context.Background(), event)
+		instrumentation.OnQueryEnd(ctx, event)
+	}
+
+	buffer := &bytes.Buffer{}
+	_, err := instrumentation.WriteTo(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"# HELP miniorm_operation_duration_seconds Duration of the operations of go-miniorm.",
+		"# TYPE miniorm_operation_duration_seconds histogram",
+		`miniorm_operation_duration_seconds_bucket{operation="Get",table="entry",status="not_found",le="0.1"} 1`,
+		`miniorm_operation_duration_seconds_bucket{operation="Get",table="entry",status="not_found",le="1"} 1`,
+		`miniorm_operation_duration_seconds_bucket{operation="Get",table="entry",status="not_found",le="+Inf"} 1`,
+		`miniorm_operation_duration_seconds_sum{operation="Get",table="entry",status="not_found"} 0.001`,
+		`miniorm_operation_duration_seconds_count{operation="Get",table="entry",status="not_found"} 1`,
+		`miniorm_operation_duration_seconds_bucket{operation="Get",table="entry",status="ok",le="0.1"} 1`,
+		`miniorm_operation_duration_seconds_bucket{operation="Get",table="entry",status="ok",le="1"} 2`,
+		`miniorm_operation_duration_seconds_bucket{operation="Get",table="entry",status="ok",le="+Inf"} 2`,
+		`miniorm_operation_duration_seconds_sum{operation="Get",table="entry",status="ok"} 0.55`,
+		`miniorm_operation_duration_seconds_count{operation="Get",table="entry",status="ok"} 2`,
+		`miniorm_operation_duration_seconds_bucket{operation="Update",table="\"entry\"",status="error",le="0.1"} 0`,
+		`miniorm_operation_duration_seconds_bucket{operation="Update",table="\"entry\"",status="error",le="1"} 0`,
+		`miniorm_operation_duration_seconds_bucket{operation="Update",table="\"entry\"",status="error",le="+Inf"} 1`,
+		`miniorm_operation_duration_seconds_sum{operation="Update",table="\"entry\"",status="error"} 2`,
+		`miniorm_operation_duration_seconds_count{operation="Update",table="\"entry\"",status="error"} 1`,
+		"# HELP miniorm_operation_rows_affected_total Rows written or loaded by the operations of go-miniorm.",
+		"# TYPE miniorm_operation_rows_affected_total counter",
+		`miniorm_operation_rows_affected_total{operation="Get",table="entry",status="not_found"} 0`,
+		`miniorm_operation_rows_affected_total{operation="Get",table="entry",status="ok"} 2`,
+		`miniorm_operation_rows_affected_total{operation="Update",table="\"entry\"",status="error"} 0`,
+		"",
+	}, "\n"), buffer.String())
+
+	recorder := httptest.NewRecorder()
+	instrumentation.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, buffer.String(), recorder.Body.String())
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "text/plain")
+}
+
+func TestNewMetricsInstrumentationDefaultBuckets(t *testing.T) {
+	assert.Equal(t, DefaultMetricsBuckets, NewMetricsInstrumentation().buckets)
+}
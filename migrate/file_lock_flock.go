@@ -0,0 +1,52 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+const (
+	lockFilePollInterval = 100 * time.Millisecond
+)
+
+// lockFile takes an exclusive flock on the file at path, which is released by the operating system if the process
+// exits without unlocking it
+func lockFile(ctx context.Context, path string) (unlockFunc, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			_ = file.Close()
+
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			_ = file.Close()
+
+			return nil, ctx.Err()
+		case <-time.After(lockFilePollInterval):
+		}
+	}
+
+	return func() error {
+		defer file.Close()
+
+		return syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	}, nil
+}
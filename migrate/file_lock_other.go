@@ -0,0 +1,42 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	lockFilePollInterval = 100 * time.Millisecond
+)
+
+// lockFile takes the lock by exclusively creating the file at path, and releases it by removing the file. Unlike
+// flock, the lock is not released if the process exits without unlocking it, so the file has to be removed manually.
+func lockFile(ctx context.Context, path string) (unlockFunc, error) {
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0o600)
+		if err == nil {
+			_ = file.Close()
+
+			break
+		}
+
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockFilePollInterval):
+		}
+	}
+
+	return func() error {
+		return os.Remove(path)
+	}, nil
+}
@@ -0,0 +1,102 @@
+//go:build go1.16
+// +build go1.16
+
+package migrate
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	miniorm "github.com/CCS-CloudServices/go-miniorm"
+)
+
+var (
+	ErrInvalidFileName   = errors.New("expected migration file name to be <version>_<name>[.<driver>].<up|down>.sql")
+	ErrUpScriptNotFound  = errors.New("expected migration to have an up script")
+	ErrMismatchedVersion = errors.New("expected migration files of the same version to have the same name")
+
+	migrationFileNameRegex = regexp.MustCompile(`^(\d+)_([^.]+)(?:\.(mysql|postgres|sqlite3|mssql))?\.(up|down)\.sql$`)
+)
+
+// LoadMigrations loads the migrations of driverType from the .sql files in dir of fsys, usually an embed.FS:
+//
+//	//go:embed migrations/*.sql
+//	var migrationsFS embed.FS
+//
+//	migrations, err := migrate.LoadMigrations(migrationsFS, "migrations", databaseConfig.Driver)
+//
+// Files are named <version>_<name>.<up|down>.sql, e.g. 0001_create_entries.up.sql. A file with the driver type before
+// the direction, e.g. 0001_create_entries.mssql.up.sql, replaces the generic file for that driver type only.
+func LoadMigrations(fsys fs.FS, dir string, driverType miniorm.DriverType) ([]Migration, error) {
+	dirEntries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	versionToMigration := make(map[int64]*Migration)
+	// Whether the script of a version and direction was read from a file of driverType
+	isDriverScript := make(map[string]bool)
+
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".sql") {
+			continue
+		}
+
+		matches := migrationFileNameRegex.FindStringSubmatch(dirEntry.Name())
+		if matches == nil {
+			return nil, ErrInvalidFileName
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil {
+			return nil, ErrInvalidFileName
+		}
+
+		name, fileDriverType, direction := matches[2], miniorm.DriverType(matches[3]), matches[4]
+		if fileDriverType != "" && fileDriverType != driverType {
+			continue
+		}
+
+		migration, ok := versionToMigration[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			versionToMigration[version] = migration
+		} else if migration.Name != name {
+			return nil, ErrMismatchedVersion
+		}
+
+		scriptKey := strconv.FormatInt(version, 10) + "." + direction
+		if isDriverScript[scriptKey] && fileDriverType == "" {
+			continue
+		}
+
+		script, err := fs.ReadFile(fsys, path.Join(dir, dirEntry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if direction == "up" {
+			migration.Up = string(script)
+		} else {
+			migration.Down = string(script)
+		}
+
+		isDriverScript[scriptKey] = fileDriverType != ""
+	}
+
+	migrations := make([]Migration, 0, len(versionToMigration))
+
+	for _, migration := range versionToMigration {
+		if strings.TrimSpace(migration.Up) == "" {
+			return nil, ErrUpScriptNotFound
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	return migrations, nil
+}
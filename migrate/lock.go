@@ -0,0 +1,121 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	miniorm "github.com/CCS-CloudServices/go-miniorm"
+)
+
+const (
+	lockName = "miniorm_migrate"
+	// pg_advisory_lock takes a bigint key, this one is "miniorm" in ASCII
+	postgresLockKey = 0x6d696e696f726d
+	// Suffix of the lock file of a SQLite3 database file
+	sqlite3LockFileSuffix = ".migrate.lock"
+)
+
+type unlockFunc func() error
+
+// lock takes the lock of the database engine against concurrent migrators on conn. The returned unlockFunc must be
+// called on the same connection, before it is closed.
+func lock(ctx context.Context, conn *sql.Conn, databaseConfig miniorm.DatabaseConfig) (unlockFunc, error) {
+	switch databaseConfig.Driver {
+	case miniorm.DriverTypeMySQL:
+		return lockMySQL(ctx, conn)
+	case miniorm.DriverTypePostgres:
+		return lockPostgres(ctx, conn)
+	case miniorm.DriverTypeMSSQL:
+		return lockMSSQL(ctx, conn)
+	case miniorm.DriverTypeSQLite3:
+		path, ok := getSQLite3LockFilePath(databaseConfig.URL)
+		if !ok {
+			// In-memory databases are not shared with other processes
+			return func() error { return nil }, nil
+		}
+
+		return lockFile(ctx, path)
+	default:
+		return nil, errors.New("invalid driver type")
+	}
+}
+
+func lockMySQL(ctx context.Context, conn *sql.Conn) (unlockFunc, error) {
+	// GET_LOCK is server wide, so the lock name includes the database name
+	var isAcquired sql.NullInt64
+	if err := conn.
+		QueryRowContext(ctx, fmt.Sprintf("SELECT GET_LOCK(CONCAT('%s_', DATABASE()), -1)", lockName)).
+		Scan(&isAcquired); err != nil {
+		return nil, err
+	}
+
+	if isAcquired.Int64 != 1 {
+		return nil, ErrLockNotAcquired
+	}
+
+	return func() error {
+		_, err := conn.ExecContext(context.Background(), fmt.Sprintf("DO RELEASE_LOCK(CONCAT('%s_', DATABASE()))", lockName))
+
+		return err
+	}, nil
+}
+
+func lockPostgres(ctx context.Context, conn *sql.Conn) (unlockFunc, error) {
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("SELECT pg_advisory_lock(%d)", postgresLockKey)); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		_, err := conn.ExecContext(context.Background(), fmt.Sprintf("SELECT pg_advisory_unlock(%d)", postgresLockKey))
+
+		return err
+	}, nil
+}
+
+func lockMSSQL(ctx context.Context, conn *sql.Conn) (unlockFunc, error) {
+	// sp_getapplock returns a negative status if the lock is not granted
+	var status int64
+	if err := conn.QueryRowContext(ctx, fmt.Sprintf(`
+		DECLARE @status INT;
+		EXEC @status = sp_getapplock @Resource = '%s', @LockMode = 'Exclusive', @LockOwner = 'Session', @LockTimeout = -1;
+		SELECT @status;
+	`, lockName)).Scan(&status); err != nil {
+		return nil, err
+	}
+
+	if status < 0 {
+		return nil, ErrLockNotAcquired
+	}
+
+	return func() error {
+		_, err := conn.ExecContext(
+			context.Background(),
+			fmt.Sprintf("EXEC sp_releaseapplock @Resource = '%s', @LockOwner = 'Session'", lockName),
+		)
+
+		return err
+	}, nil
+}
+
+// getSQLite3LockFilePath returns the path of the lock file next to the database file of url, e.g. "file:test.db" or
+// "test.db?_busy_timeout=5000". ok is false for in-memory databases.
+func getSQLite3LockFilePath(url string) (path string, ok bool) {
+	path = strings.TrimPrefix(url, "file:")
+
+	if separatorPosition := strings.Index(path, "?"); separatorPosition >= 0 {
+		if strings.Contains(path[separatorPosition+1:], "mode=memory") {
+			return "", false
+		}
+
+		path = path[:separatorPosition]
+	}
+
+	if path == "" || path == ":memory:" {
+		return "", false
+	}
+
+	return path + sqlite3LockFileSuffix, true
+}
@@ -0,0 +1,442 @@
+// Package migrate applies versioned up and down migrations to the databases supported by miniorm, recording the
+// applied versions in the schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+
+	miniorm "github.com/CCS-CloudServices/go-miniorm"
+)
+
+const (
+	// TableName is the table recording the applied migrations
+	TableName = "schema_migrations"
+
+	versionColumnName   = "version"
+	nameColumnName      = "name"
+	appliedAtColumnName = "applied_at"
+
+	// Statements between these comments are executed as a single statement, e.g. for function bodies
+	statementBeginComment = "-- +miniorm StatementBegin"
+	statementEndComment   = "-- +miniorm StatementEnd"
+)
+
+var (
+	ErrInvalidVersion        = errors.New("expected migration versions to be positive")
+	ErrDuplicateVersion      = errors.New("expected migration versions to be unique")
+	ErrUnknownVersion        = errors.New("expected version to be 0 or the version of a migration")
+	ErrMigrationNotFound     = errors.New("expected applied migration to be one of the migrations")
+	ErrIrreversibleMigration = errors.New("expected migration to have a down script")
+	ErrLockNotAcquired       = errors.New("expected migration lock to be acquired")
+
+	driverTypeToDialect = map[miniorm.DriverType]string{
+		miniorm.DriverTypeMSSQL:    "sqlserver",
+		miniorm.DriverTypeMySQL:    "mysql",
+		miniorm.DriverTypePostgres: "postgres",
+		miniorm.DriverTypeSQLite3:  "sqlite3",
+	}
+
+	driverTypeToCreateTableStatement = map[miniorm.DriverType]string{
+		miniorm.DriverTypeMSSQL: "IF OBJECT_ID(N'%[1]s', N'U') IS NULL " +
+			"CREATE TABLE %[1]s (version BIGINT NOT NULL PRIMARY KEY, name NVARCHAR(255) NOT NULL, applied_at BIGINT NOT NULL)",
+		miniorm.DriverTypeMySQL: "CREATE TABLE IF NOT EXISTS %[1]s " +
+			"(version BIGINT NOT NULL, name VARCHAR(255) NOT NULL, applied_at BIGINT NOT NULL, PRIMARY KEY (version))",
+		miniorm.DriverTypePostgres: "CREATE TABLE IF NOT EXISTS %[1]s " +
+			"(version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at BIGINT NOT NULL)",
+		miniorm.DriverTypeSQLite3: "CREATE TABLE IF NOT EXISTS %[1]s " +
+			"(version BIGINT NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at BIGINT NOT NULL)",
+	}
+)
+
+// Migration is a versioned change of the schema. Down may be empty if the migration cannot be rolled back.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is the state of a migration, AppliedAt is zero if the migration is not applied
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type appliedMigration struct {
+	Version   int64  `db:"version"`
+	Name      string `db:"name"`
+	AppliedAt int64  `db:"applied_at"`
+}
+
+// Migrator applies migrations to the database of a DatabaseConfig. Every operation takes a lock held until it
+// completes, so that concurrent migrators, e.g. several instances of a service starting up, wait for each other.
+type Migrator struct {
+	db             *sql.DB
+	databaseConfig miniorm.DatabaseConfig
+	migrations     []Migration
+}
+
+func NewMigrator(databaseConfig miniorm.DatabaseConfig, migrations []Migration) (*Migrator, error) {
+	if _, ok := driverTypeToDialect[databaseConfig.Driver]; !ok {
+		return nil, errors.New("invalid driver type")
+	}
+
+	sortedMigrations := append([]Migration{}, migrations...)
+	sort.Slice(sortedMigrations, func(i, j int) bool {
+		return sortedMigrations[i].Version < sortedMigrations[j].Version
+	})
+
+	for i, migration := range sortedMigrations {
+		if migration.Version <= 0 {
+			return nil, ErrInvalidVersion
+		}
+
+		if i > 0 && sortedMigrations[i-1].Version == migration.Version {
+			return nil, ErrDuplicateVersion
+		}
+	}
+
+	db, err := miniorm.NewSQLDatabase(databaseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:             db,
+		databaseConfig: databaseConfig,
+		migrations:     sortedMigrations,
+	}, nil
+}
+
+// Up applies all migrations which are not applied yet, in ascending order of version
+func (migrator *Migrator) Up(ctx context.Context) error {
+	if len(migrator.migrations) == 0 {
+		return nil
+	}
+
+	return migrator.To(ctx, migrator.migrations[len(migrator.migrations)-1].Version)
+}
+
+// Down rolls back the applied migration with the highest version
+func (migrator *Migrator) Down(ctx context.Context) error {
+	return migrator.withLock(ctx, func(conn *sql.Conn, appliedMigrations map[int64]appliedMigration) error {
+		latestVersion := int64(0)
+		for version := range appliedMigrations {
+			if version > latestVersion {
+				latestVersion = version
+			}
+		}
+
+		if latestVersion == 0 {
+			return nil
+		}
+
+		migration, ok := migrator.getMigration(latestVersion)
+		if !ok {
+			return ErrMigrationNotFound
+		}
+
+		return migrator.rollback(ctx, conn, migration)
+	})
+}
+
+// To applies the migrations up to version which are not applied yet, and rolls back the applied migrations above
+// version. Version 0 rolls back all migrations.
+func (migrator *Migrator) To(ctx context.Context, version int64) error {
+	if _, ok := migrator.getMigration(version); !ok && version != 0 {
+		return ErrUnknownVersion
+	}
+
+	return migrator.withLock(ctx, func(conn *sql.Conn, appliedMigrations map[int64]appliedMigration) error {
+		rollbackVersions := make([]int64, 0)
+		for appliedVersion := range appliedMigrations {
+			if appliedVersion > version {
+				rollbackVersions = append(rollbackVersions, appliedVersion)
+			}
+		}
+
+		sort.Slice(rollbackVersions, func(i, j int) bool {
+			return rollbackVersions[i] > rollbackVersions[j]
+		})
+
+		for _, rollbackVersion := range rollbackVersions {
+			migration, ok := migrator.getMigration(rollbackVersion)
+			if !ok {
+				return ErrMigrationNotFound
+			}
+
+			if err := migrator.rollback(ctx, conn, migration); err != nil {
+				return err
+			}
+		}
+
+		for _, migration := range migrator.migrations {
+			if _, isApplied := appliedMigrations[migration.Version]; isApplied || migration.Version > version {
+				continue
+			}
+
+			if err := migrator.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Status returns the state of every migration, along with the applied migrations that are not in the migrations
+// of the Migrator, in ascending order of version
+func (migrator *Migrator) Status(ctx context.Context) (statusList []MigrationStatus, err error) {
+	err = migrator.withLock(ctx, func(_ *sql.Conn, appliedMigrations map[int64]appliedMigration) error {
+		for _, migration := range migrator.migrations {
+			status := MigrationStatus{
+				Version: migration.Version,
+				Name:    migration.Name,
+			}
+
+			if applied, ok := appliedMigrations[migration.Version]; ok {
+				status.Applied = true
+				status.AppliedAt = time.Unix(applied.AppliedAt, 0)
+			}
+
+			statusList = append(statusList, status)
+		}
+
+		for _, applied := range appliedMigrations {
+			if _, ok := migrator.getMigration(applied.Version); !ok {
+				statusList = append(statusList, MigrationStatus{
+					Version:   applied.Version,
+					Name:      applied.Name,
+					Applied:   true,
+					AppliedAt: time.Unix(applied.AppliedAt, 0),
+				})
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(statusList, func(i, j int) bool {
+		return statusList[i].Version < statusList[j].Version
+	})
+
+	return statusList, nil
+}
+
+// Close closes the connections of the Migrator
+func (migrator *Migrator) Close() error {
+	return migrator.db.Close()
+}
+
+func (migrator *Migrator) getMigration(version int64) (Migration, bool) {
+	index := sort.Search(len(migrator.migrations), func(i int) bool {
+		return migrator.migrations[i].Version >= version
+	})
+
+	if index < len(migrator.migrations) && migrator.migrations[index].Version == version {
+		return migrator.migrations[index], true
+	}
+
+	return Migration{}, false
+}
+
+// withLock runs executeFunc on a dedicated connection holding the migration lock, with the applied migrations read
+// after the lock is acquired
+func (migrator *Migrator) withLock(
+	ctx context.Context,
+	executeFunc func(conn *sql.Conn, appliedMigrations map[int64]appliedMigration) error,
+) (err error) {
+	conn, err := migrator.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	unlock, err := lock(ctx, conn, migrator.databaseConfig)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if unlockErr := unlock(); err == nil {
+			err = unlockErr
+		}
+	}()
+
+	createTableStatement := fmt.Sprintf(driverTypeToCreateTableStatement[migrator.databaseConfig.Driver], TableName)
+	if _, err := conn.ExecContext(ctx, createTableStatement); err != nil {
+		return err
+	}
+
+	appliedMigrations, err := migrator.getAppliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return executeFunc(conn, appliedMigrations)
+}
+
+func (migrator *Migrator) getAppliedMigrations(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	sqlStatement, _, err := migrator.getDialect().
+		From(TableName).
+		Select(versionColumnName, nameColumnName, appliedAtColumnName).
+		ToSQL()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, sqlStatement)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	appliedMigrations := make(map[int64]appliedMigration)
+
+	for rows.Next() {
+		applied := appliedMigration{}
+		if err := rows.Scan(&applied.Version, &applied.Name, &applied.AppliedAt); err != nil {
+			return nil, err
+		}
+
+		appliedMigrations[applied.Version] = applied
+	}
+
+	return appliedMigrations, rows.Err()
+}
+
+func (migrator *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	sqlStatement, _, err := migrator.getDialect().
+		Insert(TableName).
+		Rows(goqu.Record{
+			versionColumnName:   migration.Version,
+			nameColumnName:      migration.Name,
+			appliedAtColumnName: time.Now().Unix(),
+		}).
+		ToSQL()
+	if err != nil {
+		return err
+	}
+
+	if err := withTx(ctx, conn, splitStatements(migration.Up), sqlStatement); err != nil {
+		return err
+	}
+
+	migrator.logf("miniorm: applied migration %d %s", migration.Version, migration.Name)
+
+	return nil
+}
+
+func (migrator *Migrator) rollback(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if strings.TrimSpace(migration.Down) == "" {
+		return ErrIrreversibleMigration
+	}
+
+	sqlStatement, _, err := migrator.getDialect().
+		Delete(TableName).
+		Where(goqu.C(versionColumnName).Eq(migration.Version)).
+		ToSQL()
+	if err != nil {
+		return err
+	}
+
+	if err := withTx(ctx, conn, splitStatements(migration.Down), sqlStatement); err != nil {
+		return err
+	}
+
+	migrator.logf("miniorm: rolled back migration %d %s", migration.Version, migration.Name)
+
+	return nil
+}
+
+func (migrator *Migrator) getDialect() goqu.DialectWrapper {
+	return goqu.Dialect(driverTypeToDialect[migrator.databaseConfig.Driver])
+}
+
+func (migrator *Migrator) logf(format string, v ...interface{}) {
+	if migrator.databaseConfig.Logger != nil {
+		migrator.databaseConfig.Logger.Printf(format, v...)
+	}
+}
+
+// withTx executes the statements of a migration and the statement recording it in a single transaction. Note that
+// MySQL implicitly commits DDL statements, so a failed migration may be partially applied there.
+func withTx(ctx context.Context, conn *sql.Conn, migrationStatements []string, recordStatement string) (err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, statement := range append(migrationStatements, recordStatement) {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// splitStatements splits script into statements ending with a semicolon at the end of a line, except between the
+// StatementBegin and StatementEnd comments. Comment lines outside of these comments are dropped, since some drivers
+// reject statements without SQL.
+func splitStatements(script string) []string {
+	statements := make([]string, 0)
+	statement := strings.Builder{}
+	isInBlock := false
+
+	flush := func() {
+		if trimmedStatement := strings.TrimSpace(statement.String()); trimmedStatement != "" {
+			statements = append(statements, trimmedStatement)
+		}
+
+		statement.Reset()
+	}
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmedLine := strings.TrimSpace(line)
+
+		switch {
+		case strings.HasPrefix(trimmedLine, statementBeginComment):
+			flush()
+
+			isInBlock = true
+		case strings.HasPrefix(trimmedLine, statementEndComment):
+			flush()
+
+			isInBlock = false
+		case !isInBlock && strings.HasPrefix(trimmedLine, "--"):
+		default:
+			statement.WriteString(line)
+			statement.WriteString("\n")
+
+			if !isInBlock && strings.HasSuffix(trimmedLine, ";") {
+				flush()
+			}
+		}
+	}
+
+	flush()
+
+	return statements
+}
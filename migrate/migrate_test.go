@@ -0,0 +1,315 @@
+//go:build go1.16
+// +build go1.16
+
+package migrate
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/denisenkom/go-mssqldb" // For MSSQL driver
+	_ "github.com/go-sql-driver/mysql"   // For Mysql driver
+	_ "github.com/jackc/pgx/v4/stdlib"   // For Postgres driver
+	_ "github.com/mattn/go-sqlite3"      // For SQLite driver
+	"github.com/stretchr/testify/assert"
+
+	miniorm "github.com/CCS-CloudServices/go-miniorm"
+)
+
+var (
+	mysqlTestConfig = miniorm.DatabaseConfig{
+		Driver:       miniorm.DriverTypeMySQL,
+		Host:         "localhost",
+		Port:         3306,
+		DatabaseName: "test",
+		User:         "root",
+		Password:     "password",
+		Logger:       log.Default(),
+	}
+
+	postgresTestConfig = miniorm.DatabaseConfig{
+		Driver:       miniorm.DriverTypePostgres,
+		Host:         "localhost",
+		Port:         5432,
+		DatabaseName: "test",
+		User:         "user",
+		Password:     "password",
+		Logger:       log.Default(),
+	}
+
+	mssqlTestConfig = miniorm.DatabaseConfig{
+		Driver:       miniorm.DriverTypeMSSQL,
+		Host:         "localhost",
+		Port:         1433,
+		DatabaseName: "master",
+		User:         "sa",
+		Password:     "Acronis123",
+		Logger:       log.Default(),
+	}
+
+	testMigrationsFS = fstest.MapFS{
+		"migrations/0001_create_migrate_entries.up.sql": {Data: []byte(`
+			-- The entries of the migration tests
+			CREATE TABLE migrate_entries (
+				id BIGINT NOT NULL PRIMARY KEY,
+				string_col VARCHAR(255) NOT NULL
+			);
+		`)},
+		"migrations/0001_create_migrate_entries.down.sql": {Data: []byte(`
+			DROP TABLE migrate_entries;
+		`)},
+		"migrations/0002_add_int_col.up.sql": {Data: []byte(`
+			ALTER TABLE migrate_entries ADD COLUMN int_col BIGINT NULL;
+			INSERT INTO migrate_entries (id, string_col, int_col) VALUES (1, 'value 1', 1);
+		`)},
+		"migrations/0002_add_int_col.mssql.up.sql": {Data: []byte(`
+			ALTER TABLE migrate_entries ADD int_col BIGINT NULL;
+			INSERT INTO migrate_entries (id, string_col, int_col) VALUES (1, 'value 1', 1);
+		`)},
+		"migrations/0002_add_int_col.down.sql": {Data: []byte(`
+			DELETE FROM migrate_entries WHERE id = 1;
+			ALTER TABLE migrate_entries DROP COLUMN int_col;
+		`)},
+		"migrations/README.md": {Data: []byte("Not a migration")},
+	}
+)
+
+func getSQLite3TestConfig(t *testing.T) miniorm.DatabaseConfig {
+	return miniorm.DatabaseConfig{
+		Driver: miniorm.DriverTypeSQLite3,
+		URL:    "file:" + filepath.Join(t.TempDir(), "test.db"),
+		Logger: log.Default(),
+	}
+}
+
+func prepareMigrateTestTables(databaseConfig miniorm.DatabaseConfig) error {
+	db, err := miniorm.NewSQLDatabase(databaseConfig)
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	for _, tableName := range []string{"migrate_entries", TableName} {
+		if _, err := db.Exec("DROP TABLE IF EXISTS " + tableName); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func testMigrator(t *testing.T, databaseConfig miniorm.DatabaseConfig) {
+	err := prepareMigrateTestTables(databaseConfig)
+	assert.Nil(t, err)
+
+	migrations, err := LoadMigrations(testMigrationsFS, "migrations", databaseConfig.Driver)
+	assert.Nil(t, err)
+
+	migrator, err := NewMigrator(databaseConfig, migrations)
+	assert.Nil(t, err)
+
+	defer migrator.Close()
+
+	statusList, err := migrator.Status(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []MigrationStatus{
+		{Version: 1, Name: "create_migrate_entries"},
+		{Version: 2, Name: "add_int_col"},
+	}, statusList)
+
+	err = migrator.Up(context.Background())
+	assert.Nil(t, err)
+	assertAppliedVersions(t, migrator, 1, 2)
+
+	err = migrator.Down(context.Background())
+	assert.Nil(t, err)
+	assertAppliedVersions(t, migrator, 1)
+
+	err = migrator.To(context.Background(), 2)
+	assert.Nil(t, err)
+	assertAppliedVersions(t, migrator, 1, 2)
+
+	err = migrator.To(context.Background(), 0)
+	assert.Nil(t, err)
+	assertAppliedVersions(t, migrator)
+
+	err = migrator.To(context.Background(), 3)
+	assert.ErrorIs(t, err, ErrUnknownVersion)
+
+	// Concurrent migrators wait for each other, so every migration is applied once
+	waitGroup := sync.WaitGroup{}
+
+	for i := 0; i < 3; i++ {
+		waitGroup.Add(1)
+
+		go func() {
+			defer waitGroup.Done()
+
+			concurrentMigrator, err := NewMigrator(databaseConfig, migrations)
+			assert.Nil(t, err)
+
+			defer concurrentMigrator.Close()
+
+			err = concurrentMigrator.Up(context.Background())
+			assert.Nil(t, err)
+		}()
+	}
+
+	waitGroup.Wait()
+	assertAppliedVersions(t, migrator, 1, 2)
+
+	irreversibleMigrator, err := NewMigrator(databaseConfig, []Migration{{Version: 1, Name: "create_migrate_entries"}})
+	assert.Nil(t, err)
+
+	defer irreversibleMigrator.Close()
+
+	err = irreversibleMigrator.Down(context.Background())
+	assert.ErrorIs(t, err, ErrMigrationNotFound)
+
+	err = irreversibleMigrator.To(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrMigrationNotFound)
+}
+
+func assertAppliedVersions(t *testing.T, migrator *Migrator, expectedVersions ...int64) {
+	statusList, err := migrator.Status(context.Background())
+	assert.Nil(t, err)
+
+	appliedVersions := make([]int64, 0)
+
+	for _, status := range statusList {
+		if status.Applied {
+			assert.False(t, status.AppliedAt.IsZero())
+
+			appliedVersions = append(appliedVersions, status.Version)
+		}
+	}
+
+	assert.Equal(t, append([]int64{}, expectedVersions...), appliedVersions)
+}
+
+func TestMySQLMigrator(t *testing.T) {
+	testMigrator(t, mysqlTestConfig)
+}
+
+func TestPostgresMigrator(t *testing.T) {
+	testMigrator(t, postgresTestConfig)
+}
+
+func TestMSSQLMigrator(t *testing.T) {
+	testMigrator(t, mssqlTestConfig)
+}
+
+func TestSQLite3Migrator(t *testing.T) {
+	testMigrator(t, getSQLite3TestConfig(t))
+}
+
+func TestNewMigrator(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewMigrator(miniorm.DatabaseConfig{Driver: "unknown"}, nil)
+	assert.NotNil(t, err)
+
+	databaseConfig := getSQLite3TestConfig(t)
+
+	_, err = NewMigrator(databaseConfig, []Migration{{Version: 0, Up: "SELECT 1;"}})
+	assert.ErrorIs(t, err, ErrInvalidVersion)
+
+	_, err = NewMigrator(databaseConfig, []Migration{{Version: 1, Up: "SELECT 1;"}, {Version: 1, Up: "SELECT 2;"}})
+	assert.ErrorIs(t, err, ErrDuplicateVersion)
+}
+
+func TestLoadMigrations(t *testing.T) {
+	t.Parallel()
+
+	migrations, err := LoadMigrations(testMigrationsFS, "migrations", miniorm.DriverTypeMSSQL)
+	assert.Nil(t, err)
+	assert.Len(t, migrations, 2)
+
+	for _, migration := range migrations {
+		if migration.Version == 2 {
+			assert.Equal(t, "add_int_col", migration.Name)
+			assert.Contains(t, migration.Up, "ADD int_col")
+			assert.Contains(t, migration.Down, "DROP COLUMN int_col")
+		}
+	}
+
+	testCaseList := []struct {
+		fsys          fstest.MapFS
+		expectedError error
+	}{
+		{
+			fsys:          fstest.MapFS{"migrations/create_entries.up.sql": {}},
+			expectedError: ErrInvalidFileName,
+		},
+		{
+			fsys:          fstest.MapFS{"migrations/0001_create_entries.down.sql": {Data: []byte("DROP TABLE entries;")}},
+			expectedError: ErrUpScriptNotFound,
+		},
+		{
+			fsys: fstest.MapFS{
+				"migrations/0001_create_entries.up.sql":   {Data: []byte("CREATE TABLE entries (id BIGINT);")},
+				"migrations/0001_create_records.down.sql": {Data: []byte("DROP TABLE records;")},
+			},
+			expectedError: ErrMismatchedVersion,
+		},
+	}
+
+	for _, testCase := range testCaseList {
+		_, err := LoadMigrations(testCase.fsys, "migrations", miniorm.DriverTypeMySQL)
+		assert.ErrorIs(t, err, testCase.expectedError)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	t.Parallel()
+
+	statements := splitStatements(`
+		-- Entries
+		CREATE TABLE entries (
+			id BIGINT NOT NULL
+		);
+		INSERT INTO entries (id) VALUES (1); INSERT INTO entries (id) VALUES (2);
+
+		-- +miniorm StatementBegin
+		CREATE FUNCTION one() RETURNS INT AS $$
+		BEGIN
+			RETURN 1;
+		END;
+		$$ LANGUAGE plpgsql;
+		-- +miniorm StatementEnd
+		DROP TABLE records
+	`)
+	assert.Equal(t, []string{
+		"CREATE TABLE entries (\n\t\t\tid BIGINT NOT NULL\n\t\t);",
+		"INSERT INTO entries (id) VALUES (1); INSERT INTO entries (id) VALUES (2);",
+		"CREATE FUNCTION one() RETURNS INT AS $$\n\t\tBEGIN\n\t\t\tRETURN 1;\n\t\tEND;\n\t\t$$ LANGUAGE plpgsql;",
+		"DROP TABLE records",
+	}, statements)
+}
+
+func TestGetSQLite3LockFilePath(t *testing.T) {
+	t.Parallel()
+
+	testCaseList := []struct {
+		url          string
+		expectedPath string
+		expectedOK   bool
+	}{
+		{url: "file:test.db", expectedPath: "test.db.migrate.lock", expectedOK: true},
+		{url: "test.db?_busy_timeout=5000", expectedPath: "test.db.migrate.lock", expectedOK: true},
+		{url: "file::memory:?cache=shared", expectedOK: false},
+		{url: "file:test.db?mode=memory", expectedOK: false},
+		{url: ":memory:", expectedOK: false},
+	}
+
+	for _, testCase := range testCaseList {
+		path, ok := getSQLite3LockFilePath(testCase.url)
+		assert.Equal(t, testCase.expectedPath, path)
+		assert.Equal(t, testCase.expectedOK, ok)
+	}
+}
@@ -0,0 +1,218 @@
+package miniorm
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+const (
+	miniormTagName                = "miniorm"
+	miniormTagOptionTable         = "table"
+	miniormTagOptionPrimaryKey    = "pk"
+	miniormTagOptionAutoIncrement = "autoincrement"
+	miniormTagOptionGenerated     = "generated"
+	miniormTagOptionVersion       = "version"
+	miniormTagOptionSoftDelete    = "softdelete"
+	miniormTagOptionHasMany       = "hasmany"
+	miniormTagOptionBelongsTo     = "belongsto"
+	miniormTagOptionForeignKey    = "fk"
+	miniormTagOptionReferences    = "references"
+	miniormTagOptionSensitive     = "sensitive"
+)
+
+var (
+	// Metadata of model structs, derived from their tags once per type
+	modelMetadataCache sync.Map
+)
+
+// modelField is a field of a model struct that is mapped to a column
+type modelField struct {
+	index     []int
+	column    string
+	fieldType reflect.Type
+}
+
+// modelMetadata is the metadata of a model struct, used when the model does not implement the corresponding interfaces:
+//
+//	type Entry struct {
+//		_         struct{} `miniorm:"table=entries"`
+//		ID        int64    `db:"id" goqu:"skipinsert,skipupdate" miniorm:"pk,autoincrement"`
+//		Version   int64    `db:"version" miniorm:"version"`
+//		DeletedAt *int64   `db:"deleted_at" miniorm:"softdelete"`
+//		Password  string   `db:"password" miniorm:"sensitive"`
+//		Children  []Child  `db:"-" miniorm:"hasmany,fk=parent_id"`
+//	}
+type modelMetadata struct {
+	tableName         string
+	fields            []modelField
+	primaryKeyFields  []modelField
+	generatedKeyField *modelField
+	versionField      *modelField
+	softDeleteField   *modelField
+	sensitiveFields   []modelField
+	relations         []Relation
+}
+
+// getModelMetadata returns the metadata of entry, a struct or a pointer to a struct, along with its struct value,
+// which is settable if entry is a pointer
+func getModelMetadata(entry interface{}) (metadata *modelMetadata, entryValue reflect.Value, ok bool) {
+	entryValue = reflect.ValueOf(entry)
+	if entryValue.Kind() == reflect.Ptr {
+		if entryValue.IsNil() {
+			return nil, reflect.Value{}, false
+		}
+
+		entryValue = entryValue.Elem()
+	}
+
+	if entryValue.Kind() != reflect.Struct {
+		return nil, reflect.Value{}, false
+	}
+
+	return getTypeModelMetadata(entryValue.Type()), entryValue, true
+}
+
+func getTypeModelMetadata(entryType reflect.Type) *modelMetadata {
+	if metadata, ok := modelMetadataCache.Load(entryType); ok {
+		return metadata.(*modelMetadata)
+	}
+
+	metadata := &modelMetadata{}
+	metadata.addFields(entryType, nil)
+
+	cachedMetadata, _ := modelMetadataCache.LoadOrStore(entryType, metadata)
+
+	return cachedMetadata.(*modelMetadata)
+}
+
+// addFields adds the tagged fields of structType to the metadata. Like goqu, embedded structs without a db tag are
+// flattened into the model.
+func (metadata *modelMetadata) addFields(structType reflect.Type, parentIndex []int) {
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		index := append(append([]int{}, parentIndex...), i)
+		tagOptions := getMiniormTagOptions(structField)
+
+		if tableName, ok := tagOptions[miniormTagOptionTable]; ok && metadata.tableName == "" {
+			metadata.tableName = tableName
+		}
+
+		if structField.Anonymous && structField.Type.Kind() == reflect.Struct && structField.Tag.Get("db") == "" {
+			metadata.addFields(structField.Type, index)
+
+			continue
+		}
+
+		// Relation fields are not columns, so they are usually tagged with db:"-"
+		if relation, ok := getTagRelation(structField, tagOptions); ok {
+			metadata.relations = append(metadata.relations, relation)
+
+			continue
+		}
+
+		if structField.PkgPath != "" || structField.Tag.Get("db") == "-" {
+			continue
+		}
+
+		field := modelField{
+			index:     index,
+			column:    getColumnName(structField),
+			fieldType: structField.Type,
+		}
+
+		metadata.fields = append(metadata.fields, field)
+
+		if _, ok := tagOptions[miniormTagOptionPrimaryKey]; ok {
+			metadata.primaryKeyFields = append(metadata.primaryKeyFields, field)
+
+			_, isAutoIncrement := tagOptions[miniormTagOptionAutoIncrement]
+			_, isGenerated := tagOptions[miniormTagOptionGenerated]
+
+			if (isAutoIncrement && isIntKind(field.fieldType.Kind())) || isGenerated {
+				metadata.generatedKeyField = &field
+			}
+		}
+
+		if _, ok := tagOptions[miniormTagOptionVersion]; ok && isIntKind(field.fieldType.Kind()) && metadata.versionField == nil {
+			metadata.versionField = &field
+		}
+
+		if _, ok := tagOptions[miniormTagOptionSoftDelete]; ok && metadata.softDeleteField == nil {
+			metadata.softDeleteField = &field
+		}
+
+		if _, ok := tagOptions[miniormTagOptionSensitive]; ok {
+			metadata.sensitiveFields = append(metadata.sensitiveFields, field)
+		}
+	}
+}
+
+// getMiniormTagOptions parses the miniorm tag of structField, e.g. `miniorm:"pk,autoincrement"` or
+// `miniorm:"table=entries"`, into a map of options to their values
+func getMiniormTagOptions(structField reflect.StructField) map[string]string {
+	tagOptions := make(map[string]string)
+
+	tag, ok := structField.Tag.Lookup(miniormTagName)
+	if !ok {
+		return tagOptions
+	}
+
+	for _, tagOption := range strings.Split(tag, ",") {
+		option, value := strings.TrimSpace(tagOption), ""
+		if separatorPosition := strings.Index(option, "="); separatorPosition >= 0 {
+			option, value = strings.TrimSpace(option[:separatorPosition]), strings.TrimSpace(option[separatorPosition+1:])
+		}
+
+		if option != "" {
+			tagOptions[option] = value
+		}
+	}
+
+	return tagOptions
+}
+
+// getTagRelation returns the relation declared by the tag options of structField, e.g.
+// `miniorm:"hasmany,fk=parent_id"` or `miniorm:"belongsto,fk=parent_id,references=id"`
+func getTagRelation(structField reflect.StructField, tagOptions map[string]string) (Relation, bool) {
+	relation := Relation{
+		Field:      structField.Name,
+		ForeignKey: tagOptions[miniormTagOptionForeignKey],
+		References: tagOptions[miniormTagOptionReferences],
+	}
+
+	if _, ok := tagOptions[miniormTagOptionHasMany]; ok {
+		relation.Type = RelationTypeHasMany
+	} else if _, ok := tagOptions[miniormTagOptionBelongsTo]; ok {
+		relation.Type = RelationTypeBelongsTo
+	}
+
+	return relation, relation.Type != "" && relation.ForeignKey != "" && structField.PkgPath == ""
+}
+
+// getColumnName returns the column name of structField, which is taken from the db tag like goqu does
+func getColumnName(structField reflect.StructField) string {
+	if column := structField.Tag.Get("db"); column != "" {
+		return column
+	}
+
+	return strings.ToLower(structField.Name)
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,112 @@
+package miniorm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetModelMetadata(t *testing.T) {
+	t.Parallel()
+
+	metadata, _, ok := getModelMetadata(&taggedIDEntry{})
+	assert.True(t, ok)
+	assert.Equal(t, "get_id_entries", metadata.tableName)
+	assert.Equal(t, []modelField{
+		{index: []int{1}, column: "id", fieldType: reflect.TypeOf(int64(0))},
+	}, metadata.primaryKeyFields)
+	assert.Equal(t, &metadata.primaryKeyFields[0], metadata.generatedKeyField)
+	assert.Equal(t, &modelField{index: []int{6}, column: "version", fieldType: reflect.TypeOf(int64(0))}, metadata.versionField)
+	assert.Nil(t, metadata.softDeleteField)
+	assert.Len(t, metadata.fields, 6)
+
+	cachedMetadata, _, ok := getModelMetadata(taggedIDEntry{})
+	assert.True(t, ok)
+	assert.Same(t, metadata, cachedMetadata)
+
+	metadata, _, ok = getModelMetadata(&taggedUniqueEntry{})
+	assert.True(t, ok)
+	assert.Equal(t, "get_unique_entries", metadata.tableName)
+	assert.Equal(t, []modelField{
+		{index: []int{1, 0}, column: "id_1", fieldType: reflect.TypeOf(int64(0))},
+		{index: []int{1, 1}, column: "id_2", fieldType: reflect.TypeOf(int64(0))},
+	}, metadata.primaryKeyFields)
+	assert.Nil(t, metadata.generatedKeyField)
+
+	metadata, _, ok = getModelMetadata(&struct {
+		ID        string `db:"id" miniorm:"pk, autoincrement"`
+		Version   string `miniorm:"version"`
+		DeletedAt *int64 `miniorm:"softdelete"`
+		Ignored   int64  `db:"-" miniorm:"pk"`
+	}{})
+	assert.True(t, ok)
+	assert.Empty(t, metadata.tableName)
+	assert.Len(t, metadata.primaryKeyFields, 1)
+	assert.Nil(t, metadata.generatedKeyField)
+	assert.Nil(t, metadata.versionField)
+	assert.Equal(t, "deletedat", metadata.softDeleteField.column)
+
+	testCaseList := []interface{}{
+		nil,
+		1,
+		"string",
+		(*taggedIDEntry)(nil),
+	}
+
+	for _, testCase := range testCaseList {
+		metadata, _, ok := getModelMetadata(testCase)
+		assert.Nil(t, metadata)
+		assert.False(t, ok)
+	}
+}
+
+func TestEntryInfoProviderTaggedModel(t *testing.T) {
+	t.Parallel()
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	entry := &taggedIDEntry{ID: 3}
+	tableName, err := entryInfoProvider.GetEntryTableName(entry)
+	assert.Nil(t, err)
+	assert.Equal(t, getIDEntryTableName, tableName)
+
+	idColumn, idValue, err := entryInfoProvider.GetID(entry)
+	assert.Nil(t, err)
+	assert.Equal(t, getIDEntryIDColumnName, idColumn)
+	assert.Equal(t, int64(3), idValue)
+
+	keyColumn, keyDestination, ok := entryInfoProvider.GetKeyDestination(entry)
+	assert.True(t, ok)
+	assert.Equal(t, getIDEntryIDColumnName, keyColumn)
+	assert.Equal(t, &entry.ID, keyDestination)
+
+	_, _, ok = entryInfoProvider.GetKeyDestination(taggedIDEntry{})
+	assert.False(t, ok)
+
+	uniqueColumns, ok := entryInfoProvider.GetUniqueColumns(entry)
+	assert.Nil(t, uniqueColumns)
+	assert.False(t, ok)
+
+	uniqueEntry := &taggedUniqueEntry{taggedBaseEntry: taggedBaseEntry{ID1: 1, ID2: 2}}
+	expression, err := entryInfoProvider.GetEntrySelectExpression(uniqueEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, goqu.Ex{getUniqueEntryID1ColumnName: int64(1), getUniqueEntryID2ColumnName: int64(2)}, expression)
+
+	uniqueColumns, ok = entryInfoProvider.GetUniqueColumns(uniqueEntry)
+	assert.Equal(t, []string{getUniqueEntryID1ColumnName, getUniqueEntryID2ColumnName}, uniqueColumns)
+	assert.True(t, ok)
+
+	_, _, ok = entryInfoProvider.GetKeyDestination(uniqueEntry)
+	assert.False(t, ok)
+
+	// Interfaces take precedence over tags
+	interfaceEntry := &struct {
+		getIDEntry
+		Name string `db:"name" miniorm:"pk"`
+	}{getIDEntry: getIDEntry{ID: 5}}
+	expression, err = entryInfoProvider.GetEntrySelectExpression(interfaceEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, goqu.Ex{getIDEntryIDColumnName: int64(5)}, expression)
+}
@@ -1,16 +1,39 @@
 package miniorm
 
-import "github.com/doug-martin/goqu/v9"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+var (
+	errHookFailed = errors.New("hook failed")
+)
 
 const (
 	getIDEntryTableName               = "get_id_entries"
 	getIDEntryIDColumnName            = "id"
 	getIDEntryOnCreateCountColumnName = "on_create_count"
+	getIDEntryOnUpdateCountColumnName = "on_update_count"
 
 	getUniqueEntryTableName     = "get_unique_entries"
 	getUniqueEntryID1ColumnName = "id_1"
 	getUniqueEntryID2ColumnName = "id_2"
 
+	getKeyEntryTableName    = "get_key_entries"
+	getKeyEntryIDColumnName = "id"
+
+	getChildEntryTableName          = "get_child_entries"
+	getChildEntryParentIDColumnName = "parent_id"
+
+	ddlEntryTableName      = "ddl_entries"
+	ddlEntryCodeColumnName = "code"
+
+	schemaMissingTableEntryTableName = "schema_missing_table_entries"
+
 	testfixturesDefaultSequenceStart = 10000
 )
 
@@ -143,3 +166,348 @@ type tableNameGetterWithoutUniqueSelector struct{}
 func (entry *tableNameGetterWithoutUniqueSelector) GetTableName() string {
 	return ""
 }
+
+type versionedIDEntry struct {
+	ID            int64  `db:"id" goqu:"skipinsert,skipupdate"`
+	StringCol     string `db:"string_col"`
+	BytesCol      []byte `db:"bytes_col"`
+	OnCreateCount int64  `db:"on_create_count" goqu:"skipupdate"`
+	OnUpdateCount int64  `db:"on_update_count"`
+	Version       int64  `db:"version" miniorm:"version"`
+}
+
+func (entry *versionedIDEntry) GetTableName() string {
+	return getIDEntryTableName
+}
+
+func (entry *versionedIDEntry) GetID() (string, int64) {
+	return getIDEntryIDColumnName, entry.ID
+}
+
+func (entry *versionedIDEntry) SetID(id int64) {
+	entry.ID = id
+}
+
+func (entry *versionedIDEntry) OnUpdate() {
+	entry.OnUpdateCount++
+}
+
+type versionedUniqueEntry struct {
+	ID1           int64  `db:"id_1" goqu:"skipupdate"`
+	ID2           int64  `db:"id_2" goqu:"skipupdate"`
+	StringCol     string `db:"string_col"`
+	BytesCol      []byte `db:"bytes_col"`
+	OnCreateCount int64  `db:"on_create_count" goqu:"skipupdate"`
+	OnUpdateCount int64  `db:"on_update_count"`
+	Revision      int64  `db:"version"`
+}
+
+func (entry *versionedUniqueEntry) GetTableName() string {
+	return getUniqueEntryTableName
+}
+
+func (entry *versionedUniqueEntry) GetUniqueExpression() goqu.Ex {
+	return goqu.Ex{
+		getUniqueEntryID1ColumnName: entry.ID1,
+		getUniqueEntryID2ColumnName: entry.ID2,
+	}
+}
+
+func (entry *versionedUniqueEntry) GetVersion() (string, int64) {
+	return "version", entry.Revision
+}
+
+func (entry *versionedUniqueEntry) SetVersion(version int64) {
+	entry.Revision = version
+}
+
+func (entry *versionedUniqueEntry) OnCreate() {
+	entry.OnCreateCount++
+}
+
+func (entry *versionedUniqueEntry) OnUpdate() {
+	entry.OnUpdateCount++
+}
+
+type softDeleteIDEntry struct {
+	ID            int64  `db:"id" goqu:"skipinsert,skipupdate"`
+	StringCol     string `db:"string_col"`
+	BytesCol      []byte `db:"bytes_col"`
+	OnCreateCount int64  `db:"on_create_count" goqu:"skipupdate"`
+	OnUpdateCount int64  `db:"on_update_count"`
+	DeletedAt     *int64 `db:"deleted_at" miniorm:"softdelete"`
+}
+
+func (entry *softDeleteIDEntry) GetTableName() string {
+	return getIDEntryTableName
+}
+
+func (entry *softDeleteIDEntry) GetID() (string, int64) {
+	return getIDEntryIDColumnName, entry.ID
+}
+
+func (entry *softDeleteIDEntry) SetID(id int64) {
+	entry.ID = id
+}
+
+type softDeleteUniqueEntry struct {
+	ID1           int64  `db:"id_1" goqu:"skipupdate"`
+	ID2           int64  `db:"id_2" goqu:"skipupdate"`
+	StringCol     string `db:"string_col"`
+	BytesCol      []byte `db:"bytes_col"`
+	OnCreateCount int64  `db:"on_create_count" goqu:"skipupdate"`
+	OnUpdateCount int64  `db:"on_update_count"`
+	RemovedAt     *int64 `db:"deleted_at"`
+}
+
+func (entry *softDeleteUniqueEntry) GetTableName() string {
+	return getUniqueEntryTableName
+}
+
+func (entry *softDeleteUniqueEntry) GetUniqueExpression() goqu.Ex {
+	return goqu.Ex{
+		getUniqueEntryID1ColumnName: entry.ID1,
+		getUniqueEntryID2ColumnName: entry.ID2,
+	}
+}
+
+func (entry *softDeleteUniqueEntry) GetSoftDeleteColumn() string {
+	return "deleted_at"
+}
+
+func (entry *softDeleteUniqueEntry) SetDeletedAt(deletedAt time.Time) {
+	removedAt := deletedAt.Unix()
+	entry.RemovedAt = &removedAt
+}
+
+type taggedIDEntry struct {
+	_             struct{} `miniorm:"table=get_id_entries"`
+	ID            int64    `db:"id" goqu:"skipinsert,skipupdate" miniorm:"pk,autoincrement"`
+	StringCol     string   `db:"string_col"`
+	BytesCol      []byte   `db:"bytes_col"`
+	OnCreateCount int64    `db:"on_create_count" goqu:"skipupdate"`
+	OnUpdateCount int64    `db:"on_update_count"`
+	Version       int64    `db:"version" miniorm:"version"`
+}
+
+type taggedBaseEntry struct {
+	ID1 int64 `db:"id_1" goqu:"skipupdate" miniorm:"pk"`
+	ID2 int64 `db:"id_2" goqu:"skipupdate" miniorm:"pk"`
+}
+
+type taggedUniqueEntry struct {
+	_ struct{} `miniorm:"table=get_unique_entries"`
+	taggedBaseEntry
+	StringCol     string `db:"string_col"`
+	BytesCol      []byte `db:"bytes_col"`
+	OnCreateCount int64  `db:"on_create_count" goqu:"skipupdate"`
+	OnUpdateCount int64  `db:"on_update_count"`
+}
+
+type getKeyEntry struct {
+	ID        string `db:"id" goqu:"skipinsert,skipupdate"`
+	StringCol string `db:"string_col"`
+}
+
+func (entry *getKeyEntry) GetTableName() string {
+	return getKeyEntryTableName
+}
+
+func (entry *getKeyEntry) GetKey() (string, interface{}) {
+	return getKeyEntryIDColumnName, entry.ID
+}
+
+func (entry *getKeyEntry) GetKeyDestination() (string, interface{}) {
+	return getKeyEntryIDColumnName, &entry.ID
+}
+
+type taggedKeyEntry struct {
+	_         struct{} `miniorm:"table=get_key_entries"`
+	ID        string   `db:"id" goqu:"skipupdate" miniorm:"pk"`
+	StringCol string   `db:"string_col"`
+}
+
+type onCreateCountGroup struct {
+	OnCreateCount int64 `db:"on_create_count"`
+	EntryCount    int64 `db:"entry_count"`
+}
+
+type getParentEntry struct {
+	_         struct{}         `miniorm:"table=get_id_entries"`
+	ID        int64            `db:"id" goqu:"skipinsert,skipupdate" miniorm:"pk,autoincrement"`
+	StringCol string           `db:"string_col"`
+	Children  []*getChildEntry `db:"-" miniorm:"hasmany,fk=parent_id"`
+}
+
+type getChildEntry struct {
+	_         struct{}        `miniorm:"table=get_child_entries"`
+	ID        int64           `db:"id" goqu:"skipupdate" miniorm:"pk"`
+	ParentID  int64           `db:"parent_id"`
+	StringCol string          `db:"string_col"`
+	Parent    *getParentEntry `db:"-" miniorm:"belongsto,fk=parent_id"`
+}
+
+type getParentEntryWithRelations struct {
+	ID        int64           `db:"id" goqu:"skipinsert,skipupdate"`
+	StringCol string          `db:"string_col"`
+	Children  []getChildEntry `db:"-"`
+}
+
+func (entry *getParentEntryWithRelations) GetTableName() string {
+	return getIDEntryTableName
+}
+
+func (entry *getParentEntryWithRelations) GetID() (string, int64) {
+	return getIDEntryIDColumnName, entry.ID
+}
+
+func (entry *getParentEntryWithRelations) GetRelations() []Relation {
+	return []Relation{
+		{Field: "Children", Type: RelationTypeHasMany, ForeignKey: getChildEntryParentIDColumnName},
+	}
+}
+
+type ddlEntry struct {
+	_          struct{}      `miniorm:"table=ddl_entries"`
+	ID         int64         `db:"id" goqu:"skipinsert,skipupdate" miniorm:"pk,autoincrement"`
+	Code       string        `db:"code"`
+	BoolCol    bool          `db:"bool_col"`
+	Int32Col   int32         `db:"int32_col"`
+	FloatCol   float64       `db:"float_col"`
+	StringCol  string        `db:"string_col"`
+	BytesCol   []byte        `db:"bytes_col"`
+	NullIntCol sql.NullInt64 `db:"null_int_col"`
+	TimeCol    *time.Time    `db:"time_col"`
+}
+
+func (entry *ddlEntry) GetUniqueExpression() goqu.Ex {
+	return goqu.Ex{ddlEntryCodeColumnName: entry.Code}
+}
+
+type schemaDriftEntry struct {
+	ID            int64  `db:"id" goqu:"skipinsert,skipupdate"`
+	StringCol     int64  `db:"string_col"`
+	MissingCol    string `db:"missing_col"`
+	OnCreateCount int64  `db:"on_create_count"`
+	OnUpdateCount int64  `db:"on_update_count"`
+}
+
+func (entry *schemaDriftEntry) GetTableName() string {
+	return getIDEntryTableName
+}
+
+func (entry *schemaDriftEntry) GetUniqueExpression() goqu.Ex {
+	return goqu.Ex{getIDEntryOnCreateCountColumnName: entry.OnCreateCount}
+}
+
+type schemaMissingTableEntry struct {
+	ID int64 `db:"id"`
+}
+
+func (entry *schemaMissingTableEntry) GetTableName() string {
+	return schemaMissingTableEntryTableName
+}
+
+// hookEntry records the lifecycle hooks called on it, fails the hook named FailingHook after running it, and keeps a
+// child entry with the same ID in sync
+type hookEntry struct {
+	ID            int64    `db:"id" goqu:"skipinsert,skipupdate"`
+	StringCol     string   `db:"string_col"`
+	BytesCol      []byte   `db:"bytes_col"`
+	OnCreateCount int64    `db:"on_create_count" goqu:"skipupdate"`
+	OnUpdateCount int64    `db:"on_update_count"`
+	Hooks         []string `db:"-"`
+	FailingHook   string   `db:"-"`
+}
+
+func (entry *hookEntry) GetTableName() string {
+	return getIDEntryTableName
+}
+
+func (entry *hookEntry) GetID() (string, int64) {
+	return getIDEntryIDColumnName, entry.ID
+}
+
+func (entry *hookEntry) SetID(id int64) {
+	entry.ID = id
+}
+
+func (entry *hookEntry) runHook(hook string) error {
+	entry.Hooks = append(entry.Hooks, hook)
+
+	if entry.FailingHook == hook {
+		return errHookFailed
+	}
+
+	return nil
+}
+
+func (entry *hookEntry) BeforeCreate(ctx context.Context, orm ORM) error {
+	return entry.runHook("BeforeCreate")
+}
+
+func (entry *hookEntry) AfterCreate(ctx context.Context, orm ORM) error {
+	if err := orm.Create(ctx, &getChildEntry{ID: entry.ID, ParentID: entry.ID, StringCol: entry.StringCol}); err != nil {
+		return err
+	}
+
+	return entry.runHook("AfterCreate")
+}
+
+func (entry *hookEntry) BeforeUpdate(ctx context.Context, orm ORM) error {
+	return entry.runHook("BeforeUpdate")
+}
+
+func (entry *hookEntry) AfterUpdate(ctx context.Context, orm ORM) error {
+	if _, err := orm.UpdateWhere(
+		ctx,
+		getChildEntryTableName,
+		goqu.Ex{getChildEntryParentIDColumnName: entry.ID},
+		goqu.Record{"string_col": entry.StringCol},
+	); err != nil {
+		return err
+	}
+
+	return entry.runHook("AfterUpdate")
+}
+
+func (entry *hookEntry) BeforeDelete(ctx context.Context, orm ORM) error {
+	return entry.runHook("BeforeDelete")
+}
+
+func (entry *hookEntry) AfterDelete(ctx context.Context, orm ORM) error {
+	if _, err := orm.DeleteWhere(ctx, getChildEntryTableName, goqu.Ex{getChildEntryParentIDColumnName: entry.ID}); err != nil {
+		return err
+	}
+
+	return entry.runHook("AfterDelete")
+}
+
+func (entry *hookEntry) AfterLoad(ctx context.Context, orm ORM) error {
+	return entry.runHook("AfterLoad")
+}
+
+// sensitiveEntry declares its bytes_col column as sensitive, which redacts it from the logs of its table
+type sensitiveEntry struct {
+	ID            int64  `db:"id" goqu:"skipinsert,skipupdate" miniorm:"pk,autoincrement"`
+	StringCol     string `db:"string_col"`
+	BytesCol      []byte `db:"bytes_col" miniorm:"sensitive"`
+	OnCreateCount int64  `db:"on_create_count" goqu:"skipupdate"`
+	OnUpdateCount int64  `db:"on_update_count"`
+}
+
+func (entry *sensitiveEntry) GetTableName() string {
+	return getIDEntryTableName
+}
+
+// uniqueKeyEntry has both a generated key and a unique column, so an upsert has to read the key of an updated row
+type uniqueKeyEntry struct {
+	_         struct{} `miniorm:"table=unique_key_entries"`
+	ID        int64    `db:"id" goqu:"skipinsert,skipupdate" miniorm:"pk,autoincrement"`
+	Name      string   `db:"name" goqu:"skipupdate"`
+	StringCol string   `db:"string_col"`
+}
+
+func (entry *uniqueKeyEntry) GetUniqueExpression() goqu.Ex {
+	return goqu.Ex{"name": entry.Name}
+}
@@ -3,54 +3,120 @@ package miniorm
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exec"
 	"github.com/doug-martin/goqu/v9/exp"
 )
 
+// Column of the rows inserted by createChunk holding their ordinal in the chunk
+const mssqlOrdinalColumn = "miniorm_ordinal"
+
 type MSSQLORM struct {
 	db                   DBWrapper
+	pool                 connectionPool
+	ownsPool             bool
+	replicas             *replicaSet
 	entryInfoProvider    *entryInfoProvider
+	redactor             *logRedactor
+	databaseConfig       DatabaseConfig
 	insertIntoTableRegex *regexp.Regexp
 	fromTableRegex       *regexp.Regexp
+	savepointDepth       uint
+	unscoped             bool
+	preloads             []string
 }
 
 func NewMSSQLORM(databaseConfig DatabaseConfig) (ORM, error) {
-	goquDB, err := newGoquDatabase(databaseConfig)
+	goquDB, sqlDB, err := newGoquDatabase(databaseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	orm, err := newMSSQLORM(goquDB, sqlDB, true, databaseConfig)
+	if err != nil {
+		_ = sqlDB.Close()
+	}
+
+	return orm, err
+}
+
+// NewMSSQLORMFromSQLDatabase is NewORMFromSQLDatabase for MSSQL
+func NewMSSQLORMFromSQLDatabase(db *sql.DB, databaseConfig DatabaseConfig) (ORM, error) {
+	databaseConfig.Driver = DriverTypeMSSQL
+
+	if err := waitForDatabase(db, databaseConfig); err != nil {
+		return nil, err
+	}
+
+	return newMSSQLORM(newGoquDatabaseFromSQLDatabase(db, databaseConfig), db, false, databaseConfig)
+}
+
+// NewMSSQLORMFromGoquDatabase is NewORMFromGoquDatabase for MSSQL
+func NewMSSQLORMFromGoquDatabase(goquDB *goqu.Database, databaseConfig DatabaseConfig) (ORM, error) {
+	databaseConfig.Driver = DriverTypeMSSQL
+
+	pool, err := getGoquConnectionPool(goquDB, databaseConfig.Driver)
 	if err != nil {
 		return nil, err
 	}
 
-	goquDB.Logger(databaseConfig.Logger)
+	if err := waitForDatabase(pool, databaseConfig); err != nil {
+		return nil, err
+	}
+
+	return newMSSQLORM(instrumentGoquDatabase(goquDB, databaseConfig), pool, false, databaseConfig)
+}
+
+func newMSSQLORM(goquDB *goqu.Database, pool connectionPool, ownsPool bool, databaseConfig DatabaseConfig) (ORM, error) {
+	replicas, err := newReplicaSet(databaseConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	return &MSSQLORM{
+	return newInstrumentedORM(&MSSQLORM{
 		db:                   goquDB,
-		entryInfoProvider:    newEntryInfoProvider(),
+		pool:                 pool,
+		ownsPool:             ownsPool,
+		replicas:             replicas,
+		entryInfoProvider:    newEntryInfoProvider(databaseConfig.Models...),
+		redactor:             newLogRedactor(databaseConfig),
+		databaseConfig:       databaseConfig,
 		insertIntoTableRegex: regexp.MustCompile(`INSERT INTO "[^"]+"\s*\(("[^"]+",\s*)*("[^"]+")\)`),
 		fromTableRegex:       regexp.MustCompile(`FROM\s+"[^"]+"`),
-	}, nil
+	}, databaseConfig), nil
 }
 
 // HACK: Since goqu does not support MSSQL's OUTPUT syntax, we have to manually add that
-func (orm *MSSQLORM) wrapInsertSQLStatementWithOutputIDColumn(statement, idColumn string) string {
+func (orm *MSSQLORM) wrapInsertSQLStatementWithOutputKeyColumn(statement, keyColumn string) string {
 	insertIntoTablePosition := orm.insertIntoTableRegex.FindStringIndex(statement)
 	if insertIntoTablePosition == nil {
 		return statement
 	}
 
 	return statement[:insertIntoTablePosition[1]] +
-		fmt.Sprintf(" OUTPUT INSERTED.%s", idColumn) +
+		fmt.Sprintf(" OUTPUT INSERTED.%s", keyColumn) +
 		statement[insertIntoTablePosition[1]:]
 }
 
-func (orm *MSSQLORM) Create(ctx context.Context, entry interface{}) error {
+func (orm *MSSQLORM) Create(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
 
+	return withCreateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*MSSQLORM).create(ctx, entry)
+	})
+}
+
+func (orm *MSSQLORM) create(ctx context.Context, entry interface{}) error {
 	orm.entryInfoProvider.OnCreateIfEntryIsOnCreator(entry)
 
 	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
@@ -58,6 +124,8 @@ func (orm *MSSQLORM) Create(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, entry)
+
 	sqlStatement, params, err := orm.GetDBWrapper().
 		Insert(entryTableName).
 		Prepared(true).
@@ -67,38 +135,227 @@ func (orm *MSSQLORM) Create(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
-	var (
-		idColumn string
-		idValue  int64
-	)
+	keyColumn, keyDestination, isKeySetterEntry := orm.entryInfoProvider.GetKeyDestination(entry)
+	if isKeySetterEntry {
+		if keyColumn == "" {
+			return ErrKeyGetterExpected
+		}
 
-	idSetterEntry, isIDSetterEntry := entry.(IDSetter)
-	if isIDSetterEntry {
-		idColumn, _, err = orm.entryInfoProvider.GetID(entry)
-		if err != nil {
+		sqlStatement = orm.wrapInsertSQLStatementWithOutputKeyColumn(sqlStatement, keyColumn)
+
+		return orm.scanInsertedKey(ctx, orm.GetDBWrapper(), sqlStatement, params, keyDestination)
+	}
+
+	_, err = orm.GetDBWrapper().ExecContext(ctx, sqlStatement, params...)
+
+	return err
+}
+
+// scanInsertedKey runs an INSERT statement with an OUTPUT clause for a single row, and scans the key of the inserted
+// row into keyDestination
+func (orm *MSSQLORM) scanInsertedKey(
+	ctx context.Context,
+	db DBWrapper,
+	sqlStatement string,
+	params []interface{},
+	keyDestination interface{},
+) error {
+	rows, err := db.QueryContext(ctx, sqlStatement, params...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
 			return err
 		}
 
-		sqlStatement = orm.wrapInsertSQLStatementWithOutputIDColumn(sqlStatement, idColumn)
+		return ErrNotFound
 	}
 
-	rows, err := orm.GetDBWrapper().QueryContext(ctx, sqlStatement, params...)
+	if err := rows.Scan(keyDestination); err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
+
+func (orm *MSSQLORM) CreateMany(ctx context.Context, entries interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	return createMany(ctx, orm, orm.entryInfoProvider, orm.databaseConfig, entries, orm.createChunk)
+}
+
+// createChunk inserts entryList in a single MERGE, matching the generated keys with the entries by row ordinal
+func (orm *MSSQLORM) createChunk(ctx context.Context, db DBWrapper, tableName string, entryList []interface{}) error {
+	ctx = orm.redactor.bindEntries(ctx, tableName, entryList...)
+
+	keyColumn, keyDestination, isKeySetterEntry := orm.entryInfoProvider.GetKeyDestination(entryList[0])
+	if !isKeySetterEntry {
+		_, err := db.Insert(tableName).Prepared(true).Rows(entryList...).Executor().ExecContext(ctx)
+		return err
+	}
+
+	if keyColumn == "" {
+		return ErrKeyGetterExpected
+	}
+
+	if _, err := getIntegerKeySetter(keyDestination); err != nil {
+		// Keys other than integers, e.g. from NEWID(), are scanned one by one
+		return orm.createChunkOneByOne(ctx, db, tableName, entryList)
+	}
+
+	sqlStatement, params, ok, err := orm.buildMergeSQLStatementWithOutputOrdinal(db, tableName, keyColumn, entryList)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return orm.createChunkOneByOne(ctx, db, tableName, entryList)
+	}
+
+	rows, err := db.QueryContext(ctx, sqlStatement, params...)
 	if err != nil {
 		return err
 	}
 
 	defer rows.Close()
 
-	if isIDSetterEntry {
-		if !rows.Next() {
+	rowCount := 0
+
+	for rows.Next() {
+		var ordinal, idValue int64
+		if err := rows.Scan(&ordinal, &idValue); err != nil {
+			return err
+		}
+
+		if ordinal < 0 || ordinal >= int64(len(entryList)) {
 			return ErrNotFound
 		}
 
-		if err := rows.Scan(&idValue); err != nil {
+		_, keyDestination, _ := orm.entryInfoProvider.GetKeyDestination(entryList[ordinal])
+
+		setKey, err := getIntegerKeySetter(keyDestination)
+		if err != nil {
+			return err
+		}
+
+		if err := setKey(idValue); err != nil {
+			return err
+		}
+
+		rowCount++
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if rowCount != len(entryList) {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// buildMergeSQLStatementWithOutputOrdinal builds a MERGE inserting entryList, outputting the ordinal and key of rows
+func (orm *MSSQLORM) buildMergeSQLStatementWithOutputOrdinal(
+	db DBWrapper,
+	tableName string,
+	keyColumn string,
+	entryList []interface{},
+) (sqlStatement string, params []interface{}, ok bool, err error) {
+	recordList := make([]interface{}, 0, len(entryList))
+
+	var columns []string
+
+	for i, entry := range entryList {
+		record, err := orm.entryInfoProvider.GetInsertRecord(entry)
+		if err != nil {
+			return "", nil, false, err
+		}
+
+		if len(record) == 0 {
+			return "", nil, false, nil
+		}
+
+		if i == 0 {
+			for column := range record {
+				columns = append(columns, column)
+			}
+		}
+
+		for _, value := range record {
+			if literal, isLiteral := value.(exp.LiteralExpression); isLiteral && literal.Literal() == exp.Default().Literal() {
+				return "", nil, false, nil
+			}
+		}
+
+		// The ordinal is a literal, so that it does not count toward the parameter limit
+		record[mssqlOrdinalColumn] = goqu.L(fmt.Sprint(i))
+		recordList = append(recordList, record)
+	}
+
+	insertStatement, params, err := db.Insert(tableName).Prepared(true).Rows(recordList...).ToSQL()
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	// HACK: Since goqu does not support MERGE, the table, the columns and the VALUES of the INSERT are reused
+	insertIntoTablePosition := orm.insertIntoTableRegex.FindStringIndex(insertStatement)
+	if insertIntoTablePosition == nil {
+		return "", nil, false, nil
+	}
+
+	tableAndSourceColumns := strings.TrimPrefix(insertStatement[:insertIntoTablePosition[1]], "INSERT INTO ")
+	tableEnd := strings.Index(tableAndSourceColumns[1:], `"`) + 2
+	values := insertStatement[insertIntoTablePosition[1]:]
+
+	sort.Strings(columns)
+
+	quotedColumns := make([]string, 0, len(columns))
+	sourceColumns := make([]string, 0, len(columns))
+
+	for _, column := range columns {
+		quotedColumn := `"` + strings.ReplaceAll(column, `"`, `""`) + `"`
+		quotedColumns = append(quotedColumns, quotedColumn)
+		sourceColumns = append(sourceColumns, "miniorm_source."+quotedColumn)
+	}
+
+	sqlStatement = fmt.Sprintf(
+		"MERGE INTO %s USING (%s) AS miniorm_source %s ON 1 = 0 WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s) "+
+			"OUTPUT miniorm_source.%s, INSERTED.%s;",
+		tableAndSourceColumns[:tableEnd],
+		strings.TrimSpace(values),
+		strings.TrimSpace(tableAndSourceColumns[tableEnd:]),
+		strings.Join(quotedColumns, ", "),
+		strings.Join(sourceColumns, ", "),
+		mssqlOrdinalColumn,
+		keyColumn,
+	)
+
+	return sqlStatement, params, true, nil
+}
+
+func (orm *MSSQLORM) createChunkOneByOne(ctx context.Context, db DBWrapper, tableName string, entryList []interface{}) error {
+	for _, entry := range entryList {
+		sqlStatement, params, err := db.
+			Insert(tableName).
+			Prepared(true).
+			Rows(entry).
+			ToSQL()
+		if err != nil {
 			return err
 		}
 
-		idSetterEntry.SetID(idValue)
+		keyColumn, keyDestination, _ := orm.entryInfoProvider.GetKeyDestination(entry)
+		sqlStatement = orm.wrapInsertSQLStatementWithOutputKeyColumn(sqlStatement, keyColumn)
+
+		if err := orm.scanInsertedKey(ctx, db, sqlStatement, params, keyDestination); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -114,32 +371,47 @@ func (orm *MSSQLORM) wrapSelectSQLStatementWithRowLock(statement string) string
 	return statement[:fromTablePosition[1]] + " WITH (XLOCK, ROWLOCK)" + statement[fromTablePosition[1]:]
 }
 
-func (orm *MSSQLORM) CreateOrUpdate(ctx context.Context, entry interface{}) error {
+func (orm *MSSQLORM) CreateOrUpdate(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
 
-	return orm.WithTx(func(txORM ORM) error {
+	// The hooks of entry depend on whether it is created or updated, which the upsert statements cannot tell beforehand
+	if orm.databaseConfig.CreateOrUpdateMode == CreateOrUpdateModeUpsert && !hasCreateOrUpdateHooks(entry) {
+		if uniqueColumns, ok := orm.entryInfoProvider.GetUniqueColumns(entry); ok {
+			if createEntry, updateEntry, ok := orm.entryInfoProvider.GetUpsertEntries(entry); ok {
+				return orm.upsert(ctx, entry, createEntry, updateEntry, uniqueColumns)
+			}
+		}
+	}
+
+	return orm.WithTxContext(ctx, nil, func(txCtx context.Context, txORM ORM) error {
 		entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
 		if err != nil {
 			return err
 		}
 
-		selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
+		// Soft deleted rows are included, so that they are restored by the update rather than conflicting with the insert
+		selectEntryExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
 		if err != nil {
 			return err
 		}
 
+		txCtx = orm.redactor.bindExpression(txCtx, entryTableName, selectEntryExpression)
+
 		sqlStatement, params, err := txORM.GetDBWrapper().
 			Select().
 			From(entryTableName).
-			Where(selectEntryUniqueExpression).
+			Prepared(true).
+			Where(selectEntryExpression).
 			ToSQL()
 		if err != nil {
 			return err
 		}
 
-		rows, err := txORM.GetDBWrapper().QueryContext(ctx, orm.wrapSelectSQLStatementWithRowLock(sqlStatement), params...)
+		rows, err := txORM.GetDBWrapper().QueryContext(txCtx, orm.wrapSelectSQLStatementWithRowLock(sqlStatement), params...)
 		if err != nil {
 			return err
 		}
@@ -152,14 +424,184 @@ func (orm *MSSQLORM) CreateOrUpdate(ctx context.Context, entry interface{}) erro
 		}
 
 		if !rowExists {
-			return txORM.Create(ctx, entry)
+			return txORM.Create(txCtx, entry)
 		}
 
-		return txORM.Update(ctx, entry)
+		return txORM.Update(txCtx, entry)
 	})
 }
 
-func (orm *MSSQLORM) Delete(ctx context.Context, entry interface{}) error {
+// HACK: Since goqu does not support MSSQL's MERGE syntax, we have to build the statement manually
+func (orm *MSSQLORM) buildUpsertSQLStatement(
+	tableName string,
+	uniqueExpression goqu.Ex,
+	uniqueColumns []string,
+	insertRecord, updateRecord exp.Record,
+	keyColumn string,
+) (string, []interface{}) {
+	params := make([]interface{}, 0, len(uniqueColumns)+len(insertRecord)+len(updateRecord))
+	addParam := func(value interface{}) string {
+		params = append(params, value)
+		return fmt.Sprintf("@p%d", len(params))
+	}
+
+	sourceColumns := make([]string, 0, len(uniqueColumns))
+	matchConditions := make([]string, 0, len(uniqueColumns))
+
+	for _, column := range uniqueColumns {
+		sourceColumns = append(sourceColumns, fmt.Sprintf(`%s AS "%s"`, addParam(uniqueExpression[column]), column))
+		matchConditions = append(matchConditions, fmt.Sprintf(`"target"."%s" = "source"."%s"`, column, column))
+	}
+
+	updateColumns := updateRecord.Cols()
+	setClauses := make([]string, 0, len(updateColumns))
+
+	for _, column := range updateColumns {
+		setClauses = append(setClauses, fmt.Sprintf(`"%s" = %s`, column, addParam(updateRecord[column])))
+	}
+
+	if len(setClauses) == 0 {
+		// MERGE requires at least one column to be set, so we set a unique column to its own value
+		setClauses = append(setClauses, fmt.Sprintf(`"%s" = "source"."%s"`, uniqueColumns[0], uniqueColumns[0]))
+	}
+
+	insertColumns := insertRecord.Cols()
+	insertColumnNames := make([]string, 0, len(insertColumns))
+	insertValues := make([]string, 0, len(insertColumns))
+
+	for _, column := range insertColumns {
+		insertColumnNames = append(insertColumnNames, fmt.Sprintf(`"%s"`, column))
+		insertValues = append(insertValues, addParam(insertRecord[column]))
+	}
+
+	output := "$action"
+	if keyColumn != "" {
+		// The key is output twice, to be scanned into both the created and the updated entry
+		output += fmt.Sprintf(`, INSERTED."%s", INSERTED."%s"`, keyColumn, keyColumn)
+	}
+
+	statement := fmt.Sprintf(
+		`MERGE INTO "%s" WITH (HOLDLOCK) AS "target" `+
+			`USING (SELECT %s) AS "source" ON (%s) `+
+			`WHEN MATCHED THEN UPDATE SET %s `+
+			`WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s) `+
+			`OUTPUT %s;`,
+		tableName,
+		strings.Join(sourceColumns, ", "),
+		strings.Join(matchConditions, " AND "),
+		strings.Join(setClauses, ", "),
+		strings.Join(insertColumnNames, ", "),
+		strings.Join(insertValues, ", "),
+		output,
+	)
+
+	return statement, params
+}
+
+// upsert creates or updates entry in a single MERGE statement, with HOLDLOCK to prevent concurrent inserts of the
+// same row. Whether the row was inserted is read from the $action output column.
+func (orm *MSSQLORM) upsert(
+	ctx context.Context,
+	entry, createEntry, updateEntry interface{},
+	uniqueColumns []string,
+) error {
+	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
+	if err != nil {
+		return err
+	}
+
+	insertRecord, err := orm.entryInfoProvider.GetInsertRecord(createEntry)
+	if err != nil {
+		return err
+	}
+
+	updateRecord, err := orm.entryInfoProvider.GetUpdateRecord(updateEntry)
+	if err != nil {
+		return err
+	}
+
+	uniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(createEntry)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, createEntry, updateEntry)
+
+	var action string
+
+	scanDestinations := []interface{}{&action}
+
+	keyColumn, _, isKeySetterEntry := orm.entryInfoProvider.GetKeyDestination(entry)
+	if isKeySetterEntry {
+		if keyColumn == "" {
+			return ErrKeyGetterExpected
+		}
+
+		// It is only known after scanning whether createEntry or updateEntry is kept, so the key is scanned into both
+		_, createKeyDestination, _ := orm.entryInfoProvider.GetKeyDestination(createEntry)
+		_, updateKeyDestination, _ := orm.entryInfoProvider.GetKeyDestination(updateEntry)
+
+		scanDestinations = append(scanDestinations, createKeyDestination, updateKeyDestination)
+	}
+
+	sqlStatement, params := orm.buildUpsertSQLStatement(
+		entryTableName,
+		uniqueExpression,
+		uniqueColumns,
+		insertRecord,
+		updateRecord,
+		keyColumn,
+	)
+
+	rows, err := orm.GetDBWrapper().QueryContext(ctx, sqlStatement, params...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return ErrUpdateNotApplied
+	}
+
+	if err := rows.Scan(scanDestinations...); err != nil {
+		return err
+	}
+
+	if action == "INSERT" {
+		orm.entryInfoProvider.SetEntry(entry, createEntry)
+	} else {
+		orm.entryInfoProvider.SetEntry(entry, updateEntry)
+	}
+
+	return nil
+}
+
+func (orm *MSSQLORM) Delete(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	return withDeleteHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		if softDeleteColumn, ok := orm.entryInfoProvider.GetSoftDeleteColumn(entry); ok {
+			return softDelete(ctx, hookORM, orm.entryInfoProvider, entry, softDeleteColumn, orm.unscoped)
+		}
+
+		return hookORM.(*MSSQLORM).hardDelete(ctx, entry)
+	})
+}
+
+func (orm *MSSQLORM) HardDelete(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	return withDeleteHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*MSSQLORM).hardDelete(ctx, entry)
+	})
+}
+
+func (orm *MSSQLORM) hardDelete(ctx context.Context, entry interface{}) error {
 	if entry == nil {
 		return ErrNilEntry
 	}
@@ -174,11 +616,14 @@ func (orm *MSSQLORM) Delete(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
+	ctx = orm.redactor.bindExpression(ctx, entryTableName, selectEntryUniqueExpression)
+
 	result, err := orm.db.
 		Delete(entryTableName).
+		Prepared(true).
 		Where(selectEntryUniqueExpression).
 		Executor().
-		Exec()
+		ExecContext(ctx)
 	if err != nil {
 		return err
 	}
@@ -195,7 +640,9 @@ func (orm *MSSQLORM) Delete(ctx context.Context, entry interface{}) error {
 	return nil
 }
 
-func (orm *MSSQLORM) Get(ctx context.Context, entry interface{}) error {
+func (orm *MSSQLORM) Get(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
@@ -205,15 +652,18 @@ func (orm *MSSQLORM) Get(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
-	selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
+	selectEntryExpression, err := orm.entryInfoProvider.GetEntryScopedSelectExpression(entry, orm.unscoped)
 	if err != nil {
 		return err
 	}
 
-	found, err := orm.db.
+	ctx = orm.redactor.bindExpression(ctx, entryTableName, selectEntryExpression)
+
+	found, err := getReadDB(ctx, orm.db, orm.replicas).
 		Select().
 		From(entryTableName).
-		Where(selectEntryUniqueExpression).
+		Prepared(true).
+		Where(selectEntryExpression).
 		Executor().
 		ScanStructContext(ctx, entry)
 	if err != nil {
@@ -224,10 +674,12 @@ func (orm *MSSQLORM) Get(ctx context.Context, entry interface{}) error {
 		return ErrNotFound
 	}
 
-	return nil
+	return orm.afterLoad(ctx, entry)
 }
 
-func (orm *MSSQLORM) GetWithXLock(ctx context.Context, entry interface{}) error {
+func (orm *MSSQLORM) GetWithXLock(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
@@ -237,15 +689,18 @@ func (orm *MSSQLORM) GetWithXLock(ctx context.Context, entry interface{}) error
 		return err
 	}
 
-	selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
+	selectEntryExpression, err := orm.entryInfoProvider.GetEntryScopedSelectExpression(entry, orm.unscoped)
 	if err != nil {
 		return err
 	}
 
+	ctx = orm.redactor.bindExpression(ctx, entryTableName, selectEntryExpression)
+
 	sqlQuery, params, err := orm.db.
 		Select().
 		From(entryTableName).
-		Where(selectEntryUniqueExpression).
+		Prepared(true).
+		Where(selectEntryExpression).
 		Limit(1).
 		ToSQL()
 	if err != nil {
@@ -263,11 +718,33 @@ func (orm *MSSQLORM) GetWithXLock(ctx context.Context, entry interface{}) error
 		return ErrNotFound
 	}
 
-	return exec.NewScanner(rows).ScanStruct(entry)
+	if err := exec.NewScanner(rows).ScanStruct(entry); err != nil {
+		return err
+	}
+
+	// The rows have to be closed before the relations are queried on the same connection
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	return orm.afterLoad(ctx, entry)
 }
 
-func (orm *MSSQLORM) getQuerySelectDataset(params QueryParams) *goqu.SelectDataset {
-	selectDataset := orm.db.Select().From(params.TableName).Where(params.Expression).Order(params.OrderBy...)
+func (orm *MSSQLORM) getQuerySelectDataset(db DBWrapper, params QueryParams) *goqu.SelectDataset {
+	queryExpression := getQueryScopedExpression(orm.entryInfoProvider, params, orm.unscoped)
+	selectDataset := db.Select().From(params.TableName).Prepared(true).Where(queryExpression).Order(params.OrderBy...)
+
+	if len(params.Columns) > 0 {
+		selectDataset = selectDataset.Select(params.Columns...)
+	}
+
+	if len(params.GroupBy) > 0 {
+		selectDataset = selectDataset.GroupBy(params.GroupBy...)
+	}
+
+	if params.Having != nil {
+		selectDataset = selectDataset.Having(params.Having)
+	}
 
 	if params.Offset != nil {
 		selectDataset = selectDataset.Offset(uint(*params.Offset))
@@ -280,12 +757,25 @@ func (orm *MSSQLORM) getQuerySelectDataset(params QueryParams) *goqu.SelectDatas
 	return selectDataset
 }
 
-func (orm *MSSQLORM) Query(ctx context.Context, params QueryParams) error {
-	return orm.getQuerySelectDataset(params).ScanStructsContext(ctx, params.EntryList)
+func (orm *MSSQLORM) Query(ctx context.Context, params QueryParams) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+
+	selectDataset := orm.getQuerySelectDataset(getReadDB(ctx, orm.db, orm.replicas), params)
+	if err := selectDataset.ScanStructsContext(ctx, params.EntryList); err != nil {
+		return err
+	}
+
+	return orm.afterLoad(ctx, params.EntryList)
 }
 
-func (orm *MSSQLORM) QueryWithXLock(ctx context.Context, params QueryParams) error {
-	selectDataset := orm.getQuerySelectDataset(params)
+func (orm *MSSQLORM) QueryWithXLock(ctx context.Context, params QueryParams) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+
+	selectDataset := orm.getQuerySelectDataset(orm.db, params)
 
 	sqlStatement, sqlParams, err := selectDataset.ToSQL()
 	if err != nil {
@@ -299,11 +789,60 @@ func (orm *MSSQLORM) QueryWithXLock(ctx context.Context, params QueryParams) err
 
 	defer rows.Close()
 
-	return exec.NewScanner(rows).ScanStructs(params.EntryList)
+	if err := exec.NewScanner(rows).ScanStructs(params.EntryList); err != nil {
+		return err
+	}
+
+	// The rows have to be closed before the relations are queried on the same connection
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	return orm.afterLoad(ctx, params.EntryList)
+}
+
+func (orm *MSSQLORM) Iterate(ctx context.Context, params QueryParams, iterateFunc IterateFunc) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+	readDB := getReadDB(ctx, orm.db, orm.replicas)
+
+	return iterate(ctx, orm, readDB, orm.entryInfoProvider, params, orm.getQuerySelectDataset(readDB, params), nil, iterateFunc)
 }
 
-func (orm *MSSQLORM) Count(ctx context.Context, tableName string, expression exp.Expression) (int64, error) {
-	count, err := orm.db.Select().From(tableName).Where(expression).CountContext(ctx)
+func (orm *MSSQLORM) IterateWithXLock(ctx context.Context, params QueryParams, iterateFunc IterateFunc) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+
+	selectDataset := orm.getQuerySelectDataset(orm.db, params)
+
+	return iterate(ctx, orm, orm.db, orm.entryInfoProvider, params, selectDataset, orm.wrapSelectSQLStatementWithRowLock, iterateFunc)
+}
+
+func (orm *MSSQLORM) QueryPage(ctx context.Context, params QueryParams, cursor Cursor) (nextCursor Cursor, err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	// MSSQL does not support row value comparisons
+	return queryPage(ctx, orm.entryInfoProvider, params, cursor, false, orm.Query)
+}
+
+func (orm *MSSQLORM) Count(ctx context.Context, tableName string, expression exp.Expression) (count int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	countExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, countExpression)
+
+	count, err = getReadDB(ctx, orm.db, orm.replicas).
+		Select().
+		From(tableName).
+		Prepared(true).
+		Where(countExpression).
+		CountContext(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -311,12 +850,143 @@ func (orm *MSSQLORM) Count(ctx context.Context, tableName string, expression exp
 	return count, nil
 }
 
-func (orm *MSSQLORM) Update(ctx context.Context, entry interface{}) error {
+func (orm *MSSQLORM) Sum(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	// The sum of no rows is 0 rather than NULL
+	sumExpression := goqu.COALESCE(goqu.SUM(goqu.C(column)), 0)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, sumExpression, scopedExpression, result)
+}
+
+func (orm *MSSQLORM) Min(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, goqu.MIN(goqu.C(column)), scopedExpression, result)
+}
+
+func (orm *MSSQLORM) Max(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, goqu.MAX(goqu.C(column)), scopedExpression, result)
+}
+
+func (orm *MSSQLORM) Avg(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	// AVG() of MSSQL returns the type of the column, which truncates the average of integer columns
+	averageExpression := goqu.AVG(goqu.Cast(goqu.C(column), "FLOAT"))
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, averageExpression, scopedExpression, result)
+}
+
+func (orm *MSSQLORM) Exists(ctx context.Context, tableName string, expression exp.Expression) (found bool, err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return false, err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return exists(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, scopedExpression)
+}
+
+func (orm *MSSQLORM) VerifySchema(ctx context.Context, models ...interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	return verifySchema(ctx, orm.db, orm.entryInfoProvider, DriverTypeMSSQL, models)
+}
+
+func (orm *MSSQLORM) Update(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	return withUpdateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*MSSQLORM).update(ctx, entry, nil)
+	})
+}
+
+func (orm *MSSQLORM) UpdateColumns(ctx context.Context, entry interface{}, columns ...string) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	if len(columns) == 0 {
+		return ErrColumnsExpected
+	}
+
+	return withUpdateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*MSSQLORM).update(ctx, entry, columns)
+	})
+}
+
+// update updates all columns of entry, or only columns and the columns changed by OnUpdate() if columns is not nil
+func (orm *MSSQLORM) update(ctx context.Context, entry interface{}, columns []string) error {
 	if entry == nil {
 		return ErrNilEntry
 	}
 
-	orm.entryInfoProvider.OnUpdateIfEntryIsOnCreator(entry)
+	var updateRecord exp.Record
+
+	if columns == nil {
+		orm.entryInfoProvider.OnUpdateIfEntryIsOnCreator(entry)
+	} else {
+		var err error
+		if updateRecord, err = orm.entryInfoProvider.OnUpdateColumns(entry, columns); err != nil {
+			return err
+		}
+	}
 
 	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
 	if err != nil {
@@ -328,11 +998,35 @@ func (orm *MSSQLORM) Update(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
+	var (
+		updateExpression exp.Expression = selectEntryUniqueExpression
+		updateSource     interface{}    = entry
+	)
+
+	if updateRecord != nil {
+		updateSource = updateRecord
+	}
+
+	versionColumn, version, isVersioned := orm.entryInfoProvider.GetVersion(entry)
+	if isVersioned {
+		if updateRecord == nil {
+			if updateRecord, err = orm.entryInfoProvider.GetUpdateRecord(entry); err != nil {
+				return err
+			}
+		}
+
+		updateRecord[versionColumn] = version + 1
+		updateExpression = goqu.And(selectEntryUniqueExpression, goqu.C(versionColumn).Eq(version))
+		updateSource = updateRecord
+	}
+
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, entry)
+
 	result, err := orm.db.
 		Update(entryTableName).
 		Prepared(true).
-		Where(selectEntryUniqueExpression).
-		Set(entry).
+		Where(updateExpression).
+		Set(updateSource).
 		Executor().
 		ExecContext(ctx)
 	if err != nil {
@@ -345,27 +1039,251 @@ func (orm *MSSQLORM) Update(ctx context.Context, entry interface{}) error {
 	}
 
 	if rowsAffected == 0 {
+		if isVersioned {
+			return ErrStaleEntry
+		}
+
 		return ErrUpdateNotApplied
 	}
 
+	if isVersioned {
+		orm.entryInfoProvider.SetVersion(entry, version+1)
+	}
+
 	return nil
 }
 
+func (orm *MSSQLORM) UpdateWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	return orm.updateWhere(ctx, tableName, expression, record, nil)
+}
+
+func (orm *MSSQLORM) UpdateWhereWithLimit(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+	limit uint32,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	if isEmptyLimit(limit) {
+		return 0, nil
+	}
+
+	return orm.updateWhere(ctx, tableName, expression, record, &limit)
+}
+
+// HACK: Since goqu does not support MSSQL's TOP syntax in UPDATE and DELETE statements, we have to manually add that
+func (orm *MSSQLORM) wrapUpdateOrDeleteSQLStatementWithTop(statement string, limit *uint32) string {
+	if limit == nil {
+		return statement
+	}
+
+	for _, keyword := range []string{"UPDATE ", "DELETE "} {
+		if strings.HasPrefix(statement, keyword) {
+			return keyword + fmt.Sprintf("TOP (%d) ", *limit) + statement[len(keyword):]
+		}
+	}
+
+	return statement
+}
+
+func (orm *MSSQLORM) updateWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+	limit *uint32,
+) (int64, error) {
+	expression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = orm.redactor.bindRecord(ctx, tableName, record)
+	ctx = orm.redactor.bindExpression(ctx, tableName, expression)
+
+	sqlStatement, params, err := orm.db.
+		Update(tableName).
+		Prepared(true).
+		Where(expression).
+		Set(record).
+		ToSQL()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := orm.db.ExecContext(ctx, orm.wrapUpdateOrDeleteSQLStatementWithTop(sqlStatement, limit), params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (orm *MSSQLORM) DeleteWhere(ctx context.Context, tableName string, expression exp.Expression) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	return orm.deleteWhere(ctx, tableName, expression, nil)
+}
+
+func (orm *MSSQLORM) DeleteWhereWithLimit(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	limit uint32,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	if isEmptyLimit(limit) {
+		return 0, nil
+	}
+
+	return orm.deleteWhere(ctx, tableName, expression, &limit)
+}
+
+func (orm *MSSQLORM) deleteWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	limit *uint32,
+) (int64, error) {
+	// The rows of soft deleted tables are soft deleted, like by Delete()
+	softDeleteRecord, ok, err := getTableSoftDeleteRecord(orm.entryInfoProvider, tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	if ok {
+		return orm.updateWhere(ctx, tableName, expression, softDeleteRecord, limit)
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, expression)
+
+	sqlStatement, params, err := orm.db.
+		Delete(tableName).
+		Prepared(true).
+		Where(expression).
+		ToSQL()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := orm.db.ExecContext(ctx, orm.wrapUpdateOrDeleteSQLStatementWithTop(sqlStatement, limit), params...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
 func (orm *MSSQLORM) GetDBWrapper() DBWrapper {
 	return orm.db
 }
 
-func (orm *MSSQLORM) WithTx(executeFunc func(ORM) error) error {
+func (orm *MSSQLORM) Ping(ctx context.Context) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	return pingDatabases(ctx, orm.pool, orm.replicas)
+}
+
+func (orm *MSSQLORM) HealthCheck(ctx context.Context) (status HealthStatus, err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	return checkHealth(ctx, DriverTypeMSSQL, orm.pool, orm.replicas)
+}
+
+func (orm *MSSQLORM) Close() (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	if _, ok := orm.db.(*goqu.Database); !ok {
+		return ErrCloseInTransaction
+	}
+
+	return closeDatabases(orm.pool, orm.ownsPool, orm.replicas)
+}
+
+func (orm *MSSQLORM) Unscoped() ORM {
+	unscopedORM := *orm
+	unscopedORM.unscoped = true
+
+	return &unscopedORM
+}
+
+func (orm *MSSQLORM) Preload(relationFields ...string) ORM {
+	preloadORM := *orm
+	preloadORM.preloads = append(append([]string{}, orm.preloads...), relationFields...)
+
+	return &preloadORM
+}
+
+// afterLoad preloads the relations of entries, a pointer to an entry or to a slice of entries, and runs their
+// AfterLoad() hooks
+func (orm *MSSQLORM) afterLoad(ctx context.Context, entries interface{}) error {
+	if err := orm.preload(ctx, entries); err != nil {
+		return err
+	}
+
+	return runAfterLoadHooks(ctx, orm, entries)
+}
+
+// preload loads the relations passed to Preload() into entries, a pointer to an entry or to a slice of entries
+func (orm *MSSQLORM) preload(ctx context.Context, entries interface{}) error {
+	if len(orm.preloads) == 0 {
+		return nil
+	}
+
+	queryORM := *orm
+	queryORM.preloads = nil
+
+	return preloadRelations(ctx, &queryORM, orm.entryInfoProvider, orm.databaseConfig, entries, orm.preloads)
+}
+
+func (orm *MSSQLORM) WithTx(executeFunc func(ORM) error) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
+	return orm.WithTxContext(context.Background(), nil, func(_ context.Context, txORM ORM) error {
+		return executeFunc(txORM)
+	})
+}
+
+func (orm *MSSQLORM) WithTxContext(
+	ctx context.Context,
+	opts *sql.TxOptions,
+	executeFunc func(context.Context, ORM) error,
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMSSQL, &err)
+
 	if nonTXDB, ok := orm.db.(*goqu.Database); ok {
-		return nonTXDB.WithTx(func(td *goqu.TxDatabase) error {
-			return executeFunc(&MSSQLORM{
-				db:                   td,
-				entryInfoProvider:    orm.entryInfoProvider,
-				insertIntoTableRegex: orm.insertIntoTableRegex,
-				fromTableRegex:       orm.fromTableRegex,
+		return withRetry(ctx, orm.databaseConfig.RetryPolicy, DriverTypeMSSQL, func() error {
+			return withGoquTx(ctx, nonTXDB, opts, func(td *goqu.TxDatabase) error {
+				return executeFunc(ctx, &MSSQLORM{
+					db:                   td,
+					pool:                 orm.pool,
+					replicas:             orm.replicas,
+					entryInfoProvider:    orm.entryInfoProvider,
+					redactor:             orm.redactor,
+					databaseConfig:       orm.databaseConfig,
+					unscoped:             orm.unscoped,
+					insertIntoTableRegex: orm.insertIntoTableRegex,
+					fromTableRegex:       orm.fromTableRegex,
+				})
 			})
 		})
 	}
 
-	return executeFunc(orm)
+	savepointORM := *orm
+	savepointORM.savepointDepth++
+	savepointORM.preloads = nil
+
+	return withSavepoint(ctx, orm.db, DriverTypeMSSQL, savepointORM.savepointDepth, func() error {
+		return executeFunc(ctx, &savepointORM)
+	})
 }
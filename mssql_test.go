@@ -1,10 +1,13 @@
 package miniorm
 
 import (
+	"context"
 	"log"
+	"regexp"
 	"testing"
 
 	_ "github.com/denisenkom/go-mssqldb" // For MSSQL driver
+	"github.com/doug-martin/goqu/v9"
 	"github.com/go-testfixtures/testfixtures/v3"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,7 +25,7 @@ var (
 )
 
 func prepareMSSQLTestEntryTable(fixtureFile string) error {
-	db, err := newSQLDatabase(mssqlTestConfig)
+	db, err := NewSQLDatabase(mssqlTestConfig)
 	if err != nil {
 		return err
 	}
@@ -37,7 +40,9 @@ func prepareMSSQLTestEntryTable(fixtureFile string) error {
 			string_col NVARCHAR(MAX) NOT NULL,
 			bytes_col VARBINARY(MAX) NOT NULL,
 			on_create_count BIGINT NOT NULL,
-			on_update_count BIGINT NOT NULL
+			on_update_count BIGINT NOT NULL,
+			version BIGINT NOT NULL DEFAULT 0,
+			deleted_at BIGINT NULL
 		);
 
 		IF OBJECT_ID('get_unique_entries', 'U') IS NOT NULL
@@ -49,9 +54,26 @@ func prepareMSSQLTestEntryTable(fixtureFile string) error {
 			bytes_col VARBINARY(1024) NOT NULL,
 			on_create_count BIGINT NOT NULL,
 			on_update_count BIGINT NOT NULL,
+			version BIGINT NOT NULL DEFAULT 0,
+			deleted_at BIGINT NULL,
 			CONSTRAINT PK_get_unique_entries PRIMARY KEY (id_1, id_2)
 		);
 
+		IF OBJECT_ID('get_key_entries', 'U') IS NOT NULL
+			DROP TABLE get_key_entries;
+		CREATE TABLE get_key_entries (
+			id NVARCHAR(36) PRIMARY KEY DEFAULT CONVERT(NVARCHAR(36), NEWID()),
+			string_col NVARCHAR(MAX) NOT NULL
+		);
+
+		IF OBJECT_ID('get_child_entries', 'U') IS NOT NULL
+			DROP TABLE get_child_entries;
+		CREATE TABLE get_child_entries (
+			id BIGINT PRIMARY KEY,
+			parent_id BIGINT NOT NULL,
+			string_col NVARCHAR(MAX) NOT NULL
+		);
+
 		COMMIT TRANSACTION;
 	`); err != nil {
 		return err
@@ -177,3 +199,459 @@ func TestMSSQLWithTX(t *testing.T) {
 
 	testWithTX(t, orm)
 }
+
+func TestMSSQLWithTxContext(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testWithTxContext(t, orm)
+}
+
+func TestMSSQLNestedWithTx(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testNestedWithTx(t, orm)
+}
+
+func TestMSSQLCreateOrUpdateUpsert(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mssqlTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	// MSSQL's auto increment ID takes the largest existing value + 1, so we set this value to be 100 to match the test data
+	testCreateOrUpdateUpsert(t, orm, 100)
+}
+
+func TestMSSQLCreateMany(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mssqlTestConfig
+	databaseConfig.CreateManyChunkSize = 2
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testCreateMany(t, orm, 0)
+}
+
+func TestMSSQLUpdateWhere(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testUpdateWhere(t, orm)
+}
+
+func TestMSSQLDeleteWhere(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testDeleteWhere(t, orm)
+}
+
+func TestMSSQLOptimisticLocking(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testOptimisticLocking(t, orm)
+}
+
+func TestMSSQLOptimisticLockingUpsert(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mssqlTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testOptimisticLocking(t, orm)
+}
+
+func TestMSSQLSoftDelete(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mssqlTestConfig
+	databaseConfig.Models = []interface{}{&softDeleteIDEntry{}}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testSoftDelete(t, orm)
+}
+
+func TestMSSQLUnregisteredSoftDelete(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testUnregisteredSoftDelete(t, orm)
+}
+
+func TestMSSQLTaggedModel(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testTaggedModel(t, orm, 0)
+}
+
+func TestMSSQLTaggedModelUpsert(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mssqlTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testTaggedModel(t, orm, 0)
+}
+
+func TestMSSQLKeys(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testKeys(t, orm)
+}
+
+func TestMSSQLKeysUpsert(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mssqlTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testKeys(t, orm)
+}
+
+func TestMSSQLGeneratedKeys(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testGeneratedKeys(t, orm)
+}
+
+func TestMSSQLWithTxRetryPolicy(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mssqlTestConfig
+	databaseConfig.RetryPolicy = NewExponentialBackoffRetryPolicy()
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testWithTX(t, orm)
+}
+
+func TestMSSQLIterate(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testIterate(t, orm)
+}
+
+func TestMSSQLIterateWithXLock(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testIterateWithXLock(t, orm)
+}
+
+func TestMSSQLQueryPage(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testQueryPage(t, orm)
+}
+
+func TestMSSQLQueryColumns(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testQueryColumns(t, orm)
+}
+
+func TestMSSQLUpdateColumns(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testUpdateColumns(t, orm)
+}
+
+func TestMSSQLAggregate(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testAggregate(t, orm)
+}
+
+func TestMSSQLQueryGroupBy(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testQueryGroupBy(t, orm)
+}
+
+func TestMSSQLPreload(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_preload.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mssqlTestConfig
+	databaseConfig.PreloadChunkSize = 2
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testPreload(t, orm)
+}
+
+func TestMSSQLGenerateCreateTableStatement(t *testing.T) {
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testGenerateCreateTableStatement(t, orm, DriverTypeMSSQL)
+}
+
+func TestMSSQLVerifySchema(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testVerifySchema(t, orm, DriverTypeMSSQL)
+}
+
+func TestMSSQLHooks(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_hooks.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testHooks(t, orm)
+}
+
+func TestMSSQLInstrumentation(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mssqlTestConfig
+	databaseConfig.Instrumentation = &recordingInstrumentation{}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testInstrumentation(t, orm)
+}
+
+func TestMSSQLStructuredLogging(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mssqlTestConfig
+	databaseConfig.StructuredLogger = &recordingStructuredLogger{}
+	databaseConfig.RedactedColumns = []string{"on_update_count"}
+	databaseConfig.Models = []interface{}{&sensitiveEntry{}}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testStructuredLogging(t, orm)
+}
+
+func TestMSSQLHealth(t *testing.T) {
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testHealth(t, orm, DriverTypeMSSQL)
+}
+
+func TestMSSQLFromSQLDatabase(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	testFromSQLDatabase(t, mssqlTestConfig)
+}
+
+func TestMSSQLWhereWithZeroLimit(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testWhereWithZeroLimit(t, orm)
+}
+
+func TestMSSQLAggregateEmptyTable(t *testing.T) {
+	err := prepareMSSQLTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mssqlTestConfig)
+	assert.Nil(t, err)
+
+	testAggregateEmptyTable(t, orm)
+}
+
+func TestMSSQLBuildMergeSQLStatementWithOutputOrdinal(t *testing.T) {
+	t.Parallel()
+
+	orm := &MSSQLORM{
+		entryInfoProvider:    newEntryInfoProvider(),
+		insertIntoTableRegex: regexp.MustCompile(`INSERT INTO "[^"]+"\s*\(("[^"]+",\s*)*("[^"]+")\)`),
+	}
+
+	entryList := []interface{}{
+		&getIDEntry{StringCol: "a", BytesCol: []byte("a")},
+		&getIDEntry{StringCol: "b", BytesCol: []byte("b")},
+	}
+
+	sqlStatement, params, ok, err := orm.buildMergeSQLStatementWithOutputOrdinal(
+		goqu.New("sqlserver", nil),
+		getIDEntryTableName,
+		getIDEntryIDColumnName,
+		entryList,
+	)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, `MERGE INTO "get_id_entries" USING (VALUES (@p1, 0, @p2, @p3, @p4), (@p5, 1, @p6, @p7, @p8)) `+
+		`AS miniorm_source ("bytes_col", "miniorm_ordinal", "on_create_count", "on_update_count", "string_col") ON 1 = 0 `+
+		`WHEN NOT MATCHED THEN INSERT ("bytes_col", "on_create_count", "on_update_count", "string_col") `+
+		`VALUES (miniorm_source."bytes_col", miniorm_source."on_create_count", miniorm_source."on_update_count", `+
+		`miniorm_source."string_col") OUTPUT miniorm_source.miniorm_ordinal, INSERTED.id;`, sqlStatement)
+	assert.Len(t, params, 8)
+}
+
+func TestMSSQLMergeSQLStatementRedaction(t *testing.T) {
+	t.Parallel()
+
+	orm := &MSSQLORM{
+		entryInfoProvider:    newEntryInfoProvider(),
+		insertIntoTableRegex: regexp.MustCompile(`INSERT INTO "[^"]+"\s*\(("[^"]+",\s*)*("[^"]+")\)`),
+		redactor:             newLogRedactor(DatabaseConfig{Logger: &printfLogger{}, Models: []interface{}{&sensitiveEntry{}}}),
+	}
+
+	entryList := []interface{}{
+		&sensitiveEntry{StringCol: "a", BytesCol: []byte("secret a"), OnUpdateCount: 1},
+		&sensitiveEntry{StringCol: "b", BytesCol: []byte("secret b"), OnUpdateCount: 2},
+	}
+
+	_, params, ok, err := orm.buildMergeSQLStatementWithOutputOrdinal(
+		goqu.New("sqlserver", nil),
+		getIDEntryTableName,
+		getIDEntryIDColumnName,
+		entryList,
+	)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+
+	ctx := orm.redactor.bindEntries(context.Background(), getIDEntryTableName, entryList...)
+	assert.Equal(t, []interface{}{
+		RedactedValue, int64(0), int64(1), "a",
+		RedactedValue, int64(0), int64(2), "b",
+	}, redactArgs(ctx, params))
+}
+
+func TestMSSQLUpsertSQLStatementRedaction(t *testing.T) {
+	t.Parallel()
+
+	orm := &MSSQLORM{
+		entryInfoProvider: newEntryInfoProvider(),
+		redactor: newLogRedactor(DatabaseConfig{
+			Logger:          &printfLogger{},
+			RedactedColumns: []string{"unique_key_entries.name"},
+		}),
+	}
+
+	entry := &uniqueKeyEntry{Name: "secret name", StringCol: "value"}
+
+	createEntry, updateEntry, ok := orm.entryInfoProvider.GetUpsertEntries(entry)
+	assert.True(t, ok)
+
+	insertRecord, err := orm.entryInfoProvider.GetInsertRecord(createEntry)
+	assert.Nil(t, err)
+
+	updateRecord, err := orm.entryInfoProvider.GetUpdateRecord(updateEntry)
+	assert.Nil(t, err)
+
+	uniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(createEntry)
+	assert.Nil(t, err)
+
+	sqlStatement, params := orm.buildUpsertSQLStatement(
+		"unique_key_entries",
+		uniqueExpression,
+		[]string{"name"},
+		insertRecord,
+		updateRecord,
+		"id",
+	)
+	assert.Equal(t, `MERGE INTO "unique_key_entries" WITH (HOLDLOCK) AS "target" `+
+		`USING (SELECT @p1 AS "name") AS "source" ON ("target"."name" = "source"."name") `+
+		`WHEN MATCHED THEN UPDATE SET "string_col" = @p2 `+
+		`WHEN NOT MATCHED THEN INSERT ("name", "string_col") VALUES (@p3, @p4) `+
+		`OUTPUT $action, INSERTED."id", INSERTED."id";`, sqlStatement)
+
+	ctx := orm.redactor.bindEntries(context.Background(), "unique_key_entries", createEntry, updateEntry)
+	assert.Equal(t, []interface{}{RedactedValue, "value", RedactedValue, "value"}, redactArgs(ctx, params))
+}
@@ -3,36 +3,113 @@ package miniorm
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"math"
+	"strings"
+	"sync"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exp"
 )
 
+// innodb_autoinc_lock_mode in which the IDs of a multi-row INSERT may not be consecutive
+const mysqlInterleavedAutoIncrementLockMode = 2
+
 type MySQLORM struct {
-	db                DBWrapper
-	entryInfoProvider *entryInfoProvider
+	db                    DBWrapper
+	pool                  connectionPool
+	ownsPool              bool
+	replicas              *replicaSet
+	entryInfoProvider     *entryInfoProvider
+	redactor              *logRedactor
+	autoIncrementSettings *mysqlAutoIncrementSettings
+	databaseConfig        DatabaseConfig
+	savepointDepth        uint
+	unscoped              bool
+	preloads              []string
+}
+
+// mysqlAutoIncrementSettings caches the auto-increment settings of the server, which are read once per ORM
+type mysqlAutoIncrementSettings struct {
+	lock      sync.Mutex
+	isLoaded  bool
+	increment int64
+	lockMode  int64
 }
 
 func NewMySQLORM(databaseConfig DatabaseConfig) (ORM, error) {
-	goquDB, err := newGoquDatabase(databaseConfig)
+	goquDB, sqlDB, err := newGoquDatabase(databaseConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	goquDB.Logger(databaseConfig.Logger)
+	orm, err := newMySQLORM(goquDB, sqlDB, true, databaseConfig)
+	if err != nil {
+		_ = sqlDB.Close()
+	}
+
+	return orm, err
+}
+
+// NewMySQLORMFromSQLDatabase is NewORMFromSQLDatabase for MySQL
+func NewMySQLORMFromSQLDatabase(db *sql.DB, databaseConfig DatabaseConfig) (ORM, error) {
+	databaseConfig.Driver = DriverTypeMySQL
+
+	if err := waitForDatabase(db, databaseConfig); err != nil {
+		return nil, err
+	}
 
-	return &MySQLORM{
-		db:                goquDB,
-		entryInfoProvider: newEntryInfoProvider(),
-	}, nil
+	return newMySQLORM(newGoquDatabaseFromSQLDatabase(db, databaseConfig), db, false, databaseConfig)
 }
 
-func (orm *MySQLORM) Create(ctx context.Context, entry interface{}) error {
+// NewMySQLORMFromGoquDatabase is NewORMFromGoquDatabase for MySQL
+func NewMySQLORMFromGoquDatabase(goquDB *goqu.Database, databaseConfig DatabaseConfig) (ORM, error) {
+	databaseConfig.Driver = DriverTypeMySQL
+
+	pool, err := getGoquConnectionPool(goquDB, databaseConfig.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitForDatabase(pool, databaseConfig); err != nil {
+		return nil, err
+	}
+
+	return newMySQLORM(instrumentGoquDatabase(goquDB, databaseConfig), pool, false, databaseConfig)
+}
+
+func newMySQLORM(goquDB *goqu.Database, pool connectionPool, ownsPool bool, databaseConfig DatabaseConfig) (ORM, error) {
+	replicas, err := newReplicaSet(databaseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInstrumentedORM(&MySQLORM{
+		db:                    goquDB,
+		pool:                  pool,
+		ownsPool:              ownsPool,
+		replicas:              replicas,
+		entryInfoProvider:     newEntryInfoProvider(databaseConfig.Models...),
+		redactor:              newLogRedactor(databaseConfig),
+		autoIncrementSettings: &mysqlAutoIncrementSettings{},
+		databaseConfig:        databaseConfig,
+	}, databaseConfig), nil
+}
+
+func (orm *MySQLORM) Create(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
 
+	return withCreateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*MySQLORM).create(ctx, entry)
+	})
+}
+
+func (orm *MySQLORM) create(ctx context.Context, entry interface{}) error {
 	orm.entryInfoProvider.OnCreateIfEntryIsOnCreator(entry)
 
 	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
@@ -40,6 +117,17 @@ func (orm *MySQLORM) Create(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, entry)
+
+	var setKey func(key int64) error
+
+	if _, keyDestination, ok := orm.entryInfoProvider.GetKeyDestination(entry); ok {
+		setKey, err = getIntegerKeySetter(keyDestination)
+		if err != nil {
+			return err
+		}
+	}
+
 	result, err := orm.GetDBWrapper().
 		Insert(entryTableName).
 		Prepared(true).
@@ -50,41 +138,153 @@ func (orm *MySQLORM) Create(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
-	if idSetterEntry, ok := entry.(IDSetter); ok {
+	if setKey != nil {
 		entryID, err := result.LastInsertId()
 		if err != nil {
 			return err
 		}
 
-		idSetterEntry.SetID(entryID)
+		return setKey(entryID)
 	}
 
 	return nil
 }
 
-func (orm *MySQLORM) CreateOrUpdate(ctx context.Context, entry interface{}) error {
+func (orm *MySQLORM) CreateMany(ctx context.Context, entries interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	return createMany(ctx, orm, orm.entryInfoProvider, orm.databaseConfig, entries, orm.createChunk)
+}
+
+// createChunk inserts entryList in a single INSERT, or one row per INSERT if InnoDB may interleave their IDs, which is
+// the case in the default innodb_autoinc_lock_mode of MySQL 8.0. The settings are read once per ORM.
+func (orm *MySQLORM) createChunk(ctx context.Context, db DBWrapper, tableName string, entryList []interface{}) error {
+	_, keyDestination, isKeySetterEntry := orm.entryInfoProvider.GetKeyDestination(entryList[0])
+	if !isKeySetterEntry {
+		_, err := orm.insertChunk(ctx, db, tableName, entryList)
+		return err
+	}
+
+	if _, err := getIntegerKeySetter(keyDestination); err != nil {
+		return err
+	}
+
+	increment, lockMode, err := orm.autoIncrementSettings.get(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	chunkSize := len(entryList)
+	if lockMode == mysqlInterleavedAutoIncrementLockMode {
+		chunkSize = 1
+	}
+
+	for _, chunk := range chunkEntryList(entryList, chunkSize) {
+		firstEntryID, err := orm.insertChunk(ctx, db, tableName, chunk)
+		if err != nil {
+			return err
+		}
+
+		if err := setIntegerKeys(orm.entryInfoProvider, chunk, firstEntryID, increment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// insertChunk inserts entryList in a single multi-row INSERT, returning the ID of the first row
+func (orm *MySQLORM) insertChunk(ctx context.Context, db DBWrapper, tableName string, entryList []interface{}) (int64, error) {
+	ctx = orm.redactor.bindEntries(ctx, tableName, entryList...)
+
+	result, err := db.
+		Insert(tableName).
+		Prepared(true).
+		Rows(entryList...).
+		Executor().
+		ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.LastInsertId()
+}
+
+// get returns auto_increment_increment and innodb_autoinc_lock_mode, read through db the first time
+func (settings *mysqlAutoIncrementSettings) get(ctx context.Context, db DBWrapper) (increment int64, lockMode int64, err error) {
+	settings.lock.Lock()
+	defer settings.lock.Unlock()
+
+	if settings.isLoaded {
+		return settings.increment, settings.lockMode, nil
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT @@auto_increment_increment, @@innodb_autoinc_lock_mode")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, 0, err
+		}
+
+		return 0, 0, ErrNotFound
+	}
+
+	if err := rows.Scan(&settings.increment, &settings.lockMode); err != nil {
+		return 0, 0, err
+	}
+
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	settings.isLoaded = true
+
+	return settings.increment, settings.lockMode, nil
+}
+
+func (orm *MySQLORM) CreateOrUpdate(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
 
-	return orm.WithTx(func(txORM ORM) error {
+	// The hooks of entry depend on whether it is created or updated, which the upsert statements cannot tell beforehand
+	if orm.databaseConfig.CreateOrUpdateMode == CreateOrUpdateModeUpsert && !hasCreateOrUpdateHooks(entry) {
+		if uniqueColumns, ok := orm.entryInfoProvider.GetUniqueColumns(entry); ok {
+			if createEntry, updateEntry, ok := orm.entryInfoProvider.GetUpsertEntries(entry); ok {
+				return orm.upsert(ctx, entry, createEntry, updateEntry, uniqueColumns)
+			}
+		}
+	}
+
+	return orm.WithTxContext(ctx, nil, func(txCtx context.Context, txORM ORM) error {
 		entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
 		if err != nil {
 			return err
 		}
 
-		selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
+		// Soft deleted rows are included, so that they are restored by the update rather than conflicting with the insert
+		selectEntryExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
 		if err != nil {
 			return err
 		}
 
+		txCtx = orm.redactor.bindExpression(txCtx, entryTableName, selectEntryExpression)
+
 		rows, err := txORM.GetDBWrapper().
 			Select().
 			From(entryTableName).
-			Where(selectEntryUniqueExpression).
+			Prepared(true).
+			Where(selectEntryExpression).
 			ForUpdate(goqu.Wait).
 			Executor().
-			QueryContext(ctx)
+			QueryContext(txCtx)
 		if err != nil {
 			return err
 		}
@@ -97,14 +297,144 @@ func (orm *MySQLORM) CreateOrUpdate(ctx context.Context, entry interface{}) erro
 		}
 
 		if !rowExists {
-			return txORM.Create(ctx, entry)
+			return txORM.Create(txCtx, entry)
+		}
+
+		return txORM.Update(txCtx, entry)
+	})
+}
+
+// HACK: goqu turns every INSERT with a conflict expression into INSERT IGNORE for MySQL, which would also silence
+// unrelated errors, so we have to manually add the ON DUPLICATE KEY UPDATE clause
+func (orm *MySQLORM) wrapInsertSQLStatementWithOnDuplicateKeyUpdate(
+	statement string,
+	params []interface{},
+	updateRecord exp.Record,
+	uniqueColumns []string,
+	keyColumn string,
+) (string, []interface{}) {
+	updateColumns := updateRecord.Cols()
+	setClauses := make([]string, 0, len(updateColumns)+1)
+
+	for _, column := range updateColumns {
+		setClauses = append(setClauses, fmt.Sprintf("`%s` = ?", column))
+		params = append(params, updateRecord[column])
+	}
+
+	// When the row is updated, make LastInsertId() return the complement of its ID, or -1 without key, to tell the update
+	// from an insert whatever the affected rows, which count the unchanged rows with clientFoundRows. The column keeps
+	// its value.
+	if keyColumn != "" {
+		setClauses = append(setClauses, fmt.Sprintf("`%s` = ~LAST_INSERT_ID(~`%s`)", keyColumn, keyColumn))
+	} else {
+		setClauses = append(setClauses, fmt.Sprintf(
+			"`%s` = IF(LAST_INSERT_ID(~0), `%s`, `%s`)",
+			uniqueColumns[0],
+			uniqueColumns[0],
+			uniqueColumns[0],
+		))
+	}
+
+	return statement + " ON DUPLICATE KEY UPDATE " + strings.Join(setClauses, ", "), params
+}
+
+// upsert creates or updates entry in a single INSERT ... ON DUPLICATE KEY UPDATE statement. The affected rows do not
+// tell an unchanged row from an inserted one with clientFoundRows, so the update is told from LastInsertId() instead.
+//
+// Note that MySQL does not support a conflict target, so any UNIQUE index of the table may trigger the update.
+func (orm *MySQLORM) upsert(
+	ctx context.Context,
+	entry, createEntry, updateEntry interface{},
+	uniqueColumns []string,
+) error {
+	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
+	if err != nil {
+		return err
+	}
+
+	updateRecord, err := orm.entryInfoProvider.GetUpdateRecord(updateEntry)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, createEntry, updateEntry)
+
+	var setKey func(key int64) error
+
+	keyColumn, keyDestination, isKeySetterEntry := orm.entryInfoProvider.GetKeyDestination(entry)
+	if isKeySetterEntry {
+		if keyColumn == "" {
+			return ErrKeyGetterExpected
+		}
+
+		setKey, err = getIntegerKeySetter(keyDestination)
+		if err != nil {
+			return err
+		}
+	}
+
+	sqlStatement, params, err := orm.GetDBWrapper().
+		Insert(entryTableName).
+		Prepared(true).
+		Rows(createEntry).
+		ToSQL()
+	if err != nil {
+		return err
+	}
+
+	sqlStatement, params = orm.wrapInsertSQLStatementWithOnDuplicateKeyUpdate(
+		sqlStatement,
+		params,
+		updateRecord,
+		uniqueColumns,
+		keyColumn,
+	)
+
+	result, err := orm.GetDBWrapper().ExecContext(ctx, sqlStatement, params...)
+	if err != nil {
+		return err
+	}
+
+	entryID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	if entryID < 0 {
+		entryID = ^entryID
+		orm.entryInfoProvider.SetEntry(entry, updateEntry)
+	} else {
+		orm.entryInfoProvider.SetEntry(entry, createEntry)
+	}
+
+	if setKey != nil {
+		return setKey(entryID)
+	}
+
+	return nil
+}
+
+func (orm *MySQLORM) Delete(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	return withDeleteHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		if softDeleteColumn, ok := orm.entryInfoProvider.GetSoftDeleteColumn(entry); ok {
+			return softDelete(ctx, hookORM, orm.entryInfoProvider, entry, softDeleteColumn, orm.unscoped)
 		}
 
-		return txORM.Update(ctx, entry)
+		return hookORM.(*MySQLORM).hardDelete(ctx, entry)
 	})
 }
 
-func (orm *MySQLORM) Delete(ctx context.Context, entry interface{}) error {
+func (orm *MySQLORM) HardDelete(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	return withDeleteHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*MySQLORM).hardDelete(ctx, entry)
+	})
+}
+
+func (orm *MySQLORM) hardDelete(ctx context.Context, entry interface{}) error {
 	if entry == nil {
 		return ErrNilEntry
 	}
@@ -119,8 +449,11 @@ func (orm *MySQLORM) Delete(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
+	ctx = orm.redactor.bindExpression(ctx, entryTableName, selectEntryUniqueExpression)
+
 	result, err := orm.db.
 		Delete(entryTableName).
+		Prepared(true).
 		Where(selectEntryUniqueExpression).
 		Executor().
 		ExecContext(ctx)
@@ -140,7 +473,9 @@ func (orm *MySQLORM) Delete(ctx context.Context, entry interface{}) error {
 	return nil
 }
 
-func (orm *MySQLORM) Get(ctx context.Context, entry interface{}) error {
+func (orm *MySQLORM) Get(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
@@ -150,15 +485,18 @@ func (orm *MySQLORM) Get(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
-	selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
+	selectEntryExpression, err := orm.entryInfoProvider.GetEntryScopedSelectExpression(entry, orm.unscoped)
 	if err != nil {
 		return err
 	}
 
-	found, err := orm.db.
+	ctx = orm.redactor.bindExpression(ctx, entryTableName, selectEntryExpression)
+
+	found, err := getReadDB(ctx, orm.db, orm.replicas).
 		Select().
 		From(entryTableName).
-		Where(selectEntryUniqueExpression).
+		Prepared(true).
+		Where(selectEntryExpression).
 		Limit(1).
 		ScanStructContext(ctx, entry)
 	if err != nil {
@@ -169,10 +507,12 @@ func (orm *MySQLORM) Get(ctx context.Context, entry interface{}) error {
 		return ErrNotFound
 	}
 
-	return nil
+	return orm.afterLoad(ctx, entry)
 }
 
-func (orm *MySQLORM) GetWithXLock(ctx context.Context, entry interface{}) error {
+func (orm *MySQLORM) GetWithXLock(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
@@ -182,15 +522,18 @@ func (orm *MySQLORM) GetWithXLock(ctx context.Context, entry interface{}) error
 		return err
 	}
 
-	selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
+	selectEntryExpression, err := orm.entryInfoProvider.GetEntryScopedSelectExpression(entry, orm.unscoped)
 	if err != nil {
 		return err
 	}
 
+	ctx = orm.redactor.bindExpression(ctx, entryTableName, selectEntryExpression)
+
 	found, err := orm.GetDBWrapper().
 		Select().
 		From(entryTableName).
-		Where(selectEntryUniqueExpression).
+		Prepared(true).
+		Where(selectEntryExpression).
 		ForUpdate(goqu.Wait).
 		Executor().
 		ScanStructContext(ctx, entry)
@@ -202,11 +545,24 @@ func (orm *MySQLORM) GetWithXLock(ctx context.Context, entry interface{}) error
 		return ErrNotFound
 	}
 
-	return nil
+	return orm.afterLoad(ctx, entry)
 }
 
-func (orm *MySQLORM) getQuerySelectDataset(params QueryParams) *goqu.SelectDataset {
-	selectDataset := orm.db.Select().From(params.TableName).Where(params.Expression).Order(params.OrderBy...)
+func (orm *MySQLORM) getQuerySelectDataset(db DBWrapper, params QueryParams) *goqu.SelectDataset {
+	queryExpression := getQueryScopedExpression(orm.entryInfoProvider, params, orm.unscoped)
+	selectDataset := db.Select().From(params.TableName).Prepared(true).Where(queryExpression).Order(params.OrderBy...)
+
+	if len(params.Columns) > 0 {
+		selectDataset = selectDataset.Select(params.Columns...)
+	}
+
+	if len(params.GroupBy) > 0 {
+		selectDataset = selectDataset.GroupBy(params.GroupBy...)
+	}
+
+	if params.Having != nil {
+		selectDataset = selectDataset.Having(params.Having)
+	}
 
 	if params.Offset != nil {
 		selectDataset = selectDataset.Offset(uint(*params.Offset))
@@ -223,16 +579,72 @@ func (orm *MySQLORM) getQuerySelectDataset(params QueryParams) *goqu.SelectDatas
 	return selectDataset
 }
 
-func (orm *MySQLORM) Query(ctx context.Context, params QueryParams) error {
-	return orm.getQuerySelectDataset(params).ScanStructsContext(ctx, params.EntryList)
+func (orm *MySQLORM) Query(ctx context.Context, params QueryParams) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+
+	selectDataset := orm.getQuerySelectDataset(getReadDB(ctx, orm.db, orm.replicas), params)
+	if err := selectDataset.ScanStructsContext(ctx, params.EntryList); err != nil {
+		return err
+	}
+
+	return orm.afterLoad(ctx, params.EntryList)
+}
+
+func (orm *MySQLORM) QueryWithXLock(ctx context.Context, params QueryParams) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+
+	if err := orm.getQuerySelectDataset(orm.db, params).ForUpdate(goqu.Wait).ScanStructsContext(ctx, params.EntryList); err != nil {
+		return err
+	}
+
+	return orm.afterLoad(ctx, params.EntryList)
 }
 
-func (orm *MySQLORM) QueryWithXLock(ctx context.Context, params QueryParams) error {
-	return orm.getQuerySelectDataset(params).ForUpdate(goqu.Wait).ScanStructsContext(ctx, params.EntryList)
+func (orm *MySQLORM) Iterate(ctx context.Context, params QueryParams, iterateFunc IterateFunc) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+	readDB := getReadDB(ctx, orm.db, orm.replicas)
+
+	return iterate(ctx, orm, readDB, orm.entryInfoProvider, params, orm.getQuerySelectDataset(readDB, params), nil, iterateFunc)
 }
 
-func (orm *MySQLORM) Count(ctx context.Context, tableName string, expression exp.Expression) (int64, error) {
-	count, err := orm.GetDBWrapper().Select().From(tableName).Where(expression).CountContext(ctx)
+func (orm *MySQLORM) IterateWithXLock(ctx context.Context, params QueryParams, iterateFunc IterateFunc) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+
+	selectDataset := orm.getQuerySelectDataset(orm.db, params).ForUpdate(goqu.Wait)
+
+	return iterate(ctx, orm, orm.db, orm.entryInfoProvider, params, selectDataset, nil, iterateFunc)
+}
+
+func (orm *MySQLORM) QueryPage(ctx context.Context, params QueryParams, cursor Cursor) (nextCursor Cursor, err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	return queryPage(ctx, orm.entryInfoProvider, params, cursor, true, orm.Query)
+}
+
+func (orm *MySQLORM) Count(ctx context.Context, tableName string, expression exp.Expression) (count int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	countExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, countExpression)
+
+	count, err = getReadDB(ctx, orm.db, orm.replicas).
+		Select().
+		From(tableName).
+		Prepared(true).
+		Where(countExpression).
+		CountContext(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -240,12 +652,140 @@ func (orm *MySQLORM) Count(ctx context.Context, tableName string, expression exp
 	return count, nil
 }
 
-func (orm *MySQLORM) Update(ctx context.Context, entry interface{}) error {
+func (orm *MySQLORM) Sum(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	// The sum of no rows is 0 rather than NULL
+	sumExpression := goqu.COALESCE(goqu.SUM(goqu.C(column)), 0)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, sumExpression, scopedExpression, result)
+}
+
+func (orm *MySQLORM) Min(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, goqu.MIN(goqu.C(column)), scopedExpression, result)
+}
+
+func (orm *MySQLORM) Max(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, goqu.MAX(goqu.C(column)), scopedExpression, result)
+}
+
+func (orm *MySQLORM) Avg(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, goqu.AVG(goqu.C(column)), scopedExpression, result)
+}
+
+func (orm *MySQLORM) Exists(ctx context.Context, tableName string, expression exp.Expression) (found bool, err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return false, err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return exists(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, scopedExpression)
+}
+
+func (orm *MySQLORM) VerifySchema(ctx context.Context, models ...interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	return verifySchema(ctx, orm.db, orm.entryInfoProvider, DriverTypeMySQL, models)
+}
+
+func (orm *MySQLORM) Update(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	return withUpdateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*MySQLORM).update(ctx, entry, nil)
+	})
+}
+
+func (orm *MySQLORM) UpdateColumns(ctx context.Context, entry interface{}, columns ...string) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	if len(columns) == 0 {
+		return ErrColumnsExpected
+	}
+
+	return withUpdateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*MySQLORM).update(ctx, entry, columns)
+	})
+}
+
+// update updates all columns of entry, or only columns and the columns changed by OnUpdate() if columns is not nil
+func (orm *MySQLORM) update(ctx context.Context, entry interface{}, columns []string) error {
 	if entry == nil {
 		return ErrNilEntry
 	}
 
-	orm.entryInfoProvider.OnUpdateIfEntryIsOnCreator(entry)
+	var updateRecord exp.Record
+
+	if columns == nil {
+		orm.entryInfoProvider.OnUpdateIfEntryIsOnCreator(entry)
+	} else {
+		var err error
+		if updateRecord, err = orm.entryInfoProvider.OnUpdateColumns(entry, columns); err != nil {
+			return err
+		}
+	}
 
 	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
 	if err != nil {
@@ -257,11 +797,35 @@ func (orm *MySQLORM) Update(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
+	var (
+		updateExpression exp.Expression = selectEntryUniqueExpression
+		updateSource     interface{}    = entry
+	)
+
+	if updateRecord != nil {
+		updateSource = updateRecord
+	}
+
+	versionColumn, version, isVersioned := orm.entryInfoProvider.GetVersion(entry)
+	if isVersioned {
+		if updateRecord == nil {
+			if updateRecord, err = orm.entryInfoProvider.GetUpdateRecord(entry); err != nil {
+				return err
+			}
+		}
+
+		updateRecord[versionColumn] = version + 1
+		updateExpression = goqu.And(selectEntryUniqueExpression, goqu.C(versionColumn).Eq(version))
+		updateSource = updateRecord
+	}
+
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, entry)
+
 	result, err := orm.db.
 		Update(entryTableName).
 		Prepared(true).
-		Where(selectEntryUniqueExpression).
-		Set(entry).
+		Where(updateExpression).
+		Set(updateSource).
 		Executor().
 		ExecContext(ctx)
 	if err != nil {
@@ -274,25 +838,228 @@ func (orm *MySQLORM) Update(ctx context.Context, entry interface{}) error {
 	}
 
 	if rowsAffected == 0 {
+		if isVersioned {
+			return ErrStaleEntry
+		}
+
 		return ErrUpdateNotApplied
 	}
 
+	if isVersioned {
+		orm.entryInfoProvider.SetVersion(entry, version+1)
+	}
+
 	return nil
 }
 
+func (orm *MySQLORM) UpdateWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	return orm.updateWhere(ctx, tableName, expression, record, nil)
+}
+
+func (orm *MySQLORM) UpdateWhereWithLimit(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+	limit uint32,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	if isEmptyLimit(limit) {
+		return 0, nil
+	}
+
+	return orm.updateWhere(ctx, tableName, expression, record, &limit)
+}
+
+func (orm *MySQLORM) updateWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+	limit *uint32,
+) (int64, error) {
+	expression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = orm.redactor.bindRecord(ctx, tableName, record)
+	ctx = orm.redactor.bindExpression(ctx, tableName, expression)
+
+	updateDataset := orm.db.Update(tableName).Prepared(true).Where(expression).Set(record)
+
+	if limit != nil {
+		updateDataset = updateDataset.Limit(uint(*limit))
+	}
+
+	result, err := updateDataset.Executor().ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (orm *MySQLORM) DeleteWhere(ctx context.Context, tableName string, expression exp.Expression) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	return orm.deleteWhere(ctx, tableName, expression, nil)
+}
+
+func (orm *MySQLORM) DeleteWhereWithLimit(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	limit uint32,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	if isEmptyLimit(limit) {
+		return 0, nil
+	}
+
+	return orm.deleteWhere(ctx, tableName, expression, &limit)
+}
+
+func (orm *MySQLORM) deleteWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	limit *uint32,
+) (int64, error) {
+	// The rows of soft deleted tables are soft deleted, like by Delete()
+	softDeleteRecord, ok, err := getTableSoftDeleteRecord(orm.entryInfoProvider, tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	if ok {
+		return orm.updateWhere(ctx, tableName, expression, softDeleteRecord, limit)
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, expression)
+
+	deleteDataset := orm.db.Delete(tableName).Prepared(true).Where(expression)
+
+	if limit != nil {
+		deleteDataset = deleteDataset.Limit(uint(*limit))
+	}
+
+	result, err := deleteDataset.Executor().ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
 func (orm *MySQLORM) GetDBWrapper() DBWrapper {
 	return orm.db
 }
 
-func (orm *MySQLORM) WithTx(executeFunc func(ORM) error) error {
+func (orm *MySQLORM) Ping(ctx context.Context) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	return pingDatabases(ctx, orm.pool, orm.replicas)
+}
+
+func (orm *MySQLORM) HealthCheck(ctx context.Context) (status HealthStatus, err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	return checkHealth(ctx, DriverTypeMySQL, orm.pool, orm.replicas)
+}
+
+func (orm *MySQLORM) Close() (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	if _, ok := orm.db.(*goqu.Database); !ok {
+		return ErrCloseInTransaction
+	}
+
+	return closeDatabases(orm.pool, orm.ownsPool, orm.replicas)
+}
+
+func (orm *MySQLORM) Unscoped() ORM {
+	unscopedORM := *orm
+	unscopedORM.unscoped = true
+
+	return &unscopedORM
+}
+
+func (orm *MySQLORM) Preload(relationFields ...string) ORM {
+	preloadORM := *orm
+	preloadORM.preloads = append(append([]string{}, orm.preloads...), relationFields...)
+
+	return &preloadORM
+}
+
+// afterLoad preloads the relations of entries, a pointer to an entry or to a slice of entries, and runs their
+// AfterLoad() hooks
+func (orm *MySQLORM) afterLoad(ctx context.Context, entries interface{}) error {
+	if err := orm.preload(ctx, entries); err != nil {
+		return err
+	}
+
+	return runAfterLoadHooks(ctx, orm, entries)
+}
+
+// preload loads the relations passed to Preload() into entries, a pointer to an entry or to a slice of entries
+func (orm *MySQLORM) preload(ctx context.Context, entries interface{}) error {
+	if len(orm.preloads) == 0 {
+		return nil
+	}
+
+	queryORM := *orm
+	queryORM.preloads = nil
+
+	return preloadRelations(ctx, &queryORM, orm.entryInfoProvider, orm.databaseConfig, entries, orm.preloads)
+}
+
+func (orm *MySQLORM) WithTx(executeFunc func(ORM) error) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
+	return orm.WithTxContext(context.Background(), nil, func(_ context.Context, txORM ORM) error {
+		return executeFunc(txORM)
+	})
+}
+
+func (orm *MySQLORM) WithTxContext(
+	ctx context.Context,
+	opts *sql.TxOptions,
+	executeFunc func(context.Context, ORM) error,
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeMySQL, &err)
+
 	if nonTXDB, ok := orm.db.(*goqu.Database); ok {
-		return nonTXDB.WithTx(func(td *goqu.TxDatabase) error {
-			return executeFunc(&MySQLORM{
-				db:                td,
-				entryInfoProvider: orm.entryInfoProvider,
+		return withRetry(ctx, orm.databaseConfig.RetryPolicy, DriverTypeMySQL, func() error {
+			return withGoquTx(ctx, nonTXDB, opts, func(td *goqu.TxDatabase) error {
+				return executeFunc(ctx, &MySQLORM{
+					db:                    td,
+					pool:                  orm.pool,
+					replicas:              orm.replicas,
+					entryInfoProvider:     orm.entryInfoProvider,
+					redactor:              orm.redactor,
+					autoIncrementSettings: orm.autoIncrementSettings,
+					databaseConfig:        orm.databaseConfig,
+					unscoped:              orm.unscoped,
+				})
 			})
 		})
 	}
 
-	return executeFunc(orm)
+	savepointORM := *orm
+	savepointORM.savepointDepth++
+	savepointORM.preloads = nil
+
+	return withSavepoint(ctx, orm.db, DriverTypeMySQL, savepointORM.savepointDepth, func() error {
+		return executeFunc(ctx, &savepointORM)
+	})
 }
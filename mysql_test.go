@@ -1,11 +1,16 @@
 package miniorm
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"log"
 	"testing"
 
+	"github.com/doug-martin/goqu/v9"
 	_ "github.com/go-sql-driver/mysql" // For Mysql driver
 	"github.com/go-testfixtures/testfixtures/v3"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,7 +27,7 @@ var (
 )
 
 func prepareMySQLTestEntryTable(fixtureFile string) error {
-	db, err := newSQLDatabase(mysqlTestConfig)
+	db, err := NewSQLDatabase(mysqlTestConfig)
 	if err != nil {
 		return err
 	}
@@ -34,6 +39,8 @@ func prepareMySQLTestEntryTable(fixtureFile string) error {
 			bytes_col LONGBLOB NOT NULL,
 			on_create_count BIGINT NOT NULL,
 			on_update_count BIGINT NOT NULL,
+			version BIGINT NOT NULL DEFAULT 0,
+			deleted_at BIGINT NULL,
 			PRIMARY KEY (id)
 		) ENGINE=InnoDB;
 	`); err != nil {
@@ -48,12 +55,35 @@ func prepareMySQLTestEntryTable(fixtureFile string) error {
 			bytes_col LONGBLOB NOT NULL,
 			on_create_count BIGINT NOT NULL,
 			on_update_count BIGINT NOT NULL,
+			version BIGINT NOT NULL DEFAULT 0,
+			deleted_at BIGINT NULL,
 			PRIMARY KEY (id_1, id_2)
 		) ENGINE=InnoDB;
 	`); err != nil {
 		return err
 	}
 
+	if _, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS get_key_entries (
+			id VARCHAR(36) NOT NULL,
+			string_col TEXT NOT NULL,
+			PRIMARY KEY (id)
+		) ENGINE=InnoDB;
+	`); err != nil {
+		return err
+	}
+
+	if _, err = db.Exec(`
+		CREATE TABLE IF NOT EXISTS get_child_entries (
+			id BIGINT NOT NULL,
+			parent_id BIGINT NOT NULL,
+			string_col TEXT NOT NULL,
+			PRIMARY KEY (id)
+		) ENGINE=InnoDB;
+	`); err != nil {
+		return err
+	}
+
 	fixtures, err := testfixtures.New(
 		testfixtures.Database(db),
 		testfixtures.Dialect(string(DriverTypeMySQL)),
@@ -174,3 +204,432 @@ func TestMySQLWithTX(t *testing.T) {
 
 	testWithTX(t, orm)
 }
+
+func TestMySQLWithTxContext(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testWithTxContext(t, orm)
+}
+
+func TestMySQLNestedWithTx(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testNestedWithTx(t, orm)
+}
+
+func TestMySQLCreateOrUpdateUpsert(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mysqlTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	// MySQL's auto increment ID takes the current sequence value, so we had to decrease the starting value by one
+	testCreateOrUpdateUpsert(t, orm, testfixturesDefaultSequenceStart-1)
+}
+
+func TestMySQLCreateMany(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mysqlTestConfig
+	databaseConfig.CreateManyChunkSize = 2
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	// MySQL's auto increment ID takes the current sequence value, so we had to decrease the starting value by one
+	testCreateMany(t, orm, testfixturesDefaultSequenceStart-1)
+}
+
+func TestMySQLUpdateWhere(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testUpdateWhere(t, orm)
+}
+
+func TestMySQLDeleteWhere(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testDeleteWhere(t, orm)
+}
+
+func TestMySQLOptimisticLocking(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testOptimisticLocking(t, orm)
+}
+
+func TestMySQLOptimisticLockingUpsert(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mysqlTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testOptimisticLocking(t, orm)
+}
+
+func TestMySQLSoftDelete(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mysqlTestConfig
+	databaseConfig.Models = []interface{}{&softDeleteIDEntry{}}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testSoftDelete(t, orm)
+}
+
+func TestMySQLUnregisteredSoftDelete(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testUnregisteredSoftDelete(t, orm)
+}
+
+func TestMySQLTaggedModel(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	// MySQL's auto increment ID takes the current sequence value, so we had to decrease the starting value by one
+	testTaggedModel(t, orm, testfixturesDefaultSequenceStart-1)
+}
+
+func TestMySQLTaggedModelUpsert(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mysqlTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	// MySQL's auto increment ID takes the current sequence value, so we had to decrease the starting value by one
+	testTaggedModel(t, orm, testfixturesDefaultSequenceStart-1)
+}
+
+func TestMySQLKeys(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testKeys(t, orm)
+}
+
+func TestMySQLKeysUpsert(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mysqlTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testKeys(t, orm)
+}
+
+func TestMySQLGeneratedKeys(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testGeneratedKeysNotSupported(t, orm)
+}
+
+func TestMySQLWithTxRetryPolicy(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mysqlTestConfig
+	databaseConfig.RetryPolicy = NewExponentialBackoffRetryPolicy()
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testWithTX(t, orm)
+}
+
+func TestMySQLIterate(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testIterate(t, orm)
+}
+
+func TestMySQLIterateWithXLock(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testIterateWithXLock(t, orm)
+}
+
+func TestMySQLQueryPage(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testQueryPage(t, orm)
+}
+
+func TestMySQLQueryColumns(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testQueryColumns(t, orm)
+}
+
+func TestMySQLUpdateColumns(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testUpdateColumns(t, orm)
+}
+
+func TestMySQLAggregate(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testAggregate(t, orm)
+}
+
+func TestMySQLQueryGroupBy(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testQueryGroupBy(t, orm)
+}
+
+func TestMySQLPreload(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_preload.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mysqlTestConfig
+	databaseConfig.PreloadChunkSize = 2
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testPreload(t, orm)
+}
+
+func TestMySQLGenerateCreateTableStatement(t *testing.T) {
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testGenerateCreateTableStatement(t, orm, DriverTypeMySQL)
+}
+
+func TestMySQLVerifySchema(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testVerifySchema(t, orm, DriverTypeMySQL)
+}
+
+func TestMySQLHooks(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_hooks.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testHooks(t, orm)
+}
+
+func TestMySQLInstrumentation(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mysqlTestConfig
+	databaseConfig.Instrumentation = &recordingInstrumentation{}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testInstrumentation(t, orm)
+}
+
+func TestMySQLStructuredLogging(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := mysqlTestConfig
+	databaseConfig.StructuredLogger = &recordingStructuredLogger{}
+	databaseConfig.RedactedColumns = []string{"on_update_count"}
+	databaseConfig.Models = []interface{}{&sensitiveEntry{}}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testStructuredLogging(t, orm)
+}
+
+func TestMySQLHealth(t *testing.T) {
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testHealth(t, orm, DriverTypeMySQL)
+}
+
+func TestMySQLFromSQLDatabase(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	testFromSQLDatabase(t, mysqlTestConfig)
+}
+
+func TestMySQLWhereWithZeroLimit(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testWhereWithZeroLimit(t, orm)
+}
+
+func TestMySQLAggregateEmptyTable(t *testing.T) {
+	err := prepareMySQLTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(mysqlTestConfig)
+	assert.Nil(t, err)
+
+	testAggregateEmptyTable(t, orm)
+}
+
+func TestMySQLAutoIncrementSettings(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	// SQLite returns the rows of the settings, which are only read again after a failure
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	defer sqlDB.Close()
+
+	queryErr := errors.New("query error")
+	settingsQuery := "SELECT @@auto_increment_increment, @@innodb_autoinc_lock_mode"
+
+	db := NewMockDBWrapper(mockController)
+	gomock.InOrder(
+		db.EXPECT().QueryContext(gomock.Any(), settingsQuery).Return(nil, queryErr).Times(1),
+		db.EXPECT().QueryContext(gomock.Any(), settingsQuery).
+			DoAndReturn(func(ctx context.Context, _ string, _ ...interface{}) (*sql.Rows, error) {
+				return sqlDB.QueryContext(ctx, "SELECT 2, 2")
+			}).
+			Times(1),
+	)
+
+	settings := &mysqlAutoIncrementSettings{}
+
+	_, _, err = settings.get(context.Background(), db)
+	assert.ErrorIs(t, err, queryErr)
+
+	for i := 0; i < 2; i++ {
+		increment, lockMode, err := settings.get(context.Background(), db)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), increment)
+		assert.Equal(t, int64(mysqlInterleavedAutoIncrementLockMode), lockMode)
+	}
+}
+
+func TestMySQLOnDuplicateKeyUpdateSQLStatement(t *testing.T) {
+	t.Parallel()
+
+	orm := &MySQLORM{}
+
+	statement, params := orm.wrapInsertSQLStatementWithOnDuplicateKeyUpdate(
+		"INSERT INTO `entries` (`name`) VALUES (?)",
+		[]interface{}{"a"},
+		goqu.Record{"name": "a"},
+		[]string{"name"},
+		"id",
+	)
+	assert.Equal(
+		t,
+		"INSERT INTO `entries` (`name`) VALUES (?) ON DUPLICATE KEY UPDATE `name` = ?, `id` = ~LAST_INSERT_ID(~`id`)",
+		statement,
+	)
+	assert.Equal(t, []interface{}{"a", "a"}, params)
+
+	statement, params = orm.wrapInsertSQLStatementWithOnDuplicateKeyUpdate(
+		"INSERT INTO `entries` (`name`) VALUES (?)",
+		[]interface{}{"a"},
+		goqu.Record{},
+		[]string{"name"},
+		"",
+	)
+	assert.Equal(
+		t,
+		"INSERT INTO `entries` (`name`) VALUES (?) ON DUPLICATE KEY UPDATE `name` = IF(LAST_INSERT_ID(~0), `name`, `name`)",
+		statement,
+	)
+	assert.Equal(t, []interface{}{"a"}, params)
+}
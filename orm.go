@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exp"
@@ -24,10 +25,25 @@ type IDSetter interface {
 	SetID(id int64)
 }
 
+// KeyGetter is a generalization of IDGetter for keys of any type, e.g. UUIDs or strings
+type KeyGetter interface {
+	GetKey() (keyColumn string, keyValue interface{})
+}
+
+// KeySetter is a generalization of IDSetter for keys of any type generated by the database. The generated key is
+// scanned into keyDestination, which is usually a pointer to the key field.
+type KeySetter interface {
+	GetKeyDestination() (keyColumn string, keyDestination interface{})
+}
+
 type UniqueGetter interface {
 	GetUniqueExpression() goqu.Ex
 }
 
+type RelationsGetter interface {
+	GetRelations() []Relation
+}
+
 type OnCreator interface {
 	OnCreate()
 }
@@ -36,6 +52,48 @@ type OnUpdater interface {
 	OnUpdate()
 }
 
+// BeforeCreator and the other lifecycle hooks are called with the active ORM, which is in a transaction along with the
+// operation, so that a hook can write related rows atomically. An error returned by a hook aborts the operation.
+type BeforeCreator interface {
+	BeforeCreate(ctx context.Context, orm ORM) error
+}
+
+type AfterCreator interface {
+	AfterCreate(ctx context.Context, orm ORM) error
+}
+
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context, orm ORM) error
+}
+
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context, orm ORM) error
+}
+
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context, orm ORM) error
+}
+
+type AfterDeleter interface {
+	AfterDelete(ctx context.Context, orm ORM) error
+}
+
+// AfterLoader is called for every entry loaded by Get(), Query(), Iterate() and their variants, after preloading the
+// relations of the entry, except for Iterate() which does not preload
+type AfterLoader interface {
+	AfterLoad(ctx context.Context, orm ORM) error
+}
+
+type SoftDeleter interface {
+	GetSoftDeleteColumn() string
+	SetDeletedAt(deletedAt time.Time)
+}
+
+type Versioned interface {
+	GetVersion() (versionColumn string, version int64)
+	SetVersion(version int64)
+}
+
 type QueryParams struct {
 	TableName  string
 	EntryList  interface{}
@@ -43,6 +101,11 @@ type QueryParams struct {
 	OrderBy    []exp.OrderedExpression
 	Limit      *uint32
 	Offset     *uint32
+	// Columns restricts the selected columns, the other fields of the entries are left empty. All columns are selected
+	// if empty. Besides column names, expressions like goqu.SUM("col").As("total") can be selected.
+	Columns []interface{}
+	GroupBy []interface{}
+	Having  goqu.Expression
 }
 
 type DBWrapper interface {
@@ -60,22 +123,54 @@ type DBWrapper interface {
 
 type ORM interface {
 	Create(ctx context.Context, entry interface{}) error
+	CreateMany(ctx context.Context, entries interface{}) error
 	Get(ctx context.Context, entry interface{}) error
 	GetWithXLock(ctx context.Context, entry interface{}) error
 	Query(ctx context.Context, params QueryParams) error
 	QueryWithXLock(ctx context.Context, params QueryParams) error
+	Iterate(ctx context.Context, params QueryParams, iterateFunc IterateFunc) error
+	IterateWithXLock(ctx context.Context, params QueryParams, iterateFunc IterateFunc) error
+	QueryPage(ctx context.Context, params QueryParams, cursor Cursor) (nextCursor Cursor, err error)
 	Count(ctx context.Context, tableName string, expression goqu.Expression) (int64, error)
+	Sum(ctx context.Context, tableName string, column string, expression goqu.Expression, result interface{}) error
+	Min(ctx context.Context, tableName string, column string, expression goqu.Expression, result interface{}) error
+	Max(ctx context.Context, tableName string, column string, expression goqu.Expression, result interface{}) error
+	Avg(ctx context.Context, tableName string, column string, expression goqu.Expression, result interface{}) error
+	Exists(ctx context.Context, tableName string, expression goqu.Expression) (bool, error)
+	VerifySchema(ctx context.Context, models ...interface{}) error
 	Update(ctx context.Context, entry interface{}) error
+	UpdateColumns(ctx context.Context, entry interface{}, columns ...string) error
+	UpdateWhere(ctx context.Context, tableName string, expression goqu.Expression, record goqu.Record) (int64, error)
+	UpdateWhereWithLimit(
+		ctx context.Context,
+		tableName string,
+		expression goqu.Expression,
+		record goqu.Record,
+		limit uint32,
+	) (int64, error)
 	CreateOrUpdate(ctx context.Context, entry interface{}) error
 	Delete(ctx context.Context, entry interface{}) error
+	HardDelete(ctx context.Context, entry interface{}) error
+	DeleteWhere(ctx context.Context, tableName string, expression goqu.Expression) (int64, error)
+	DeleteWhereWithLimit(ctx context.Context, tableName string, expression goqu.Expression, limit uint32) (int64, error)
 	GetDBWrapper() DBWrapper
+	Ping(ctx context.Context) error
+	HealthCheck(ctx context.Context) (HealthStatus, error)
+	Close() error
+	Unscoped() ORM
+	Preload(relationFields ...string) ORM
 	WithTx(executeFunc func(ORM) error) error
+	WithTxContext(ctx context.Context, opts *sql.TxOptions, executeFunc func(context.Context, ORM) error) error
 }
 
 var (
 	ErrNilEntry         = errors.New("entry is nil")
 	ErrNotFound         = errors.New("entry not found")
 	ErrUpdateNotApplied = errors.New("update not applied")
+	ErrStaleEntry       = errors.New("entry was modified concurrently")
+
+	ErrColumnsExpected    = errors.New("expected at least one column")
+	ErrColumnNotUpdatable = errors.New("expected column to be an updatable field of entry")
 )
 
 func NewORM(databaseConfig DatabaseConfig) (ORM, error) {
@@ -92,3 +187,39 @@ func NewORM(databaseConfig DatabaseConfig) (ORM, error) {
 		return nil, errors.New("invalid driver type")
 	}
 }
+
+// NewORMFromSQLDatabase returns an ORM on db, an open database of the Driver of databaseConfig, e.g. a pool shared
+// with other libraries, opened with a custom connector, or an in-memory SQLite3 database in tests. The connection
+// settings of databaseConfig are ignored, and Close() leaves db open.
+func NewORMFromSQLDatabase(db *sql.DB, databaseConfig DatabaseConfig) (ORM, error) {
+	switch databaseConfig.Driver {
+	case DriverTypeMySQL:
+		return NewMySQLORMFromSQLDatabase(db, databaseConfig)
+	case DriverTypePostgres:
+		return NewPostgresORMFromSQLDatabase(db, databaseConfig)
+	case DriverTypeSQLite3:
+		return NewSQLite3ORMFromSQLDatabase(db, databaseConfig)
+	case DriverTypeMSSQL:
+		return NewMSSQLORMFromSQLDatabase(db, databaseConfig)
+	default:
+		return nil, errors.New("invalid driver type")
+	}
+}
+
+// NewORMFromGoquDatabase returns an ORM on goquDB, an open database with the dialect of the Driver of databaseConfig,
+// which executes the statements as configured by the caller, i.e. without the statement retries and logging of
+// databaseConfig. The connection settings of databaseConfig are ignored, and Close() leaves goquDB open.
+func NewORMFromGoquDatabase(goquDB *goqu.Database, databaseConfig DatabaseConfig) (ORM, error) {
+	switch databaseConfig.Driver {
+	case DriverTypeMySQL:
+		return NewMySQLORMFromGoquDatabase(goquDB, databaseConfig)
+	case DriverTypePostgres:
+		return NewPostgresORMFromGoquDatabase(goquDB, databaseConfig)
+	case DriverTypeSQLite3:
+		return NewSQLite3ORMFromGoquDatabase(goquDB, databaseConfig)
+	case DriverTypeMSSQL:
+		return NewMSSQLORMFromGoquDatabase(goquDB, databaseConfig)
+	default:
+		return nil, errors.New("invalid driver type")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	context "context"
 	sql "database/sql"
 	reflect "reflect"
+	time "time"
 
 	v9 "github.com/doug-martin/goqu/v9"
 	gomock "github.com/golang/mock/gomock"
@@ -123,6 +124,82 @@ func (mr *MockIDSetterMockRecorder) SetID(id interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetID", reflect.TypeOf((*MockIDSetter)(nil).SetID), id)
 }
 
+// MockKeyGetter is a mock of KeyGetter interface.
+type MockKeyGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyGetterMockRecorder
+}
+
+// MockKeyGetterMockRecorder is the mock recorder for MockKeyGetter.
+type MockKeyGetterMockRecorder struct {
+	mock *MockKeyGetter
+}
+
+// NewMockKeyGetter creates a new mock instance.
+func NewMockKeyGetter(ctrl *gomock.Controller) *MockKeyGetter {
+	mock := &MockKeyGetter{ctrl: ctrl}
+	mock.recorder = &MockKeyGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyGetter) EXPECT() *MockKeyGetterMockRecorder {
+	return m.recorder
+}
+
+// GetKey mocks base method.
+func (m *MockKeyGetter) GetKey() (string, interface{}) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKey")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(interface{})
+	return ret0, ret1
+}
+
+// GetKey indicates an expected call of GetKey.
+func (mr *MockKeyGetterMockRecorder) GetKey() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKey", reflect.TypeOf((*MockKeyGetter)(nil).GetKey))
+}
+
+// MockKeySetter is a mock of KeySetter interface.
+type MockKeySetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeySetterMockRecorder
+}
+
+// MockKeySetterMockRecorder is the mock recorder for MockKeySetter.
+type MockKeySetterMockRecorder struct {
+	mock *MockKeySetter
+}
+
+// NewMockKeySetter creates a new mock instance.
+func NewMockKeySetter(ctrl *gomock.Controller) *MockKeySetter {
+	mock := &MockKeySetter{ctrl: ctrl}
+	mock.recorder = &MockKeySetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeySetter) EXPECT() *MockKeySetterMockRecorder {
+	return m.recorder
+}
+
+// GetKeyDestination mocks base method.
+func (m *MockKeySetter) GetKeyDestination() (string, interface{}) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetKeyDestination")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(interface{})
+	return ret0, ret1
+}
+
+// GetKeyDestination indicates an expected call of GetKeyDestination.
+func (mr *MockKeySetterMockRecorder) GetKeyDestination() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetKeyDestination", reflect.TypeOf((*MockKeySetter)(nil).GetKeyDestination))
+}
+
 // MockUniqueGetter is a mock of UniqueGetter interface.
 type MockUniqueGetter struct {
 	ctrl     *gomock.Controller
@@ -160,6 +237,43 @@ func (mr *MockUniqueGetterMockRecorder) GetUniqueExpression() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUniqueExpression", reflect.TypeOf((*MockUniqueGetter)(nil).GetUniqueExpression))
 }
 
+// MockRelationsGetter is a mock of RelationsGetter interface.
+type MockRelationsGetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockRelationsGetterMockRecorder
+}
+
+// MockRelationsGetterMockRecorder is the mock recorder for MockRelationsGetter.
+type MockRelationsGetterMockRecorder struct {
+	mock *MockRelationsGetter
+}
+
+// NewMockRelationsGetter creates a new mock instance.
+func NewMockRelationsGetter(ctrl *gomock.Controller) *MockRelationsGetter {
+	mock := &MockRelationsGetter{ctrl: ctrl}
+	mock.recorder = &MockRelationsGetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRelationsGetter) EXPECT() *MockRelationsGetterMockRecorder {
+	return m.recorder
+}
+
+// GetRelations mocks base method.
+func (m *MockRelationsGetter) GetRelations() []Relation {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRelations")
+	ret0, _ := ret[0].([]Relation)
+	return ret0
+}
+
+// GetRelations indicates an expected call of GetRelations.
+func (mr *MockRelationsGetterMockRecorder) GetRelations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRelations", reflect.TypeOf((*MockRelationsGetter)(nil).GetRelations))
+}
+
 // MockOnCreator is a mock of OnCreator interface.
 type MockOnCreator struct {
 	ctrl     *gomock.Controller
@@ -230,6 +344,364 @@ func (mr *MockOnUpdaterMockRecorder) OnUpdate() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OnUpdate", reflect.TypeOf((*MockOnUpdater)(nil).OnUpdate))
 }
 
+// MockBeforeCreator is a mock of BeforeCreator interface.
+type MockBeforeCreator struct {
+	ctrl     *gomock.Controller
+	recorder *MockBeforeCreatorMockRecorder
+}
+
+// MockBeforeCreatorMockRecorder is the mock recorder for MockBeforeCreator.
+type MockBeforeCreatorMockRecorder struct {
+	mock *MockBeforeCreator
+}
+
+// NewMockBeforeCreator creates a new mock instance.
+func NewMockBeforeCreator(ctrl *gomock.Controller) *MockBeforeCreator {
+	mock := &MockBeforeCreator{ctrl: ctrl}
+	mock.recorder = &MockBeforeCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBeforeCreator) EXPECT() *MockBeforeCreatorMockRecorder {
+	return m.recorder
+}
+
+// BeforeCreate mocks base method.
+func (m *MockBeforeCreator) BeforeCreate(ctx context.Context, orm ORM) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeforeCreate", ctx, orm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeforeCreate indicates an expected call of BeforeCreate.
+func (mr *MockBeforeCreatorMockRecorder) BeforeCreate(ctx, orm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeforeCreate", reflect.TypeOf((*MockBeforeCreator)(nil).BeforeCreate), ctx, orm)
+}
+
+// MockAfterCreator is a mock of AfterCreator interface.
+type MockAfterCreator struct {
+	ctrl     *gomock.Controller
+	recorder *MockAfterCreatorMockRecorder
+}
+
+// MockAfterCreatorMockRecorder is the mock recorder for MockAfterCreator.
+type MockAfterCreatorMockRecorder struct {
+	mock *MockAfterCreator
+}
+
+// NewMockAfterCreator creates a new mock instance.
+func NewMockAfterCreator(ctrl *gomock.Controller) *MockAfterCreator {
+	mock := &MockAfterCreator{ctrl: ctrl}
+	mock.recorder = &MockAfterCreatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAfterCreator) EXPECT() *MockAfterCreatorMockRecorder {
+	return m.recorder
+}
+
+// AfterCreate mocks base method.
+func (m *MockAfterCreator) AfterCreate(ctx context.Context, orm ORM) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AfterCreate", ctx, orm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AfterCreate indicates an expected call of AfterCreate.
+func (mr *MockAfterCreatorMockRecorder) AfterCreate(ctx, orm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AfterCreate", reflect.TypeOf((*MockAfterCreator)(nil).AfterCreate), ctx, orm)
+}
+
+// MockBeforeUpdater is a mock of BeforeUpdater interface.
+type MockBeforeUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockBeforeUpdaterMockRecorder
+}
+
+// MockBeforeUpdaterMockRecorder is the mock recorder for MockBeforeUpdater.
+type MockBeforeUpdaterMockRecorder struct {
+	mock *MockBeforeUpdater
+}
+
+// NewMockBeforeUpdater creates a new mock instance.
+func NewMockBeforeUpdater(ctrl *gomock.Controller) *MockBeforeUpdater {
+	mock := &MockBeforeUpdater{ctrl: ctrl}
+	mock.recorder = &MockBeforeUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBeforeUpdater) EXPECT() *MockBeforeUpdaterMockRecorder {
+	return m.recorder
+}
+
+// BeforeUpdate mocks base method.
+func (m *MockBeforeUpdater) BeforeUpdate(ctx context.Context, orm ORM) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeforeUpdate", ctx, orm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeforeUpdate indicates an expected call of BeforeUpdate.
+func (mr *MockBeforeUpdaterMockRecorder) BeforeUpdate(ctx, orm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeforeUpdate", reflect.TypeOf((*MockBeforeUpdater)(nil).BeforeUpdate), ctx, orm)
+}
+
+// MockAfterUpdater is a mock of AfterUpdater interface.
+type MockAfterUpdater struct {
+	ctrl     *gomock.Controller
+	recorder *MockAfterUpdaterMockRecorder
+}
+
+// MockAfterUpdaterMockRecorder is the mock recorder for MockAfterUpdater.
+type MockAfterUpdaterMockRecorder struct {
+	mock *MockAfterUpdater
+}
+
+// NewMockAfterUpdater creates a new mock instance.
+func NewMockAfterUpdater(ctrl *gomock.Controller) *MockAfterUpdater {
+	mock := &MockAfterUpdater{ctrl: ctrl}
+	mock.recorder = &MockAfterUpdaterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAfterUpdater) EXPECT() *MockAfterUpdaterMockRecorder {
+	return m.recorder
+}
+
+// AfterUpdate mocks base method.
+func (m *MockAfterUpdater) AfterUpdate(ctx context.Context, orm ORM) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AfterUpdate", ctx, orm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AfterUpdate indicates an expected call of AfterUpdate.
+func (mr *MockAfterUpdaterMockRecorder) AfterUpdate(ctx, orm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AfterUpdate", reflect.TypeOf((*MockAfterUpdater)(nil).AfterUpdate), ctx, orm)
+}
+
+// MockBeforeDeleter is a mock of BeforeDeleter interface.
+type MockBeforeDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockBeforeDeleterMockRecorder
+}
+
+// MockBeforeDeleterMockRecorder is the mock recorder for MockBeforeDeleter.
+type MockBeforeDeleterMockRecorder struct {
+	mock *MockBeforeDeleter
+}
+
+// NewMockBeforeDeleter creates a new mock instance.
+func NewMockBeforeDeleter(ctrl *gomock.Controller) *MockBeforeDeleter {
+	mock := &MockBeforeDeleter{ctrl: ctrl}
+	mock.recorder = &MockBeforeDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBeforeDeleter) EXPECT() *MockBeforeDeleterMockRecorder {
+	return m.recorder
+}
+
+// BeforeDelete mocks base method.
+func (m *MockBeforeDeleter) BeforeDelete(ctx context.Context, orm ORM) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeforeDelete", ctx, orm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeforeDelete indicates an expected call of BeforeDelete.
+func (mr *MockBeforeDeleterMockRecorder) BeforeDelete(ctx, orm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeforeDelete", reflect.TypeOf((*MockBeforeDeleter)(nil).BeforeDelete), ctx, orm)
+}
+
+// MockAfterDeleter is a mock of AfterDeleter interface.
+type MockAfterDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockAfterDeleterMockRecorder
+}
+
+// MockAfterDeleterMockRecorder is the mock recorder for MockAfterDeleter.
+type MockAfterDeleterMockRecorder struct {
+	mock *MockAfterDeleter
+}
+
+// NewMockAfterDeleter creates a new mock instance.
+func NewMockAfterDeleter(ctrl *gomock.Controller) *MockAfterDeleter {
+	mock := &MockAfterDeleter{ctrl: ctrl}
+	mock.recorder = &MockAfterDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAfterDeleter) EXPECT() *MockAfterDeleterMockRecorder {
+	return m.recorder
+}
+
+// AfterDelete mocks base method.
+func (m *MockAfterDeleter) AfterDelete(ctx context.Context, orm ORM) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AfterDelete", ctx, orm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AfterDelete indicates an expected call of AfterDelete.
+func (mr *MockAfterDeleterMockRecorder) AfterDelete(ctx, orm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AfterDelete", reflect.TypeOf((*MockAfterDeleter)(nil).AfterDelete), ctx, orm)
+}
+
+// MockAfterLoader is a mock of AfterLoader interface.
+type MockAfterLoader struct {
+	ctrl     *gomock.Controller
+	recorder *MockAfterLoaderMockRecorder
+}
+
+// MockAfterLoaderMockRecorder is the mock recorder for MockAfterLoader.
+type MockAfterLoaderMockRecorder struct {
+	mock *MockAfterLoader
+}
+
+// NewMockAfterLoader creates a new mock instance.
+func NewMockAfterLoader(ctrl *gomock.Controller) *MockAfterLoader {
+	mock := &MockAfterLoader{ctrl: ctrl}
+	mock.recorder = &MockAfterLoaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAfterLoader) EXPECT() *MockAfterLoaderMockRecorder {
+	return m.recorder
+}
+
+// AfterLoad mocks base method.
+func (m *MockAfterLoader) AfterLoad(ctx context.Context, orm ORM) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AfterLoad", ctx, orm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AfterLoad indicates an expected call of AfterLoad.
+func (mr *MockAfterLoaderMockRecorder) AfterLoad(ctx, orm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AfterLoad", reflect.TypeOf((*MockAfterLoader)(nil).AfterLoad), ctx, orm)
+}
+
+// MockSoftDeleter is a mock of SoftDeleter interface.
+type MockSoftDeleter struct {
+	ctrl     *gomock.Controller
+	recorder *MockSoftDeleterMockRecorder
+}
+
+// MockSoftDeleterMockRecorder is the mock recorder for MockSoftDeleter.
+type MockSoftDeleterMockRecorder struct {
+	mock *MockSoftDeleter
+}
+
+// NewMockSoftDeleter creates a new mock instance.
+func NewMockSoftDeleter(ctrl *gomock.Controller) *MockSoftDeleter {
+	mock := &MockSoftDeleter{ctrl: ctrl}
+	mock.recorder = &MockSoftDeleterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSoftDeleter) EXPECT() *MockSoftDeleterMockRecorder {
+	return m.recorder
+}
+
+// GetSoftDeleteColumn mocks base method.
+func (m *MockSoftDeleter) GetSoftDeleteColumn() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSoftDeleteColumn")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetSoftDeleteColumn indicates an expected call of GetSoftDeleteColumn.
+func (mr *MockSoftDeleterMockRecorder) GetSoftDeleteColumn() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSoftDeleteColumn", reflect.TypeOf((*MockSoftDeleter)(nil).GetSoftDeleteColumn))
+}
+
+// SetDeletedAt mocks base method.
+func (m *MockSoftDeleter) SetDeletedAt(deletedAt time.Time) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetDeletedAt", deletedAt)
+}
+
+// SetDeletedAt indicates an expected call of SetDeletedAt.
+func (mr *MockSoftDeleterMockRecorder) SetDeletedAt(deletedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDeletedAt", reflect.TypeOf((*MockSoftDeleter)(nil).SetDeletedAt), deletedAt)
+}
+
+// MockVersioned is a mock of Versioned interface.
+type MockVersioned struct {
+	ctrl     *gomock.Controller
+	recorder *MockVersionedMockRecorder
+}
+
+// MockVersionedMockRecorder is the mock recorder for MockVersioned.
+type MockVersionedMockRecorder struct {
+	mock *MockVersioned
+}
+
+// NewMockVersioned creates a new mock instance.
+func NewMockVersioned(ctrl *gomock.Controller) *MockVersioned {
+	mock := &MockVersioned{ctrl: ctrl}
+	mock.recorder = &MockVersionedMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockVersioned) EXPECT() *MockVersionedMockRecorder {
+	return m.recorder
+}
+
+// GetVersion mocks base method.
+func (m *MockVersioned) GetVersion() (string, int64) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVersion")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(int64)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockVersionedMockRecorder) GetVersion() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockVersioned)(nil).GetVersion))
+}
+
+// SetVersion mocks base method.
+func (m *MockVersioned) SetVersion(version int64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetVersion", version)
+}
+
+// SetVersion indicates an expected call of SetVersion.
+func (mr *MockVersionedMockRecorder) SetVersion(version interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVersion", reflect.TypeOf((*MockVersioned)(nil).SetVersion), version)
+}
+
 // MockDBWrapper is a mock of DBWrapper interface.
 type MockDBWrapper struct {
 	ctrl     *gomock.Controller
@@ -452,6 +924,34 @@ func (m *MockORM) EXPECT() *MockORMMockRecorder {
 	return m.recorder
 }
 
+// Avg mocks base method.
+func (m *MockORM) Avg(ctx context.Context, tableName, column string, expression v9.Expression, result interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Avg", ctx, tableName, column, expression, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Avg indicates an expected call of Avg.
+func (mr *MockORMMockRecorder) Avg(ctx, tableName, column, expression, result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Avg", reflect.TypeOf((*MockORM)(nil).Avg), ctx, tableName, column, expression, result)
+}
+
+// Close mocks base method.
+func (m *MockORM) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockORMMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockORM)(nil).Close))
+}
+
 // Count mocks base method.
 func (m *MockORM) Count(ctx context.Context, tableName string, expression v9.Expression) (int64, error) {
 	m.ctrl.T.Helper()
@@ -481,6 +981,20 @@ func (mr *MockORMMockRecorder) Create(ctx, entry interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockORM)(nil).Create), ctx, entry)
 }
 
+// CreateMany mocks base method.
+func (m *MockORM) CreateMany(ctx context.Context, entries interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMany", ctx, entries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateMany indicates an expected call of CreateMany.
+func (mr *MockORMMockRecorder) CreateMany(ctx, entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMany", reflect.TypeOf((*MockORM)(nil).CreateMany), ctx, entries)
+}
+
 // CreateOrUpdate mocks base method.
 func (m *MockORM) CreateOrUpdate(ctx context.Context, entry interface{}) error {
 	m.ctrl.T.Helper()
@@ -509,6 +1023,51 @@ func (mr *MockORMMockRecorder) Delete(ctx, entry interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockORM)(nil).Delete), ctx, entry)
 }
 
+// DeleteWhere mocks base method.
+func (m *MockORM) DeleteWhere(ctx context.Context, tableName string, expression v9.Expression) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWhere", ctx, tableName, expression)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWhere indicates an expected call of DeleteWhere.
+func (mr *MockORMMockRecorder) DeleteWhere(ctx, tableName, expression interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWhere", reflect.TypeOf((*MockORM)(nil).DeleteWhere), ctx, tableName, expression)
+}
+
+// DeleteWhereWithLimit mocks base method.
+func (m *MockORM) DeleteWhereWithLimit(ctx context.Context, tableName string, expression v9.Expression, limit uint32) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWhereWithLimit", ctx, tableName, expression, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWhereWithLimit indicates an expected call of DeleteWhereWithLimit.
+func (mr *MockORMMockRecorder) DeleteWhereWithLimit(ctx, tableName, expression, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWhereWithLimit", reflect.TypeOf((*MockORM)(nil).DeleteWhereWithLimit), ctx, tableName, expression, limit)
+}
+
+// Exists mocks base method.
+func (m *MockORM) Exists(ctx context.Context, tableName string, expression v9.Expression) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", ctx, tableName, expression)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exists indicates an expected call of Exists.
+func (mr *MockORMMockRecorder) Exists(ctx, tableName, expression interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockORM)(nil).Exists), ctx, tableName, expression)
+}
+
 // Get mocks base method.
 func (m *MockORM) Get(ctx context.Context, entry interface{}) error {
 	m.ctrl.T.Helper()
@@ -551,6 +1110,123 @@ func (mr *MockORMMockRecorder) GetWithXLock(ctx, entry interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithXLock", reflect.TypeOf((*MockORM)(nil).GetWithXLock), ctx, entry)
 }
 
+// HardDelete mocks base method.
+func (m *MockORM) HardDelete(ctx context.Context, entry interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HardDelete", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HardDelete indicates an expected call of HardDelete.
+func (mr *MockORMMockRecorder) HardDelete(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HardDelete", reflect.TypeOf((*MockORM)(nil).HardDelete), ctx, entry)
+}
+
+// HealthCheck mocks base method.
+func (m *MockORM) HealthCheck(ctx context.Context) (HealthStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HealthCheck", ctx)
+	ret0, _ := ret[0].(HealthStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HealthCheck indicates an expected call of HealthCheck.
+func (mr *MockORMMockRecorder) HealthCheck(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthCheck", reflect.TypeOf((*MockORM)(nil).HealthCheck), ctx)
+}
+
+// Iterate mocks base method.
+func (m *MockORM) Iterate(ctx context.Context, params QueryParams, iterateFunc IterateFunc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Iterate", ctx, params, iterateFunc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Iterate indicates an expected call of Iterate.
+func (mr *MockORMMockRecorder) Iterate(ctx, params, iterateFunc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Iterate", reflect.TypeOf((*MockORM)(nil).Iterate), ctx, params, iterateFunc)
+}
+
+// IterateWithXLock mocks base method.
+func (m *MockORM) IterateWithXLock(ctx context.Context, params QueryParams, iterateFunc IterateFunc) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IterateWithXLock", ctx, params, iterateFunc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IterateWithXLock indicates an expected call of IterateWithXLock.
+func (mr *MockORMMockRecorder) IterateWithXLock(ctx, params, iterateFunc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IterateWithXLock", reflect.TypeOf((*MockORM)(nil).IterateWithXLock), ctx, params, iterateFunc)
+}
+
+// Max mocks base method.
+func (m *MockORM) Max(ctx context.Context, tableName, column string, expression v9.Expression, result interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Max", ctx, tableName, column, expression, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Max indicates an expected call of Max.
+func (mr *MockORMMockRecorder) Max(ctx, tableName, column, expression, result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Max", reflect.TypeOf((*MockORM)(nil).Max), ctx, tableName, column, expression, result)
+}
+
+// Min mocks base method.
+func (m *MockORM) Min(ctx context.Context, tableName, column string, expression v9.Expression, result interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Min", ctx, tableName, column, expression, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Min indicates an expected call of Min.
+func (mr *MockORMMockRecorder) Min(ctx, tableName, column, expression, result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Min", reflect.TypeOf((*MockORM)(nil).Min), ctx, tableName, column, expression, result)
+}
+
+// Ping mocks base method.
+func (m *MockORM) Ping(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockORMMockRecorder) Ping(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockORM)(nil).Ping), ctx)
+}
+
+// Preload mocks base method.
+func (m *MockORM) Preload(relationFields ...string) ORM {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range relationFields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Preload", varargs...)
+	ret0, _ := ret[0].(ORM)
+	return ret0
+}
+
+// Preload indicates an expected call of Preload.
+func (mr *MockORMMockRecorder) Preload(relationFields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preload", reflect.TypeOf((*MockORM)(nil).Preload), relationFields...)
+}
+
 // Query mocks base method.
 func (m *MockORM) Query(ctx context.Context, params QueryParams) error {
 	m.ctrl.T.Helper()
@@ -565,6 +1241,21 @@ func (mr *MockORMMockRecorder) Query(ctx, params interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockORM)(nil).Query), ctx, params)
 }
 
+// QueryPage mocks base method.
+func (m *MockORM) QueryPage(ctx context.Context, params QueryParams, cursor Cursor) (Cursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryPage", ctx, params, cursor)
+	ret0, _ := ret[0].(Cursor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryPage indicates an expected call of QueryPage.
+func (mr *MockORMMockRecorder) QueryPage(ctx, params, cursor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryPage", reflect.TypeOf((*MockORM)(nil).QueryPage), ctx, params, cursor)
+}
+
 // QueryWithXLock mocks base method.
 func (m *MockORM) QueryWithXLock(ctx context.Context, params QueryParams) error {
 	m.ctrl.T.Helper()
@@ -579,6 +1270,34 @@ func (mr *MockORMMockRecorder) QueryWithXLock(ctx, params interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryWithXLock", reflect.TypeOf((*MockORM)(nil).QueryWithXLock), ctx, params)
 }
 
+// Sum mocks base method.
+func (m *MockORM) Sum(ctx context.Context, tableName, column string, expression v9.Expression, result interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sum", ctx, tableName, column, expression, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sum indicates an expected call of Sum.
+func (mr *MockORMMockRecorder) Sum(ctx, tableName, column, expression, result interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sum", reflect.TypeOf((*MockORM)(nil).Sum), ctx, tableName, column, expression, result)
+}
+
+// Unscoped mocks base method.
+func (m *MockORM) Unscoped() ORM {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unscoped")
+	ret0, _ := ret[0].(ORM)
+	return ret0
+}
+
+// Unscoped indicates an expected call of Unscoped.
+func (mr *MockORMMockRecorder) Unscoped() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unscoped", reflect.TypeOf((*MockORM)(nil).Unscoped))
+}
+
 // Update mocks base method.
 func (m *MockORM) Update(ctx context.Context, entry interface{}) error {
 	m.ctrl.T.Helper()
@@ -593,6 +1312,74 @@ func (mr *MockORMMockRecorder) Update(ctx, entry interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockORM)(nil).Update), ctx, entry)
 }
 
+// UpdateColumns mocks base method.
+func (m *MockORM) UpdateColumns(ctx context.Context, entry interface{}, columns ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, entry}
+	for _, a := range columns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateColumns", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateColumns indicates an expected call of UpdateColumns.
+func (mr *MockORMMockRecorder) UpdateColumns(ctx, entry interface{}, columns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, entry}, columns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateColumns", reflect.TypeOf((*MockORM)(nil).UpdateColumns), varargs...)
+}
+
+// UpdateWhere mocks base method.
+func (m *MockORM) UpdateWhere(ctx context.Context, tableName string, expression v9.Expression, record v9.Record) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWhere", ctx, tableName, expression, record)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWhere indicates an expected call of UpdateWhere.
+func (mr *MockORMMockRecorder) UpdateWhere(ctx, tableName, expression, record interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWhere", reflect.TypeOf((*MockORM)(nil).UpdateWhere), ctx, tableName, expression, record)
+}
+
+// UpdateWhereWithLimit mocks base method.
+func (m *MockORM) UpdateWhereWithLimit(ctx context.Context, tableName string, expression v9.Expression, record v9.Record, limit uint32) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWhereWithLimit", ctx, tableName, expression, record, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWhereWithLimit indicates an expected call of UpdateWhereWithLimit.
+func (mr *MockORMMockRecorder) UpdateWhereWithLimit(ctx, tableName, expression, record, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWhereWithLimit", reflect.TypeOf((*MockORM)(nil).UpdateWhereWithLimit), ctx, tableName, expression, record, limit)
+}
+
+// VerifySchema mocks base method.
+func (m *MockORM) VerifySchema(ctx context.Context, models ...interface{}) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range models {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "VerifySchema", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifySchema indicates an expected call of VerifySchema.
+func (mr *MockORMMockRecorder) VerifySchema(ctx interface{}, models ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, models...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifySchema", reflect.TypeOf((*MockORM)(nil).VerifySchema), varargs...)
+}
+
 // WithTx mocks base method.
 func (m *MockORM) WithTx(executeFunc func(ORM) error) error {
 	m.ctrl.T.Helper()
@@ -606,3 +1393,17 @@ func (mr *MockORMMockRecorder) WithTx(executeFunc interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockORM)(nil).WithTx), executeFunc)
 }
+
+// WithTxContext mocks base method.
+func (m *MockORM) WithTxContext(ctx context.Context, opts *sql.TxOptions, executeFunc func(context.Context, ORM) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTxContext", ctx, opts, executeFunc)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTxContext indicates an expected call of WithTxContext.
+func (mr *MockORMMockRecorder) WithTxContext(ctx, opts, executeFunc interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTxContext", reflect.TypeOf((*MockORM)(nil).WithTxContext), ctx, opts, executeFunc)
+}
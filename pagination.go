@@ -0,0 +1,339 @@
+package miniorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+var (
+	ErrInvalidCursor         = errors.New("cursor is invalid or does not match the order of the query")
+	ErrOrderByColumnExpected = errors.New("expected OrderBy to only contain columns for keyset pagination")
+	ErrOffsetNotSupported    = errors.New("offset cannot be combined with keyset pagination")
+	ErrNullCursorValue       = errors.New("columns of keyset pagination must not be null")
+)
+
+// Cursor is an opaque position in the results of QueryPage(), which can be passed to clients as is. The empty cursor
+// points to the first page.
+type Cursor string
+
+// keysetColumn is a column ordering the results of keyset pagination
+type keysetColumn struct {
+	identifier exp.IdentifierExpression
+	name       string
+	isAsc      bool
+}
+
+// cursorName returns the name of the column in a cursor, which includes the direction of the order
+func (column keysetColumn) cursorName() string {
+	if column.isAsc {
+		return column.name
+	}
+
+	return "-" + column.name
+}
+
+// cursorData is the encoded content of a Cursor: the ordered columns and their values in the last row of the page
+type cursorData struct {
+	Columns []string      `json:"c"`
+	Values  []cursorValue `json:"v"`
+}
+
+// cursorValue keeps the type of a driver.Value through JSON encoding, all fields are nil for NULL
+type cursorValue struct {
+	Int    *int64     `json:"i,omitempty"`
+	Float  *float64   `json:"f,omitempty"`
+	Bool   *bool      `json:"b,omitempty"`
+	Bytes  *[]byte    `json:"y,omitempty"`
+	String *string    `json:"s,omitempty"`
+	Time   *time.Time `json:"t,omitempty"`
+}
+
+func newCursorValue(value driver.Value) cursorValue {
+	switch value := value.(type) {
+	case int64:
+		return cursorValue{Int: &value}
+	case float64:
+		return cursorValue{Float: &value}
+	case bool:
+		return cursorValue{Bool: &value}
+	case []byte:
+		return cursorValue{Bytes: &value}
+	case string:
+		return cursorValue{String: &value}
+	case time.Time:
+		return cursorValue{Time: &value}
+	default:
+		return cursorValue{}
+	}
+}
+
+func (value cursorValue) get() interface{} {
+	switch {
+	case value.Int != nil:
+		return *value.Int
+	case value.Float != nil:
+		return *value.Float
+	case value.Bool != nil:
+		return *value.Bool
+	case value.Bytes != nil:
+		return *value.Bytes
+	case value.String != nil:
+		return *value.String
+	case value.Time != nil:
+		return *value.Time
+	default:
+		return nil
+	}
+}
+
+// queryPage runs queryFunc for the page of params after cursor, ordered by params.OrderBy followed by the key columns
+// of the entries, and returns the cursor of the next page, which is empty if there are no more rows. useRowValues is
+// set if the database engine supports row value comparisons like (a, b) > (?, ?).
+func queryPage(
+	ctx context.Context,
+	entryInfoProvider *entryInfoProvider,
+	params QueryParams,
+	cursor Cursor,
+	useRowValues bool,
+	queryFunc func(ctx context.Context, params QueryParams) error,
+) (Cursor, error) {
+	if params.Offset != nil {
+		return "", ErrOffsetNotSupported
+	}
+
+	keysetColumns, err := getKeysetColumns(entryInfoProvider, params)
+	if err != nil {
+		return "", err
+	}
+
+	if cursor != "" {
+		cursorValues, err := decodeCursor(cursor, keysetColumns)
+		if err != nil {
+			return "", err
+		}
+
+		seekExpression := getKeysetSeekExpression(keysetColumns, cursorValues, useRowValues)
+		if params.Expression == nil {
+			params.Expression = seekExpression
+		} else {
+			params.Expression = goqu.And(params.Expression, seekExpression)
+		}
+	}
+
+	// The cursor of the next page is read from the keyset columns of the last entry, so they have to be selected
+	if len(params.Columns) > 0 {
+		params.Columns = getKeysetSelectColumns(params.Columns, keysetColumns)
+	}
+
+	params.OrderBy = make([]exp.OrderedExpression, 0, len(keysetColumns))
+	for _, keysetColumn := range keysetColumns {
+		if keysetColumn.isAsc {
+			params.OrderBy = append(params.OrderBy, keysetColumn.identifier.Asc())
+		} else {
+			params.OrderBy = append(params.OrderBy, keysetColumn.identifier.Desc())
+		}
+	}
+
+	entryListValue := reflect.ValueOf(params.EntryList).Elem()
+	previousLength := entryListValue.Len()
+
+	if err := queryFunc(ctx, params); err != nil {
+		return "", err
+	}
+
+	entryListValue = reflect.ValueOf(params.EntryList).Elem()
+
+	rowCount := entryListValue.Len() - previousLength
+	if params.Limit == nil || rowCount == 0 || rowCount < int(*params.Limit) {
+		return "", nil
+	}
+
+	return encodeCursor(keysetColumns, entryListValue.Index(entryListValue.Len()-1).Interface())
+}
+
+// getKeysetColumns returns the columns of params.OrderBy, followed by the key columns of the entries of
+// params.EntryList in ascending order, unless already ordered by, so that the order of the rows is total
+func getKeysetColumns(entryInfoProvider *entryInfoProvider, params QueryParams) ([]keysetColumn, error) {
+	entryType, ok := entryInfoProvider.GetEntryListEntryType(params.EntryList)
+	if !ok || reflect.TypeOf(params.EntryList).Kind() != reflect.Ptr {
+		return nil, ErrSliceExpected
+	}
+
+	if entryType.Kind() == reflect.Ptr {
+		entryType = entryType.Elem()
+	}
+
+	keyColumns, err := entryInfoProvider.GetEntrySelectColumns(reflect.New(entryType).Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	keysetColumns := make([]keysetColumn, 0, len(params.OrderBy)+len(keyColumns))
+	orderedColumns := make(map[string]bool, len(params.OrderBy))
+
+	for _, orderedExpression := range params.OrderBy {
+		identifier, ok := orderedExpression.SortExpression().(exp.IdentifierExpression)
+		if !ok {
+			return nil, ErrOrderByColumnExpected
+		}
+
+		name, ok := identifier.GetCol().(string)
+		if !ok || name == "" || name == "*" {
+			return nil, ErrOrderByColumnExpected
+		}
+
+		keysetColumns = append(keysetColumns, keysetColumn{identifier: identifier, name: name, isAsc: orderedExpression.IsAsc()})
+		orderedColumns[name] = true
+	}
+
+	for _, keyColumn := range keyColumns {
+		if !orderedColumns[keyColumn] {
+			keysetColumns = append(keysetColumns, keysetColumn{identifier: goqu.C(keyColumn), name: keyColumn, isAsc: true})
+		}
+	}
+
+	return keysetColumns, nil
+}
+
+// getKeysetSelectColumns returns columns, followed by the names of the keyset columns which are not yet selected
+func getKeysetSelectColumns(columns []interface{}, keysetColumns []keysetColumn) []interface{} {
+	selectColumns := append(make([]interface{}, 0, len(columns)+len(keysetColumns)), columns...)
+
+	for _, keysetColumn := range keysetColumns {
+		isSelected := false
+		for _, column := range columns {
+			isSelected = isSelected || column == keysetColumn.name
+		}
+
+		if !isSelected {
+			selectColumns = append(selectColumns, keysetColumn.name)
+		}
+	}
+
+	return selectColumns
+}
+
+// getKeysetSeekExpression returns the expression selecting the rows after the row with cursorValues, either as a row
+// value comparison, which is only possible if all columns are ordered in the same direction, or expanded into
+// (a > ?) OR (a = ? AND b > ?) ...
+func getKeysetSeekExpression(keysetColumns []keysetColumn, cursorValues []interface{}, useRowValues bool) exp.Expression {
+	for _, keysetColumn := range keysetColumns {
+		if keysetColumn.isAsc != keysetColumns[0].isAsc {
+			useRowValues = false
+		}
+	}
+
+	if useRowValues && len(keysetColumns) > 1 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(keysetColumns)), ", ")
+		operator := ">"
+		if !keysetColumns[0].isAsc {
+			operator = "<"
+		}
+
+		args := make([]interface{}, 0, len(keysetColumns)*2)
+		for _, keysetColumn := range keysetColumns {
+			args = append(args, keysetColumn.identifier)
+		}
+
+		args = append(args, cursorValues...)
+
+		return goqu.L("("+placeholders+") "+operator+" ("+placeholders+")", args...)
+	}
+
+	seekExpressions := make([]exp.Expression, 0, len(keysetColumns))
+	for i, keysetColumn := range keysetColumns {
+		columnExpressions := make([]exp.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			columnExpressions = append(columnExpressions, keysetColumns[j].identifier.Eq(cursorValues[j]))
+		}
+
+		if keysetColumn.isAsc {
+			columnExpressions = append(columnExpressions, keysetColumn.identifier.Gt(cursorValues[i]))
+		} else {
+			columnExpressions = append(columnExpressions, keysetColumn.identifier.Lt(cursorValues[i]))
+		}
+
+		seekExpressions = append(seekExpressions, goqu.And(columnExpressions...))
+	}
+
+	return goqu.Or(seekExpressions...)
+}
+
+// encodeCursor returns the cursor pointing after entry, from the values of the keyset columns
+func encodeCursor(keysetColumns []keysetColumn, entry interface{}) (Cursor, error) {
+	record, err := exp.NewRecordFromStruct(reflect.Indirect(reflect.ValueOf(entry)).Interface(), false, false)
+	if err != nil {
+		return "", err
+	}
+
+	data := cursorData{
+		Columns: make([]string, 0, len(keysetColumns)),
+		Values:  make([]cursorValue, 0, len(keysetColumns)),
+	}
+
+	for _, keysetColumn := range keysetColumns {
+		value, ok := record[keysetColumn.name]
+		if !ok {
+			return "", ErrOrderByColumnExpected
+		}
+
+		driverValue, err := driver.DefaultParameterConverter.ConvertValue(value)
+		if err != nil {
+			return "", err
+		}
+
+		if driverValue == nil {
+			return "", ErrNullCursorValue
+		}
+
+		data.Columns = append(data.Columns, keysetColumn.cursorName())
+		data.Values = append(data.Values, newCursorValue(driverValue))
+	}
+
+	encodedData, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	return Cursor(base64.RawURLEncoding.EncodeToString(encodedData)), nil
+}
+
+// decodeCursor returns the values of the keyset columns in cursor, ErrInvalidCursor is returned if cursor cannot be
+// decoded or was created for other columns
+func decodeCursor(cursor Cursor, keysetColumns []keysetColumn) ([]interface{}, error) {
+	encodedData, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var data cursorData
+	if err := json.Unmarshal(encodedData, &data); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if len(data.Columns) != len(keysetColumns) || len(data.Values) != len(keysetColumns) {
+		return nil, ErrInvalidCursor
+	}
+
+	cursorValues := make([]interface{}, 0, len(keysetColumns))
+
+	for i, keysetColumn := range keysetColumns {
+		value := data.Values[i].get()
+		if data.Columns[i] != keysetColumn.cursorName() || value == nil {
+			return nil, ErrInvalidCursor
+		}
+
+		cursorValues = append(cursorValues, value)
+	}
+
+	return cursorValues, nil
+}
@@ -0,0 +1,83 @@
+package miniorm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetKeysetSeekExpression(t *testing.T) {
+	t.Parallel()
+
+	ascendingColumns := []keysetColumn{
+		{identifier: goqu.C("a"), name: "a", isAsc: true},
+		{identifier: goqu.C("id"), name: "id", isAsc: true},
+	}
+	mixedColumns := []keysetColumn{
+		{identifier: goqu.C("a"), name: "a", isAsc: false},
+		{identifier: goqu.C("id"), name: "id", isAsc: true},
+	}
+	cursorValues := []interface{}{int64(1), int64(2)}
+
+	testCaseList := []struct {
+		keysetColumns []keysetColumn
+		useRowValues  bool
+		expectedSQL   string
+	}{
+		{ascendingColumns, true, `SELECT * FROM "t" WHERE ("a", "id") > (1, 2)`},
+		{ascendingColumns, false, `SELECT * FROM "t" WHERE (("a" > 1) OR (("a" = 1) AND ("id" > 2)))`},
+		{mixedColumns, true, `SELECT * FROM "t" WHERE (("a" < 1) OR (("a" = 1) AND ("id" > 2)))`},
+		{ascendingColumns[1:], true, `SELECT * FROM "t" WHERE ("id" > 1)`},
+	}
+
+	for _, testCase := range testCaseList {
+		seekExpression := getKeysetSeekExpression(testCase.keysetColumns, cursorValues, testCase.useRowValues)
+		sql, _, err := goqu.From("t").Where(seekExpression).ToSQL()
+		assert.Nil(t, err)
+		assert.Equal(t, testCase.expectedSQL, sql)
+	}
+}
+
+func TestEncodeCursor(t *testing.T) {
+	t.Parallel()
+
+	entry := &struct {
+		ID        int64     `db:"id"`
+		Name      string    `db:"name"`
+		CreatedAt time.Time `db:"created_at"`
+		Data      []byte    `db:"data"`
+		Nullable  *string   `db:"nullable"`
+	}{ID: 3, Name: "name", CreatedAt: time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC), Data: []byte("data")}
+
+	keysetColumns := []keysetColumn{
+		{identifier: goqu.C("created_at"), name: "created_at", isAsc: false},
+		{identifier: goqu.C("name"), name: "name", isAsc: true},
+		{identifier: goqu.C("data"), name: "data", isAsc: true},
+		{identifier: goqu.C("id"), name: "id", isAsc: true},
+	}
+
+	cursor, err := encodeCursor(keysetColumns, entry)
+	assert.Nil(t, err)
+
+	cursorValues, err := decodeCursor(cursor, keysetColumns)
+	assert.Nil(t, err)
+	assert.Equal(t, []interface{}{entry.CreatedAt, "name", []byte("data"), int64(3)}, cursorValues)
+
+	keysetColumns[0].isAsc = true
+	_, err = decodeCursor(cursor, keysetColumns)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = decodeCursor(cursor, keysetColumns[1:])
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = decodeCursor("!", keysetColumns)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = encodeCursor([]keysetColumn{{identifier: goqu.C("nullable"), name: "nullable", isAsc: true}}, entry)
+	assert.ErrorIs(t, err, ErrNullCursorValue)
+
+	_, err = encodeCursor([]keysetColumn{{identifier: goqu.C("unknown"), name: "unknown", isAsc: true}}, entry)
+	assert.ErrorIs(t, err, ErrOrderByColumnExpected)
+}
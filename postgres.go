@@ -3,6 +3,8 @@ package miniorm
 
 import (
 	"context"
+	"database/sql"
+	"strings"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exp"
@@ -10,28 +12,88 @@ import (
 
 type PostgresORM struct {
 	db                DBWrapper
+	pool              connectionPool
+	ownsPool          bool
+	replicas          *replicaSet
 	entryInfoProvider *entryInfoProvider
+	redactor          *logRedactor
+	databaseConfig    DatabaseConfig
+	savepointDepth    uint
+	unscoped          bool
+	preloads          []string
 }
 
 func NewPostgresORM(databaseConfig DatabaseConfig) (ORM, error) {
-	goquDB, err := newGoquDatabase(databaseConfig)
+	goquDB, sqlDB, err := newGoquDatabase(databaseConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	goquDB.Logger(databaseConfig.Logger)
+	orm, err := newPostgresORM(goquDB, sqlDB, true, databaseConfig)
+	if err != nil {
+		_ = sqlDB.Close()
+	}
+
+	return orm, err
+}
+
+// NewPostgresORMFromSQLDatabase is NewORMFromSQLDatabase for Postgres
+func NewPostgresORMFromSQLDatabase(db *sql.DB, databaseConfig DatabaseConfig) (ORM, error) {
+	databaseConfig.Driver = DriverTypePostgres
+
+	if err := waitForDatabase(db, databaseConfig); err != nil {
+		return nil, err
+	}
+
+	return newPostgresORM(newGoquDatabaseFromSQLDatabase(db, databaseConfig), db, false, databaseConfig)
+}
+
+// NewPostgresORMFromGoquDatabase is NewORMFromGoquDatabase for Postgres
+func NewPostgresORMFromGoquDatabase(goquDB *goqu.Database, databaseConfig DatabaseConfig) (ORM, error) {
+	databaseConfig.Driver = DriverTypePostgres
+
+	pool, err := getGoquConnectionPool(goquDB, databaseConfig.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitForDatabase(pool, databaseConfig); err != nil {
+		return nil, err
+	}
+
+	return newPostgresORM(instrumentGoquDatabase(goquDB, databaseConfig), pool, false, databaseConfig)
+}
 
-	return &PostgresORM{
+func newPostgresORM(goquDB *goqu.Database, pool connectionPool, ownsPool bool, databaseConfig DatabaseConfig) (ORM, error) {
+	replicas, err := newReplicaSet(databaseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInstrumentedORM(&PostgresORM{
 		db:                goquDB,
-		entryInfoProvider: newEntryInfoProvider(),
-	}, nil
+		pool:              pool,
+		ownsPool:          ownsPool,
+		replicas:          replicas,
+		entryInfoProvider: newEntryInfoProvider(databaseConfig.Models...),
+		redactor:          newLogRedactor(databaseConfig),
+		databaseConfig:    databaseConfig,
+	}, databaseConfig), nil
 }
 
-func (orm *PostgresORM) Create(ctx context.Context, entry interface{}) error {
+func (orm *PostgresORM) Create(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
 
+	return withCreateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*PostgresORM).create(ctx, entry)
+	})
+}
+
+func (orm *PostgresORM) create(ctx context.Context, entry interface{}) error {
 	orm.entryInfoProvider.OnCreateIfEntryIsOnCreator(entry)
 
 	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
@@ -39,21 +101,17 @@ func (orm *PostgresORM) Create(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
-	insertDataset := orm.GetDBWrapper().Insert(entryTableName).Prepared(true).Rows(entry)
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, entry)
 
-	var (
-		idColumn string
-		idValue  int64
-	)
+	insertDataset := orm.GetDBWrapper().Insert(entryTableName).Prepared(true).Rows(entry)
 
-	idSetterEntry, isIDSetterEntry := entry.(IDSetter)
-	if isIDSetterEntry {
-		idColumn, _, err = orm.entryInfoProvider.GetID(entry)
-		if err != nil {
-			return err
+	keyColumn, keyDestination, isKeySetterEntry := orm.entryInfoProvider.GetKeyDestination(entry)
+	if isKeySetterEntry {
+		if keyColumn == "" {
+			return ErrKeyGetterExpected
 		}
 
-		insertDataset = insertDataset.Returning(idColumn)
+		insertDataset = insertDataset.Returning(keyColumn)
 	}
 
 	rows, err := insertDataset.Executor().QueryContext(ctx)
@@ -63,44 +121,108 @@ func (orm *PostgresORM) Create(ctx context.Context, entry interface{}) error {
 
 	defer rows.Close()
 
-	if isIDSetterEntry {
+	if isKeySetterEntry {
 		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
 			return ErrNotFound
 		}
 
-		if err := rows.Scan(&idValue); err != nil {
+		if err := rows.Scan(keyDestination); err != nil {
 			return err
 		}
-
-		idSetterEntry.SetID(idValue)
 	}
 
 	return nil
 }
 
-func (orm *PostgresORM) CreateOrUpdate(ctx context.Context, entry interface{}) error {
+func (orm *PostgresORM) CreateMany(ctx context.Context, entries interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	return createMany(ctx, orm, orm.entryInfoProvider, orm.databaseConfig, entries, orm.createChunk)
+}
+
+// createChunk inserts entryList in a single multi-row INSERT, the keys are returned in the order of the inserted rows
+func (orm *PostgresORM) createChunk(ctx context.Context, db DBWrapper, tableName string, entryList []interface{}) error {
+	ctx = orm.redactor.bindEntries(ctx, tableName, entryList...)
+
+	insertDataset := db.Insert(tableName).Prepared(true).Rows(entryList...)
+
+	keyColumn, _, isKeySetterEntry := orm.entryInfoProvider.GetKeyDestination(entryList[0])
+	if !isKeySetterEntry {
+		_, err := insertDataset.Executor().ExecContext(ctx)
+		return err
+	}
+
+	if keyColumn == "" {
+		return ErrKeyGetterExpected
+	}
+
+	rows, err := insertDataset.Returning(keyColumn).Executor().QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for _, entry := range entryList {
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			return ErrNotFound
+		}
+
+		_, keyDestination, _ := orm.entryInfoProvider.GetKeyDestination(entry)
+		if err := rows.Scan(keyDestination); err != nil {
+			return err
+		}
+	}
+
+	return rows.Close()
+}
+
+func (orm *PostgresORM) CreateOrUpdate(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
 
-	return orm.WithTx(func(txORM ORM) error {
+	// The hooks of entry depend on whether it is created or updated, which the upsert statements cannot tell beforehand
+	if orm.databaseConfig.CreateOrUpdateMode == CreateOrUpdateModeUpsert && !hasCreateOrUpdateHooks(entry) {
+		if uniqueColumns, ok := orm.entryInfoProvider.GetUniqueColumns(entry); ok {
+			if createEntry, updateEntry, ok := orm.entryInfoProvider.GetUpsertEntries(entry); ok {
+				return orm.upsert(ctx, entry, createEntry, updateEntry, uniqueColumns)
+			}
+		}
+	}
+
+	return orm.WithTxContext(ctx, nil, func(txCtx context.Context, txORM ORM) error {
 		entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
 		if err != nil {
 			return err
 		}
 
-		selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
+		// Soft deleted rows are included, so that they are restored by the update rather than conflicting with the insert
+		selectEntryExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
 		if err != nil {
 			return err
 		}
 
+		txCtx = orm.redactor.bindExpression(txCtx, entryTableName, selectEntryExpression)
+
 		rows, err := txORM.GetDBWrapper().
 			Select().
 			From(entryTableName).
-			Where(selectEntryUniqueExpression).
+			Prepared(true).
+			Where(selectEntryExpression).
 			ForUpdate(goqu.Wait).
 			Executor().
-			QueryContext(ctx)
+			QueryContext(txCtx)
 		if err != nil {
 			return err
 		}
@@ -113,14 +235,102 @@ func (orm *PostgresORM) CreateOrUpdate(ctx context.Context, entry interface{}) e
 		}
 
 		if !rowExists {
-			return txORM.Create(ctx, entry)
+			return txORM.Create(txCtx, entry)
+		}
+
+		return txORM.Update(txCtx, entry)
+	})
+}
+
+// upsert creates or updates entry in a single INSERT ... ON CONFLICT DO UPDATE statement. Whether the row was
+// inserted is derived from the system column xmax, which is 0 for newly inserted rows.
+func (orm *PostgresORM) upsert(
+	ctx context.Context,
+	entry, createEntry, updateEntry interface{},
+	uniqueColumns []string,
+) error {
+	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, createEntry, updateEntry)
+
+	var inserted bool
+
+	returning := []interface{}{goqu.L(`"xmax" = 0`)}
+	scanDestinations := []interface{}{&inserted}
+
+	keyColumn, _, isKeySetterEntry := orm.entryInfoProvider.GetKeyDestination(entry)
+	if isKeySetterEntry {
+		if keyColumn == "" {
+			return ErrKeyGetterExpected
+		}
+
+		// It is only known after scanning whether createEntry or updateEntry is kept, so the key is scanned into both
+		_, createKeyDestination, _ := orm.entryInfoProvider.GetKeyDestination(createEntry)
+		_, updateKeyDestination, _ := orm.entryInfoProvider.GetKeyDestination(updateEntry)
+
+		returning = append(returning, goqu.C(keyColumn), goqu.C(keyColumn))
+		scanDestinations = append(scanDestinations, createKeyDestination, updateKeyDestination)
+	}
+
+	rows, err := orm.GetDBWrapper().
+		Insert(entryTableName).
+		Prepared(true).
+		Rows(createEntry).
+		OnConflict(goqu.DoUpdate(strings.Join(uniqueColumns, ", "), updateEntry)).
+		Returning(returning...).
+		Executor().
+		QueryContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return ErrUpdateNotApplied
+	}
+
+	if err := rows.Scan(scanDestinations...); err != nil {
+		return err
+	}
+
+	if inserted {
+		orm.entryInfoProvider.SetEntry(entry, createEntry)
+	} else {
+		orm.entryInfoProvider.SetEntry(entry, updateEntry)
+	}
+
+	return nil
+}
+
+func (orm *PostgresORM) Delete(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	return withDeleteHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		if softDeleteColumn, ok := orm.entryInfoProvider.GetSoftDeleteColumn(entry); ok {
+			return softDelete(ctx, hookORM, orm.entryInfoProvider, entry, softDeleteColumn, orm.unscoped)
 		}
 
-		return txORM.Update(ctx, entry)
+		return hookORM.(*PostgresORM).hardDelete(ctx, entry)
+	})
+}
+
+func (orm *PostgresORM) HardDelete(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	return withDeleteHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*PostgresORM).hardDelete(ctx, entry)
 	})
 }
 
-func (orm *PostgresORM) Delete(ctx context.Context, entry interface{}) error {
+func (orm *PostgresORM) hardDelete(ctx context.Context, entry interface{}) error {
 	if entry == nil {
 		return ErrNilEntry
 	}
@@ -135,8 +345,11 @@ func (orm *PostgresORM) Delete(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
+	ctx = orm.redactor.bindExpression(ctx, entryTableName, selectEntryUniqueExpression)
+
 	result, err := orm.db.
 		Delete(entryTableName).
+		Prepared(true).
 		Where(selectEntryUniqueExpression).
 		Executor().
 		ExecContext(ctx)
@@ -156,7 +369,9 @@ func (orm *PostgresORM) Delete(ctx context.Context, entry interface{}) error {
 	return nil
 }
 
-func (orm *PostgresORM) Get(ctx context.Context, entry interface{}) error {
+func (orm *PostgresORM) Get(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
@@ -166,15 +381,18 @@ func (orm *PostgresORM) Get(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
-	selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
+	selectEntryExpression, err := orm.entryInfoProvider.GetEntryScopedSelectExpression(entry, orm.unscoped)
 	if err != nil {
 		return err
 	}
 
-	found, err := orm.db.
+	ctx = orm.redactor.bindExpression(ctx, entryTableName, selectEntryExpression)
+
+	found, err := getReadDB(ctx, orm.db, orm.replicas).
 		Select().
 		From(entryTableName).
-		Where(selectEntryUniqueExpression).
+		Prepared(true).
+		Where(selectEntryExpression).
 		Limit(1).
 		ScanStructContext(ctx, entry)
 	if err != nil {
@@ -185,10 +403,12 @@ func (orm *PostgresORM) Get(ctx context.Context, entry interface{}) error {
 		return ErrNotFound
 	}
 
-	return nil
+	return orm.afterLoad(ctx, entry)
 }
 
-func (orm *PostgresORM) GetWithXLock(ctx context.Context, entry interface{}) error {
+func (orm *PostgresORM) GetWithXLock(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
@@ -198,15 +418,18 @@ func (orm *PostgresORM) GetWithXLock(ctx context.Context, entry interface{}) err
 		return err
 	}
 
-	selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
+	selectEntryExpression, err := orm.entryInfoProvider.GetEntryScopedSelectExpression(entry, orm.unscoped)
 	if err != nil {
 		return err
 	}
 
+	ctx = orm.redactor.bindExpression(ctx, entryTableName, selectEntryExpression)
+
 	found, err := orm.GetDBWrapper().
 		Select().
 		From(entryTableName).
-		Where(selectEntryUniqueExpression).
+		Prepared(true).
+		Where(selectEntryExpression).
 		ForUpdate(goqu.Wait).
 		Executor().
 		ScanStructContext(ctx, entry)
@@ -218,11 +441,24 @@ func (orm *PostgresORM) GetWithXLock(ctx context.Context, entry interface{}) err
 		return ErrNotFound
 	}
 
-	return nil
+	return orm.afterLoad(ctx, entry)
 }
 
-func (orm *PostgresORM) getQuerySelectDataset(params QueryParams) *goqu.SelectDataset {
-	selectDataset := orm.db.Select().From(params.TableName).Where(params.Expression).Order(params.OrderBy...)
+func (orm *PostgresORM) getQuerySelectDataset(db DBWrapper, params QueryParams) *goqu.SelectDataset {
+	queryExpression := getQueryScopedExpression(orm.entryInfoProvider, params, orm.unscoped)
+	selectDataset := db.Select().From(params.TableName).Prepared(true).Where(queryExpression).Order(params.OrderBy...)
+
+	if len(params.Columns) > 0 {
+		selectDataset = selectDataset.Select(params.Columns...)
+	}
+
+	if len(params.GroupBy) > 0 {
+		selectDataset = selectDataset.GroupBy(params.GroupBy...)
+	}
+
+	if params.Having != nil {
+		selectDataset = selectDataset.Having(params.Having)
+	}
 
 	if params.Offset != nil {
 		selectDataset = selectDataset.Offset(uint(*params.Offset))
@@ -235,16 +471,72 @@ func (orm *PostgresORM) getQuerySelectDataset(params QueryParams) *goqu.SelectDa
 	return selectDataset
 }
 
-func (orm *PostgresORM) Query(ctx context.Context, params QueryParams) error {
-	return orm.getQuerySelectDataset(params).ScanStructsContext(ctx, params.EntryList)
+func (orm *PostgresORM) Query(ctx context.Context, params QueryParams) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+
+	selectDataset := orm.getQuerySelectDataset(getReadDB(ctx, orm.db, orm.replicas), params)
+	if err := selectDataset.ScanStructsContext(ctx, params.EntryList); err != nil {
+		return err
+	}
+
+	return orm.afterLoad(ctx, params.EntryList)
+}
+
+func (orm *PostgresORM) QueryWithXLock(ctx context.Context, params QueryParams) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+
+	if err := orm.getQuerySelectDataset(orm.db, params).ForUpdate(goqu.Wait).ScanStructsContext(ctx, params.EntryList); err != nil {
+		return err
+	}
+
+	return orm.afterLoad(ctx, params.EntryList)
+}
+
+func (orm *PostgresORM) Iterate(ctx context.Context, params QueryParams, iterateFunc IterateFunc) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+	readDB := getReadDB(ctx, orm.db, orm.replicas)
+
+	return iterate(ctx, orm, readDB, orm.entryInfoProvider, params, orm.getQuerySelectDataset(readDB, params), nil, iterateFunc)
+}
+
+func (orm *PostgresORM) IterateWithXLock(ctx context.Context, params QueryParams, iterateFunc IterateFunc) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+
+	selectDataset := orm.getQuerySelectDataset(orm.db, params).ForUpdate(goqu.Wait)
+
+	return iterate(ctx, orm, orm.db, orm.entryInfoProvider, params, selectDataset, nil, iterateFunc)
 }
 
-func (orm *PostgresORM) QueryWithXLock(ctx context.Context, params QueryParams) error {
-	return orm.getQuerySelectDataset(params).ForUpdate(goqu.Wait).ScanStructsContext(ctx, params.EntryList)
+func (orm *PostgresORM) QueryPage(ctx context.Context, params QueryParams, cursor Cursor) (nextCursor Cursor, err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	return queryPage(ctx, orm.entryInfoProvider, params, cursor, true, orm.Query)
 }
 
-func (orm *PostgresORM) Count(ctx context.Context, tableName string, expression exp.Expression) (int64, error) {
-	count, err := orm.GetDBWrapper().Select().From(tableName).Where(expression).CountContext(ctx)
+func (orm *PostgresORM) Count(ctx context.Context, tableName string, expression exp.Expression) (count int64, err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	countExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, countExpression)
+
+	count, err = getReadDB(ctx, orm.db, orm.replicas).
+		Select().
+		From(tableName).
+		Prepared(true).
+		Where(countExpression).
+		CountContext(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -252,12 +544,140 @@ func (orm *PostgresORM) Count(ctx context.Context, tableName string, expression
 	return count, nil
 }
 
-func (orm *PostgresORM) Update(ctx context.Context, entry interface{}) error {
+func (orm *PostgresORM) Sum(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	// The sum of no rows is 0 rather than NULL
+	sumExpression := goqu.COALESCE(goqu.SUM(goqu.C(column)), 0)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, sumExpression, scopedExpression, result)
+}
+
+func (orm *PostgresORM) Min(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, goqu.MIN(goqu.C(column)), scopedExpression, result)
+}
+
+func (orm *PostgresORM) Max(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, goqu.MAX(goqu.C(column)), scopedExpression, result)
+}
+
+func (orm *PostgresORM) Avg(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, goqu.AVG(goqu.C(column)), scopedExpression, result)
+}
+
+func (orm *PostgresORM) Exists(ctx context.Context, tableName string, expression exp.Expression) (found bool, err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return false, err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return exists(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, scopedExpression)
+}
+
+func (orm *PostgresORM) VerifySchema(ctx context.Context, models ...interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	return verifySchema(ctx, orm.db, orm.entryInfoProvider, DriverTypePostgres, models)
+}
+
+func (orm *PostgresORM) Update(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	return withUpdateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*PostgresORM).update(ctx, entry, nil)
+	})
+}
+
+func (orm *PostgresORM) UpdateColumns(ctx context.Context, entry interface{}, columns ...string) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	if len(columns) == 0 {
+		return ErrColumnsExpected
+	}
+
+	return withUpdateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*PostgresORM).update(ctx, entry, columns)
+	})
+}
+
+// update updates all columns of entry, or only columns and the columns changed by OnUpdate() if columns is not nil
+func (orm *PostgresORM) update(ctx context.Context, entry interface{}, columns []string) error {
 	if entry == nil {
 		return ErrNilEntry
 	}
 
-	orm.entryInfoProvider.OnUpdateIfEntryIsOnCreator(entry)
+	var updateRecord exp.Record
+
+	if columns == nil {
+		orm.entryInfoProvider.OnUpdateIfEntryIsOnCreator(entry)
+	} else {
+		var err error
+		if updateRecord, err = orm.entryInfoProvider.OnUpdateColumns(entry, columns); err != nil {
+			return err
+		}
+	}
 
 	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
 	if err != nil {
@@ -269,11 +689,35 @@ func (orm *PostgresORM) Update(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
+	var (
+		updateExpression exp.Expression = selectEntryUniqueExpression
+		updateSource     interface{}    = entry
+	)
+
+	if updateRecord != nil {
+		updateSource = updateRecord
+	}
+
+	versionColumn, version, isVersioned := orm.entryInfoProvider.GetVersion(entry)
+	if isVersioned {
+		if updateRecord == nil {
+			if updateRecord, err = orm.entryInfoProvider.GetUpdateRecord(entry); err != nil {
+				return err
+			}
+		}
+
+		updateRecord[versionColumn] = version + 1
+		updateExpression = goqu.And(selectEntryUniqueExpression, goqu.C(versionColumn).Eq(version))
+		updateSource = updateRecord
+	}
+
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, entry)
+
 	result, err := orm.db.
 		Update(entryTableName).
 		Prepared(true).
-		Where(selectEntryUniqueExpression).
-		Set(entry).
+		Where(updateExpression).
+		Set(updateSource).
 		Executor().
 		ExecContext(ctx)
 	if err != nil {
@@ -286,25 +730,247 @@ func (orm *PostgresORM) Update(ctx context.Context, entry interface{}) error {
 	}
 
 	if rowsAffected == 0 {
+		if isVersioned {
+			return ErrStaleEntry
+		}
+
 		return ErrUpdateNotApplied
 	}
 
+	if isVersioned {
+		orm.entryInfoProvider.SetVersion(entry, version+1)
+	}
+
 	return nil
 }
 
+func (orm *PostgresORM) UpdateWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	return orm.updateWhere(ctx, tableName, expression, record, nil)
+}
+
+func (orm *PostgresORM) UpdateWhereWithLimit(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+	limit uint32,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	if isEmptyLimit(limit) {
+		return 0, nil
+	}
+
+	return orm.updateWhere(ctx, tableName, expression, record, &limit)
+}
+
+// getLimitedWhereExpression returns the expression matching at most limit rows of the table.
+// PostgreSQL does not support LIMIT in UPDATE and DELETE statements, so the rows are selected by their
+// physical location (ctid) in a sub-query instead
+func (orm *PostgresORM) getLimitedWhereExpression(
+	tableName string,
+	expression exp.Expression,
+	limit *uint32,
+) exp.Expression {
+	if limit == nil {
+		return expression
+	}
+
+	// The sub-query is passed as a literal, since goqu wraps the operand of IN with an extra pair of parentheses,
+	// which would turn it into a scalar sub-query
+	return goqu.L(
+		"? IN ?",
+		goqu.C("ctid"),
+		orm.db.Select(goqu.C("ctid")).From(tableName).Where(expression).Limit(uint(*limit)),
+	)
+}
+
+func (orm *PostgresORM) updateWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+	limit *uint32,
+) (int64, error) {
+	expression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = orm.redactor.bindRecord(ctx, tableName, record)
+	ctx = orm.redactor.bindExpression(ctx, tableName, expression)
+
+	result, err := orm.db.
+		Update(tableName).
+		Prepared(true).
+		Where(orm.getLimitedWhereExpression(tableName, expression, limit)).
+		Set(record).
+		Executor().
+		ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (orm *PostgresORM) DeleteWhere(ctx context.Context, tableName string, expression exp.Expression) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	return orm.deleteWhere(ctx, tableName, expression, nil)
+}
+
+func (orm *PostgresORM) DeleteWhereWithLimit(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	limit uint32,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	if isEmptyLimit(limit) {
+		return 0, nil
+	}
+
+	return orm.deleteWhere(ctx, tableName, expression, &limit)
+}
+
+func (orm *PostgresORM) deleteWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	limit *uint32,
+) (int64, error) {
+	// The rows of soft deleted tables are soft deleted, like by Delete()
+	softDeleteRecord, ok, err := getTableSoftDeleteRecord(orm.entryInfoProvider, tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	if ok {
+		return orm.updateWhere(ctx, tableName, expression, softDeleteRecord, limit)
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, expression)
+
+	result, err := orm.db.
+		Delete(tableName).
+		Prepared(true).
+		Where(orm.getLimitedWhereExpression(tableName, expression, limit)).
+		Executor().
+		ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
 func (orm *PostgresORM) GetDBWrapper() DBWrapper {
 	return orm.db
 }
 
-func (orm *PostgresORM) WithTx(executeFunc func(ORM) error) error {
+func (orm *PostgresORM) Ping(ctx context.Context) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	return pingDatabases(ctx, orm.pool, orm.replicas)
+}
+
+func (orm *PostgresORM) HealthCheck(ctx context.Context) (status HealthStatus, err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	return checkHealth(ctx, DriverTypePostgres, orm.pool, orm.replicas)
+}
+
+func (orm *PostgresORM) Close() (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	if _, ok := orm.db.(*goqu.Database); !ok {
+		return ErrCloseInTransaction
+	}
+
+	return closeDatabases(orm.pool, orm.ownsPool, orm.replicas)
+}
+
+func (orm *PostgresORM) Unscoped() ORM {
+	unscopedORM := *orm
+	unscopedORM.unscoped = true
+
+	return &unscopedORM
+}
+
+func (orm *PostgresORM) Preload(relationFields ...string) ORM {
+	preloadORM := *orm
+	preloadORM.preloads = append(append([]string{}, orm.preloads...), relationFields...)
+
+	return &preloadORM
+}
+
+// afterLoad preloads the relations of entries, a pointer to an entry or to a slice of entries, and runs their
+// AfterLoad() hooks
+func (orm *PostgresORM) afterLoad(ctx context.Context, entries interface{}) error {
+	if err := orm.preload(ctx, entries); err != nil {
+		return err
+	}
+
+	return runAfterLoadHooks(ctx, orm, entries)
+}
+
+// preload loads the relations passed to Preload() into entries, a pointer to an entry or to a slice of entries
+func (orm *PostgresORM) preload(ctx context.Context, entries interface{}) error {
+	if len(orm.preloads) == 0 {
+		return nil
+	}
+
+	queryORM := *orm
+	queryORM.preloads = nil
+
+	return preloadRelations(ctx, &queryORM, orm.entryInfoProvider, orm.databaseConfig, entries, orm.preloads)
+}
+
+func (orm *PostgresORM) WithTx(executeFunc func(ORM) error) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
+	return orm.WithTxContext(context.Background(), nil, func(_ context.Context, txORM ORM) error {
+		return executeFunc(txORM)
+	})
+}
+
+func (orm *PostgresORM) WithTxContext(
+	ctx context.Context,
+	opts *sql.TxOptions,
+	executeFunc func(context.Context, ORM) error,
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypePostgres, &err)
+
 	if nonTXDB, ok := orm.db.(*goqu.Database); ok {
-		return nonTXDB.WithTx(func(td *goqu.TxDatabase) error {
-			return executeFunc(&PostgresORM{
-				db:                td,
-				entryInfoProvider: orm.entryInfoProvider,
+		return withRetry(ctx, orm.databaseConfig.RetryPolicy, DriverTypePostgres, func() error {
+			return withGoquTx(ctx, nonTXDB, opts, func(td *goqu.TxDatabase) error {
+				return executeFunc(ctx, &PostgresORM{
+					db:                td,
+					pool:              orm.pool,
+					replicas:          orm.replicas,
+					entryInfoProvider: orm.entryInfoProvider,
+					redactor:          orm.redactor,
+					databaseConfig:    orm.databaseConfig,
+					unscoped:          orm.unscoped,
+				})
 			})
 		})
 	}
 
-	return executeFunc(orm)
+	savepointORM := *orm
+	savepointORM.savepointDepth++
+	savepointORM.preloads = nil
+
+	return withSavepoint(ctx, orm.db, DriverTypePostgres, savepointORM.savepointDepth, func() error {
+		return executeFunc(ctx, &savepointORM)
+	})
 }
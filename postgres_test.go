@@ -22,7 +22,7 @@ var (
 )
 
 func preparePostgresTestEntryTable(fixtureFile string) error {
-	db, err := newSQLDatabase(postgresTestConfig)
+	db, err := NewSQLDatabase(postgresTestConfig)
 	if err != nil {
 		return err
 	}
@@ -36,7 +36,9 @@ func preparePostgresTestEntryTable(fixtureFile string) error {
 			string_col TEXT NOT NULL,
 			bytes_col BYTEA NOT NULL,
 			on_create_count BIGINT NOT NULL,
-			on_update_count BIGINT NOT NULL
+			on_update_count BIGINT NOT NULL,
+			version BIGINT NOT NULL DEFAULT 0,
+			deleted_at BIGINT NULL
 		);
 
 
@@ -48,9 +50,24 @@ func preparePostgresTestEntryTable(fixtureFile string) error {
 			bytes_col BYTEA NOT NULL,
 			on_create_count BIGINT NOT NULL,
 			on_update_count BIGINT NOT NULL,
+			version BIGINT NOT NULL DEFAULT 0,
+			deleted_at BIGINT NULL,
 			PRIMARY KEY (id_1, id_2)
 		);
 
+		DROP TABLE IF EXISTS get_key_entries;
+		CREATE TABLE get_key_entries (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			string_col TEXT NOT NULL
+		);
+
+		DROP TABLE IF EXISTS get_child_entries;
+		CREATE TABLE get_child_entries (
+			id BIGINT PRIMARY KEY,
+			parent_id BIGINT NOT NULL,
+			string_col TEXT NOT NULL
+		);
+
 		END TRANSACTION;
 	`); err != nil {
 		return err
@@ -174,3 +191,356 @@ func TestPostgreSQLWithTX(t *testing.T) {
 
 	testWithTX(t, orm)
 }
+
+func TestPostgresWithTxContext(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testWithTxContext(t, orm)
+}
+
+func TestPostgresNestedWithTx(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testNestedWithTx(t, orm)
+}
+
+func TestPostgresCreateOrUpdateUpsert(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := postgresTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testCreateOrUpdateUpsert(t, orm, testfixturesDefaultSequenceStart)
+}
+
+func TestPostgresCreateMany(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := postgresTestConfig
+	databaseConfig.CreateManyChunkSize = 2
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testCreateMany(t, orm, testfixturesDefaultSequenceStart)
+}
+
+func TestPostgresUpdateWhere(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testUpdateWhere(t, orm)
+}
+
+func TestPostgresDeleteWhere(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testDeleteWhere(t, orm)
+}
+
+func TestPostgresOptimisticLocking(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testOptimisticLocking(t, orm)
+}
+
+func TestPostgresOptimisticLockingUpsert(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := postgresTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testOptimisticLocking(t, orm)
+}
+
+func TestPostgresSoftDelete(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := postgresTestConfig
+	databaseConfig.Models = []interface{}{&softDeleteIDEntry{}}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testSoftDelete(t, orm)
+}
+
+func TestPostgresUnregisteredSoftDelete(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testUnregisteredSoftDelete(t, orm)
+}
+
+func TestPostgresTaggedModel(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testTaggedModel(t, orm, testfixturesDefaultSequenceStart)
+}
+
+func TestPostgresTaggedModelUpsert(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := postgresTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testTaggedModel(t, orm, testfixturesDefaultSequenceStart)
+}
+
+func TestPostgresKeys(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testKeys(t, orm)
+}
+
+func TestPostgresKeysUpsert(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := postgresTestConfig
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testKeys(t, orm)
+}
+
+func TestPostgresGeneratedKeys(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testGeneratedKeys(t, orm)
+}
+
+func TestPostgresWithTxRetryPolicy(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := postgresTestConfig
+	databaseConfig.RetryPolicy = NewExponentialBackoffRetryPolicy()
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testWithTX(t, orm)
+}
+
+func TestPostgresIterate(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testIterate(t, orm)
+}
+
+func TestPostgresIterateWithXLock(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testIterateWithXLock(t, orm)
+}
+
+func TestPostgresQueryPage(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testQueryPage(t, orm)
+}
+
+func TestPostgresQueryColumns(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testQueryColumns(t, orm)
+}
+
+func TestPostgresUpdateColumns(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testUpdateColumns(t, orm)
+}
+
+func TestPostgresAggregate(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testAggregate(t, orm)
+}
+
+func TestPostgresQueryGroupBy(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testQueryGroupBy(t, orm)
+}
+
+func TestPostgresPreload(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_preload.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := postgresTestConfig
+	databaseConfig.PreloadChunkSize = 2
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testPreload(t, orm)
+}
+
+func TestPostgresGenerateCreateTableStatement(t *testing.T) {
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testGenerateCreateTableStatement(t, orm, DriverTypePostgres)
+}
+
+func TestPostgresVerifySchema(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testVerifySchema(t, orm, DriverTypePostgres)
+}
+
+func TestPostgresHooks(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_hooks.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testHooks(t, orm)
+}
+
+func TestPostgresInstrumentation(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := postgresTestConfig
+	databaseConfig.Instrumentation = &recordingInstrumentation{}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testInstrumentation(t, orm)
+}
+
+func TestPostgresStructuredLogging(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := postgresTestConfig
+	databaseConfig.StructuredLogger = &recordingStructuredLogger{}
+	databaseConfig.RedactedColumns = []string{"on_update_count"}
+	databaseConfig.Models = []interface{}{&sensitiveEntry{}}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testStructuredLogging(t, orm)
+}
+
+func TestPostgresHealth(t *testing.T) {
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testHealth(t, orm, DriverTypePostgres)
+}
+
+func TestPostgresFromSQLDatabase(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	testFromSQLDatabase(t, postgresTestConfig)
+}
+
+func TestPostgresWhereWithZeroLimit(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testWhereWithZeroLimit(t, orm)
+}
+
+func TestPostgresAggregateEmptyTable(t *testing.T) {
+	err := preparePostgresTestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(postgresTestConfig)
+	assert.Nil(t, err)
+
+	testAggregateEmptyTable(t, orm)
+}
@@ -0,0 +1,281 @@
+package miniorm
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+const (
+	defaultPreloadChunkSize = 1000
+	// Parameters left for the scope that Query() adds to the IN (...) of a chunk, e.g. the soft delete filter
+	preloadScopeParameters = 10
+)
+
+var (
+	ErrRelationNotFound     = errors.New("expected entry to declare the relation with RelationsGetter or a hasmany or belongsto tag")
+	ErrInvalidRelationField = errors.New(
+		"expected relation field to be a slice of structs or pointers to structs for has-many, " +
+			"or a struct or pointer to a struct for belongs-to",
+	)
+	ErrRelationColumnNotFound = errors.New("expected relation columns to be columns of the entries")
+)
+
+type RelationType string
+
+const (
+	// RelationTypeHasMany loads the entries whose ForeignKey references the entry into a slice field
+	RelationTypeHasMany RelationType = "hasmany"
+	// RelationTypeBelongsTo loads the entry referenced by the ForeignKey of the entry into a struct or pointer field
+	RelationTypeBelongsTo RelationType = "belongsto"
+)
+
+// Relation declares entries related to an entry, which are loaded into the field named Field by Preload()
+type Relation struct {
+	Field string
+	Type  RelationType
+	// ForeignKey is the referencing column, of the related entries for RelationTypeHasMany, or of the entry for
+	// RelationTypeBelongsTo
+	ForeignKey string
+	// References is the referenced column, of the entry for RelationTypeHasMany, or of the related entries for
+	// RelationTypeBelongsTo. It defaults to the ID or key column.
+	References string
+}
+
+// preloadRelations loads the relations named by relationFields into entries, either a pointer to an entry or a pointer
+// to a slice of entries. The related entries of each relation are queried with queryORM, which must not preload
+// itself, by a single WHERE ... IN (...) per chunk of databaseConfig.PreloadChunkSize values.
+func preloadRelations(
+	ctx context.Context,
+	queryORM ORM,
+	entryInfoProvider *entryInfoProvider,
+	databaseConfig DatabaseConfig,
+	entries interface{},
+	relationFields []string,
+) error {
+	entryList := []interface{}{entries}
+	if reflect.Indirect(reflect.ValueOf(entries)).Kind() == reflect.Slice {
+		var err error
+		if entryList, err = entryInfoProvider.GetEntryList(entries); err != nil {
+			return err
+		}
+	}
+
+	if len(entryList) == 0 {
+		return nil
+	}
+
+	for _, relationField := range relationFields {
+		relation, ok := entryInfoProvider.GetRelation(entryList[0], relationField)
+		if !ok {
+			return ErrRelationNotFound
+		}
+
+		if err := preloadRelation(ctx, queryORM, entryInfoProvider, databaseConfig, entryList, relation); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func preloadRelation(
+	ctx context.Context,
+	queryORM ORM,
+	entryInfoProvider *entryInfoProvider,
+	databaseConfig DatabaseConfig,
+	entryList []interface{},
+	relation Relation,
+) error {
+	fieldType, relatedType, err := getRelationTypes(entryList[0], relation)
+	if err != nil {
+		return err
+	}
+
+	relatedTableName, err := entryInfoProvider.GetEntryTableName(reflect.New(relatedType).Interface())
+	if err != nil {
+		return err
+	}
+
+	entryColumn, relatedColumn := relation.References, relation.ForeignKey
+	if relation.Type == RelationTypeBelongsTo {
+		entryColumn, relatedColumn = relation.ForeignKey, relation.References
+	}
+
+	if entryColumn == "" {
+		if entryColumn, err = getRelationKeyColumn(entryInfoProvider, entryList[0]); err != nil {
+			return err
+		}
+	}
+
+	if relatedColumn == "" {
+		if relatedColumn, err = getRelationKeyColumn(entryInfoProvider, reflect.New(relatedType).Interface()); err != nil {
+			return err
+		}
+	}
+
+	// Entries sharing the same referenced value get the same related entries
+	keyToEntryFields := make(map[string][]reflect.Value)
+	keyValues := make([]interface{}, 0, len(entryList))
+
+	for _, entry := range entryList {
+		entryField := reflect.ValueOf(entry).Elem().FieldByName(relation.Field)
+		entryField.Set(reflect.Zero(fieldType))
+
+		if relation.Type == RelationTypeHasMany {
+			entryField.Set(reflect.MakeSlice(fieldType, 0, 0))
+		}
+
+		key, keyValue, ok, err := getRelationKey(entry, entryColumn)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		if _, isKnown := keyToEntryFields[key]; !isKnown {
+			keyValues = append(keyValues, keyValue)
+		}
+
+		keyToEntryFields[key] = append(keyToEntryFields[key], entryField)
+	}
+
+	relatedEntryType := fieldType
+	if relation.Type == RelationTypeHasMany {
+		relatedEntryType = fieldType.Elem()
+	}
+
+	for _, keyValueChunk := range chunkEntryList(keyValues, getPreloadChunkSize(databaseConfig)) {
+		relatedEntryList := reflect.New(reflect.SliceOf(relatedEntryType))
+		if err := queryORM.Query(ctx, QueryParams{
+			TableName:  relatedTableName,
+			EntryList:  relatedEntryList.Interface(),
+			Expression: goqu.C(relatedColumn).In(keyValueChunk...),
+		}); err != nil {
+			return err
+		}
+
+		for i := 0; i < relatedEntryList.Elem().Len(); i++ {
+			relatedEntry := relatedEntryList.Elem().Index(i)
+
+			key, _, ok, err := getRelationKey(relatedEntry.Interface(), relatedColumn)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				continue
+			}
+
+			for _, entryField := range keyToEntryFields[key] {
+				if relation.Type == RelationTypeHasMany {
+					entryField.Set(reflect.Append(entryField, relatedEntry))
+				} else {
+					entryField.Set(relatedEntry)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// getRelationTypes returns the type of the relation field of entry, and the struct type of the related entries
+func getRelationTypes(entry interface{}, relation Relation) (fieldType, relatedType reflect.Type, err error) {
+	entryValue := reflect.ValueOf(entry)
+	if entryValue.Kind() != reflect.Ptr || entryValue.IsNil() || entryValue.Elem().Kind() != reflect.Struct {
+		return nil, nil, ErrInvalidRelationField
+	}
+
+	field, ok := entryValue.Elem().Type().FieldByName(relation.Field)
+	if !ok {
+		return nil, nil, ErrInvalidRelationField
+	}
+
+	relatedType = field.Type
+
+	switch relation.Type {
+	case RelationTypeHasMany:
+		if relatedType.Kind() != reflect.Slice {
+			return nil, nil, ErrInvalidRelationField
+		}
+
+		relatedType = relatedType.Elem()
+	case RelationTypeBelongsTo:
+	default:
+		return nil, nil, ErrRelationNotFound
+	}
+
+	if relatedType.Kind() == reflect.Ptr {
+		relatedType = relatedType.Elem()
+	}
+
+	if relatedType.Kind() != reflect.Struct {
+		return nil, nil, ErrInvalidRelationField
+	}
+
+	return field.Type, relatedType, nil
+}
+
+// getRelationKeyColumn returns the single ID or key column of entry
+func getRelationKeyColumn(entryInfoProvider *entryInfoProvider, entry interface{}) (string, error) {
+	keyColumns, err := entryInfoProvider.GetEntrySelectColumns(entry)
+	if err != nil {
+		return "", err
+	}
+
+	if len(keyColumns) != 1 {
+		return "", ErrRelationColumnNotFound
+	}
+
+	return keyColumns[0], nil
+}
+
+// getRelationKey returns the value of column in entry, and its string representation to match related entries by. ok
+// is false if the value is NULL.
+func getRelationKey(entry interface{}, column string) (key string, keyValue interface{}, ok bool, err error) {
+	record, err := exp.NewRecordFromStruct(reflect.Indirect(reflect.ValueOf(entry)).Interface(), false, false)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	value, ok := record[column]
+	if !ok {
+		return "", nil, false, ErrRelationColumnNotFound
+	}
+
+	// Converting the value lets e.g. an int32 foreign key match an int64 ID, or sql.NullInt64 match int64
+	keyValue, err = driver.DefaultParameterConverter.ConvertValue(value)
+	if err != nil || keyValue == nil {
+		return "", nil, false, err
+	}
+
+	if bytes, isBytes := keyValue.([]byte); isBytes {
+		return string(bytes), keyValue, true, nil
+	}
+
+	return fmt.Sprint(keyValue), keyValue, true, nil
+}
+
+// getPreloadChunkSize returns the configured chunk size, reduced to the parameter limit of the database engine minus
+// the parameters of the scope of the query
+func getPreloadChunkSize(databaseConfig DatabaseConfig) int {
+	chunkSize := databaseConfig.PreloadChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultPreloadChunkSize
+	}
+
+	maxParameters, ok := configDriverTypeToMaxParameters[databaseConfig.Driver]
+	if ok && chunkSize > maxParameters-preloadScopeParameters {
+		chunkSize = maxParameters - preloadScopeParameters
+	}
+
+	return chunkSize
+}
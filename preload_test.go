@@ -0,0 +1,27 @@
+package miniorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPreloadChunkSize(t *testing.T) {
+	t.Parallel()
+
+	testCaseList := []struct {
+		databaseConfig    DatabaseConfig
+		expectedChunkSize int
+	}{
+		{databaseConfig: DatabaseConfig{Driver: DriverTypeMySQL}, expectedChunkSize: defaultPreloadChunkSize},
+		{databaseConfig: DatabaseConfig{Driver: DriverTypeMySQL, PreloadChunkSize: 10}, expectedChunkSize: 10},
+		{databaseConfig: DatabaseConfig{Driver: DriverTypeMSSQL, PreloadChunkSize: 5000}, expectedChunkSize: 2088},
+		{databaseConfig: DatabaseConfig{Driver: DriverTypeMSSQL, PreloadChunkSize: 2088}, expectedChunkSize: 2088},
+		{databaseConfig: DatabaseConfig{Driver: DriverTypeMSSQL, PreloadChunkSize: 2099}, expectedChunkSize: 2088},
+		{databaseConfig: DatabaseConfig{Driver: DriverTypeSQLite3, PreloadChunkSize: 50000}, expectedChunkSize: 32756},
+	}
+
+	for _, testCase := range testCaseList {
+		assert.Equal(t, testCase.expectedChunkSize, getPreloadChunkSize(testCase.databaseConfig))
+	}
+}
@@ -0,0 +1,472 @@
+package miniorm
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+// RedactedValue replaces the redacted values in the logged arguments
+const RedactedValue = "[REDACTED]"
+
+type redactionBindingsContextKey struct{}
+
+// redactionBinding returns the values bound to the redacted columns of a statement. It is only called when the
+// statement is logged.
+type redactionBinding func() []interface{}
+
+// logRedactor binds the values of the sensitive columns to the context of the statements built by the ORM, so that
+// they are redacted from the arguments of the logged statements
+type logRedactor struct {
+	// Columns redacted in every table, in lower case
+	redactedColumns map[string]bool
+	// Columns redacted in a single table, by table, in lower case
+	redactedTableColumns map[string]map[string]bool
+}
+
+// newLogRedactor returns the redactor of the sensitive columns of databaseConfig, or nil if the statements are not
+// logged or no column is sensitive
+func newLogRedactor(databaseConfig DatabaseConfig) *logRedactor {
+	if databaseConfig.Logger == nil && databaseConfig.StructuredLogger == nil {
+		return nil
+	}
+
+	redactor := &logRedactor{
+		redactedColumns:      make(map[string]bool, len(databaseConfig.RedactedColumns)),
+		redactedTableColumns: make(map[string]map[string]bool),
+	}
+
+	for _, column := range databaseConfig.RedactedColumns {
+		tableName := ""
+		if separatorPosition := strings.LastIndex(column, "."); separatorPosition >= 0 {
+			tableName, column = column[:separatorPosition], column[separatorPosition+1:]
+		}
+
+		redactor.addRedactedColumn(tableName, column)
+	}
+
+	entryInfoProvider := newEntryInfoProvider()
+
+	for _, model := range databaseConfig.Models {
+		metadata, _, ok := getModelMetadata(model)
+		if !ok {
+			continue
+		}
+
+		// Columns of models without a table name are redacted in every table
+		tableName, _ := entryInfoProvider.GetEntryTableName(model)
+
+		for _, field := range metadata.sensitiveFields {
+			redactor.addRedactedColumn(tableName, field.column)
+		}
+	}
+
+	if len(redactor.redactedColumns) == 0 && len(redactor.redactedTableColumns) == 0 {
+		return nil
+	}
+
+	return redactor
+}
+
+func (redactor *logRedactor) addRedactedColumn(tableName string, column string) {
+	column = strings.ToLower(column)
+	if tableName == "" {
+		redactor.redactedColumns[column] = true
+		return
+	}
+
+	tableName = strings.ToLower(tableName)
+	if redactor.redactedTableColumns[tableName] == nil {
+		redactor.redactedTableColumns[tableName] = make(map[string]bool)
+	}
+
+	redactor.redactedTableColumns[tableName][column] = true
+}
+
+// isRedactedColumn returns whether column is redacted in tableName
+func (redactor *logRedactor) isRedactedColumn(tableName string, column string) bool {
+	column = strings.ToLower(column)
+
+	return redactor.redactedColumns[column] || redactor.redactedTableColumns[strings.ToLower(tableName)][column]
+}
+
+// bind returns ctx with binding added to the bindings of its statements
+func (redactor *logRedactor) bind(ctx context.Context, binding redactionBinding) context.Context {
+	bindings, _ := ctx.Value(redactionBindingsContextKey{}).([]redactionBinding)
+
+	return context.WithValue(ctx, redactionBindingsContextKey{}, append(bindings[:len(bindings):len(bindings)], binding))
+}
+
+// bindEntries binds the values of the redacted columns of entries, rows of tableName, to ctx
+func (redactor *logRedactor) bindEntries(ctx context.Context, tableName string, entries ...interface{}) context.Context {
+	if redactor == nil || len(entries) == 0 {
+		return ctx
+	}
+
+	return redactor.bind(ctx, func() []interface{} {
+		var values []interface{}
+
+		for _, entry := range entries {
+			if record, ok := entry.(exp.Record); ok {
+				values = redactor.getRecordValues(tableName, record, values)
+				continue
+			}
+
+			entryValue := reflect.Indirect(reflect.ValueOf(entry))
+			if entryValue.Kind() != reflect.Struct {
+				continue
+			}
+
+			record, err := exp.NewRecordFromStruct(entryValue.Interface(), false, false)
+			if err != nil {
+				continue
+			}
+
+			values = redactor.getRecordValues(tableName, record, values)
+		}
+
+		return values
+	})
+}
+
+// bindRecord binds the values of the redacted columns of record, set in tableName, to ctx
+func (redactor *logRedactor) bindRecord(ctx context.Context, tableName string, record exp.Record) context.Context {
+	if redactor == nil || len(record) == 0 {
+		return ctx
+	}
+
+	return redactor.bind(ctx, func() []interface{} {
+		return redactor.getRecordValues(tableName, record, nil)
+	})
+}
+
+// bindExpression binds the values compared to the redacted columns of tableName in expression to ctx, e.g. the value
+// of `"email" = 'a'`, `LOWER("email") = 'a'` or `'a' = "email"`
+func (redactor *logRedactor) bindExpression(ctx context.Context, tableName string, expression exp.Expression) context.Context {
+	if redactor == nil || expression == nil {
+		return ctx
+	}
+
+	return redactor.bind(ctx, func() []interface{} {
+		return redactor.getExpressionValues(tableName, expression, false, nil)
+	})
+}
+
+// bindQueryParams binds the values compared to the redacted columns in the expressions of params to ctx
+func (redactor *logRedactor) bindQueryParams(ctx context.Context, params QueryParams) context.Context {
+	ctx = redactor.bindExpression(ctx, params.TableName, params.Expression)
+
+	return redactor.bindExpression(ctx, params.TableName, params.Having)
+}
+
+func (redactor *logRedactor) getRecordValues(tableName string, record exp.Record, values []interface{}) []interface{} {
+	for column, value := range record {
+		isRedacted := redactor.isRedactedColumn(tableName, column)
+
+		if expression, ok := value.(exp.Expression); ok {
+			values = redactor.getExpressionValues(tableName, expression, isRedacted, values)
+		} else if isRedacted {
+			values = appendRedactedValue(values, value)
+		}
+	}
+
+	return values
+}
+
+// getExpressionValues returns values with the values of expression appended, if they are compared to a redacted column
+// of tableName or if isRedacted
+func (redactor *logRedactor) getExpressionValues(
+	tableName string,
+	expression exp.Expression,
+	isRedacted bool,
+	values []interface{},
+) []interface{} {
+	switch typedExpression := expression.(type) {
+	case exp.Ex:
+		expressionList, err := typedExpression.ToExpressions()
+		if err == nil {
+			values = redactor.getExpressionValues(tableName, expressionList, isRedacted, values)
+		}
+	case exp.ExOr:
+		expressionList, err := typedExpression.ToExpressions()
+		if err == nil {
+			values = redactor.getExpressionValues(tableName, expressionList, isRedacted, values)
+		}
+	case exp.ExpressionList:
+		for _, listExpression := range typedExpression.Expressions() {
+			values = redactor.getExpressionValues(tableName, listExpression, isRedacted, values)
+		}
+	case exp.BooleanExpression:
+		values = redactor.getOperandValues(tableName, []interface{}{typedExpression.LHS(), typedExpression.RHS()}, isRedacted, values)
+	case exp.BitwiseExpression:
+		values = redactor.getOperandValues(tableName, []interface{}{typedExpression.LHS(), typedExpression.RHS()}, isRedacted, values)
+	case exp.RangeExpression:
+		operands := []interface{}{typedExpression.LHS()}
+		if rangeValue, ok := typedExpression.RHS().(exp.RangeVal); ok {
+			operands = append(operands, rangeValue.Start(), rangeValue.End())
+		}
+
+		values = redactor.getOperandValues(tableName, operands, isRedacted, values)
+	case exp.SQLFunctionExpression:
+		values = redactor.getOperandValues(tableName, typedExpression.Args(), isRedacted, values)
+	case exp.LiteralExpression:
+		values = redactor.getOperandValues(tableName, typedExpression.Args(), isRedacted, values)
+	case exp.AliasedExpression:
+		values = redactor.getExpressionValues(tableName, typedExpression.Aliased(), isRedacted, values)
+	case exp.CastExpression:
+		values = redactor.getExpressionValues(tableName, typedExpression.Casted(), isRedacted, values)
+	case *goqu.SelectDataset:
+		// The values of a subquery are bound to the columns of its own table
+		clauses := typedExpression.GetClauses()
+		if clauses.Where() != nil {
+			values = redactor.getExpressionValues(getSelectTableName(clauses, tableName), clauses.Where(), false, values)
+		}
+	}
+
+	return values
+}
+
+// getOperandValues returns values with the values of operands appended, if one of operands is a redacted column of
+// tableName or if isRedacted, and with the values of the expressions of operands
+func (redactor *logRedactor) getOperandValues(
+	tableName string,
+	operands []interface{},
+	isRedacted bool,
+	values []interface{},
+) []interface{} {
+	// goqu passes the values of IN as a slice, which may hold expressions, e.g. a subquery
+	flatOperands := make([]interface{}, 0, len(operands))
+	for _, operand := range operands {
+		if operandList, ok := operand.([]interface{}); ok {
+			flatOperands = append(flatOperands, operandList...)
+		} else {
+			flatOperands = append(flatOperands, operand)
+		}
+	}
+
+	operands = flatOperands
+
+	for _, operand := range operands {
+		if expression, ok := operand.(exp.Expression); ok && !isRedacted {
+			operandTableName, column, ok := getExpressionColumn(expression)
+			if !ok {
+				continue
+			}
+
+			if operandTableName == "" {
+				operandTableName = tableName
+			}
+
+			isRedacted = redactor.isRedactedColumn(operandTableName, column)
+		}
+	}
+
+	for _, operand := range operands {
+		if expression, ok := operand.(exp.Expression); ok {
+			values = redactor.getExpressionValues(tableName, expression, isRedacted, values)
+		} else if isRedacted {
+			values = appendRedactedValue(values, operand)
+		}
+	}
+
+	return values
+}
+
+// getExpressionColumn returns the column of expression, if it is a column or a function of a column, e.g. LOWER("email")
+func getExpressionColumn(expression exp.Expression) (tableName string, column string, ok bool) {
+	var operands []interface{}
+
+	switch typedExpression := expression.(type) {
+	case exp.IdentifierExpression:
+		column, ok := typedExpression.GetCol().(string)
+
+		return typedExpression.GetTable(), column, ok
+	case exp.AliasedExpression:
+		return getExpressionColumn(typedExpression.Aliased())
+	case exp.CastExpression:
+		return getExpressionColumn(typedExpression.Casted())
+	case exp.SQLFunctionExpression:
+		operands = typedExpression.Args()
+	case exp.LiteralExpression:
+		operands = typedExpression.Args()
+	}
+
+	for _, operand := range operands {
+		if operandExpression, ok := operand.(exp.Expression); ok {
+			if tableName, column, ok := getExpressionColumn(operandExpression); ok {
+				return tableName, column, true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// getSelectTableName returns the table selected from by clauses, or defaultTableName if there is none
+func getSelectTableName(clauses exp.SelectClauses, defaultTableName string) string {
+	if clauses.From() == nil {
+		return defaultTableName
+	}
+
+	for _, from := range clauses.From().Columns() {
+		identifier, ok := from.(exp.IdentifierExpression)
+		if !ok {
+			continue
+		}
+
+		// goqu parses a table name without schema as a column, e.g. From("users")
+		if identifier.GetTable() != "" {
+			return identifier.GetTable()
+		}
+
+		if tableName, ok := identifier.GetCol().(string); ok {
+			return tableName
+		}
+	}
+
+	return defaultTableName
+}
+
+// appendRedactedValue appends value to values like goqu converts it into arguments, e.g. each value of a slice of an
+// IN expression
+func appendRedactedValue(values []interface{}, value interface{}) []interface{} {
+	value = normalizeRedactedValue(value)
+	if value == nil {
+		return values
+	}
+
+	reflectValue := reflect.ValueOf(value)
+	if reflectValue.Kind() == reflect.Slice && reflectValue.Type().Elem().Kind() != reflect.Uint8 {
+		for i := 0; i < reflectValue.Len(); i++ {
+			values = appendRedactedValue(values, reflectValue.Index(i).Interface())
+		}
+
+		return values
+	}
+
+	return append(values, value)
+}
+
+// normalizeRedactedValue converts value like goqu converts the arguments of the statements, e.g. a driver.Valuer to its
+// value or a named string type to a string, so that it can be compared with the arguments
+func normalizeRedactedValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case nil, string, int64, float64, bool, []byte, time.Time:
+		return value
+	case int:
+		return int64(typedValue)
+	case int32:
+		return int64(typedValue)
+	case float32:
+		return float64(typedValue)
+	case *time.Time:
+		if typedValue == nil {
+			return nil
+		}
+
+		return *typedValue
+	case driver.Valuer:
+		if reflectValue := reflect.ValueOf(value); reflectValue.Kind() == reflect.Ptr && reflectValue.IsNil() {
+			return nil
+		}
+
+		driverValue, err := typedValue.Value()
+		if err != nil {
+			return value
+		}
+
+		return normalizeRedactedValue(driverValue)
+	}
+
+	reflectValue := reflect.Indirect(reflect.ValueOf(value))
+
+	switch reflectValue.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflectValue.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(reflectValue.Uint())
+	case reflect.Float32, reflect.Float64:
+		return reflectValue.Float()
+	case reflect.String:
+		return reflectValue.String()
+	case reflect.Bool:
+		return reflectValue.Bool()
+	case reflect.Slice:
+		if reflectValue.Type().Elem().Kind() == reflect.Uint8 {
+			return reflectValue.Bytes()
+		}
+	}
+
+	return reflectValue.Interface()
+}
+
+// isRedactedValue returns whether value, a normalized argument, is one of redactedValues
+func isRedactedValue(value interface{}, redactedValues []interface{}) bool {
+	for _, redactedValue := range redactedValues {
+		switch typedValue := value.(type) {
+		case []byte:
+			if redactedBytes, ok := redactedValue.([]byte); ok && bytes.Equal(typedValue, redactedBytes) {
+				return true
+			}
+		case time.Time:
+			if redactedTime, ok := redactedValue.(time.Time); ok && typedValue.Equal(redactedTime) {
+				return true
+			}
+		default:
+			if reflect.TypeOf(value).Comparable() && reflect.TypeOf(redactedValue).Comparable() {
+				if value == redactedValue {
+					return true
+				}
+			} else if reflect.DeepEqual(value, redactedValue) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// redactArgs returns a copy of args with the values bound to the redacted columns of ctx replaced by RedactedValue,
+// or args if none of them is redacted. The arguments are matched by value, since their positions are only known to goqu,
+// so any other argument equal to a bound value is redacted too, e.g. an ID or the LIMIT.
+func redactArgs(ctx context.Context, args []interface{}) []interface{} {
+	bindings, _ := ctx.Value(redactionBindingsContextKey{}).([]redactionBinding)
+	if len(bindings) == 0 || len(args) == 0 {
+		return args
+	}
+
+	var redactedValues []interface{}
+	for _, binding := range bindings {
+		redactedValues = append(redactedValues, binding()...)
+	}
+
+	if len(redactedValues) == 0 {
+		return args
+	}
+
+	redactedArgs := args
+	hasRedactedArgs := false
+
+	for i, arg := range args {
+		value := normalizeRedactedValue(arg)
+		if value == nil || !isRedactedValue(value, redactedValues) {
+			continue
+		}
+
+		if !hasRedactedArgs {
+			redactedArgs, hasRedactedArgs = append([]interface{}{}, args...), true
+		}
+
+		redactedArgs[i] = RedactedValue
+	}
+
+	return redactedArgs
+}
@@ -0,0 +1,194 @@
+package miniorm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/stretchr/testify/assert"
+)
+
+type redactionTestEntry struct {
+	_      struct{} `miniorm:"table=secrets"`
+	ID     int64    `db:"id"`
+	Secret string   `db:"secret" miniorm:"sensitive"`
+}
+
+type redactionTestUser struct {
+	ID       int64  `db:"id" goqu:"skipinsert"`
+	Email    string `db:"email"`
+	Name     string `db:"name"`
+	Password string `db:"password"`
+}
+
+func TestNewLogRedactor(t *testing.T) {
+	assert.Nil(t, newLogRedactor(DatabaseConfig{RedactedColumns: []string{"password"}}))
+	assert.Nil(t, newLogRedactor(DatabaseConfig{Logger: &printfLogger{}}))
+	assert.NotNil(t, newLogRedactor(DatabaseConfig{Logger: &printfLogger{}, RedactedColumns: []string{"password"}}))
+	assert.NotNil(t, newLogRedactor(DatabaseConfig{
+		StructuredLogger: &recordingStructuredLogger{},
+		Models:           []interface{}{&redactionTestEntry{}},
+	}))
+
+	// The statements of the ORMs without a redactor are not bound
+	var redactor *logRedactor
+	ctx := redactor.bindEntries(context.Background(), "users", &redactionTestUser{Password: "secret"})
+	ctx = redactor.bindExpression(ctx, "users", goqu.C("password").Eq("secret"))
+	assert.Equal(t, []interface{}{"secret"}, redactArgs(ctx, []interface{}{"secret"}))
+}
+
+func TestLogRedactorBind(t *testing.T) {
+	redactor := newLogRedactor(DatabaseConfig{Logger: &printfLogger{}, RedactedColumns: []string{"Password", "email"}})
+	dialect := goqu.Dialect("postgres")
+
+	for _, testCase := range []struct {
+		name         string
+		bind         func(ctx context.Context) context.Context
+		dataset      exp.SQLExpression
+		expectedArgs []interface{}
+	}{
+		{
+			name: "entries",
+			bind: func(ctx context.Context) context.Context {
+				return redactor.bindEntries(
+					ctx,
+					"users",
+					&redactionTestUser{Email: "a@example.com", Name: "a", Password: "secret a"},
+					&redactionTestUser{Email: "b@example.com", Name: "b", Password: "secret b"},
+				)
+			},
+			dataset: dialect.Insert("users").Prepared(true).Rows(
+				&redactionTestUser{Email: "a@example.com", Name: "a", Password: "secret a"},
+				&redactionTestUser{Email: "b@example.com", Name: "b", Password: "secret b"},
+			),
+			expectedArgs: []interface{}{RedactedValue, "a", RedactedValue, RedactedValue, "b", RedactedValue},
+		},
+		{
+			name: "record and IN expression",
+			bind: func(ctx context.Context) context.Context {
+				ctx = redactor.bindRecord(ctx, "users", goqu.Record{"name": "a", "password": "secret"})
+				return redactor.bindExpression(ctx, "users", goqu.Ex{"email": []string{"a@example.com", "b@example.com"}})
+			},
+			dataset: dialect.Update("users").Prepared(true).
+				Set(goqu.Record{"name": "a", "password": "secret"}).
+				Where(goqu.Ex{"email": []string{"a@example.com", "b@example.com"}}),
+			expectedArgs: []interface{}{"a", RedactedValue, RedactedValue, RedactedValue},
+		},
+		{
+			name: "function of a column",
+			bind: func(ctx context.Context) context.Context {
+				return redactor.bindExpression(ctx, "users", goqu.And(
+					goqu.Func("LOWER", goqu.C("email")).Eq("a@example.com"),
+					goqu.C("id").Gt(1),
+				))
+			},
+			dataset: dialect.From("users").Prepared(true).Where(
+				goqu.Func("LOWER", goqu.C("email")).Eq("a@example.com"),
+				goqu.C("id").Gt(1),
+			),
+			expectedArgs: []interface{}{RedactedValue, int64(1)},
+		},
+		{
+			name: "value on the left",
+			bind: func(ctx context.Context) context.Context {
+				return redactor.bindExpression(ctx, "users", goqu.V("secret").Eq(goqu.C("password")))
+			},
+			dataset:      dialect.From("users").Prepared(true).Where(goqu.V("secret").Eq(goqu.C("password"))),
+			expectedArgs: []interface{}{RedactedValue},
+		},
+		{
+			name: "literal and subquery",
+			bind: func(ctx context.Context) context.Context {
+				return redactor.bindExpression(ctx, "groups", goqu.Or(
+					goqu.L("? LIKE ?", goqu.C("password"), "secret%"),
+					goqu.C("id").In(goqu.From("users").Select("group_id").Where(goqu.C("email").Eq("a@example.com"))),
+				))
+			},
+			dataset: dialect.From("groups").Prepared(true).Where(goqu.Or(
+				goqu.L("? LIKE ?", goqu.C("password"), "secret%"),
+				goqu.C("id").In(goqu.From("users").Select("group_id").Where(goqu.C("email").Eq("a@example.com"))),
+			)),
+			expectedArgs: []interface{}{RedactedValue, RedactedValue},
+		},
+		{
+			name: "unbound columns",
+			bind: func(ctx context.Context) context.Context {
+				return redactor.bindExpression(ctx, "users", goqu.Ex{"name": "secret", "password": nil})
+			},
+			dataset:      dialect.From("users").Prepared(true).Where(goqu.Ex{"name": "secret", "password": nil}),
+			expectedArgs: []interface{}{"secret"},
+		},
+	} {
+		_, args, err := testCase.dataset.ToSQL()
+		if !assert.Nil(t, err, testCase.name) {
+			continue
+		}
+
+		originalArgs := append([]interface{}(nil), args...)
+		assert.Equal(t, testCase.expectedArgs, redactArgs(testCase.bind(context.Background()), args), testCase.name)
+		assert.Equal(t, originalArgs, args, "the arguments must not be modified")
+	}
+}
+
+func TestLogRedactorTableColumns(t *testing.T) {
+	redactor := newLogRedactor(DatabaseConfig{
+		Logger:          &printfLogger{},
+		RedactedColumns: []string{"Users.Password"},
+		Models:          []interface{}{&redactionTestEntry{}},
+	})
+
+	for _, testCase := range []struct {
+		tableName    string
+		record       exp.Record
+		expectedArgs []interface{}
+	}{
+		{
+			tableName:    "users",
+			record:       goqu.Record{"name": "a", "password": "b", "secret": "c"},
+			expectedArgs: []interface{}{"a", RedactedValue, "c"},
+		},
+		{
+			tableName:    "secrets",
+			record:       goqu.Record{"name": "a", "password": "b", "secret": "c"},
+			expectedArgs: []interface{}{"a", "b", RedactedValue},
+		},
+		{
+			tableName:    "groups",
+			record:       goqu.Record{"name": "a", "password": "b", "secret": "c"},
+			expectedArgs: []interface{}{"a", "b", "c"},
+		},
+	} {
+		ctx := redactor.bindRecord(context.Background(), testCase.tableName, testCase.record)
+		redactedArgs := redactArgs(ctx, []interface{}{"a", "b", "c"})
+		assert.Equal(t, testCase.expectedArgs, redactedArgs, testCase.tableName)
+	}
+}
+
+func TestRedactArgsNormalizedValues(t *testing.T) {
+	type userID int32
+
+	redactor := newLogRedactor(DatabaseConfig{Logger: &printfLogger{}, RedactedColumns: []string{"secret"}})
+	ctx := redactor.bindRecord(context.Background(), "users", goqu.Record{"secret": userID(42)})
+	ctx = redactor.bindExpression(ctx, "users", goqu.C("secret").In([]byte("bytes"), 1.5))
+
+	assert.Equal(
+		t,
+		[]interface{}{RedactedValue, RedactedValue, RedactedValue, int64(43), "bytes"},
+		redactArgs(ctx, []interface{}{int64(42), []byte("bytes"), 1.5, int64(43), "bytes"}),
+	)
+}
+
+func TestRedactArgsEqualValues(t *testing.T) {
+	redactor := newLogRedactor(DatabaseConfig{Logger: &printfLogger{}, RedactedColumns: []string{"pin"}})
+	expression := goqu.And(goqu.C("pin").Eq(5), goqu.C("id").Neq(6))
+	ctx := redactor.bindExpression(context.Background(), "users", expression)
+
+	_, args, err := goqu.Dialect("postgres").From("users").Prepared(true).Where(expression).Limit(5).ToSQL()
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	// The arguments are redacted by value, so the LIMIT equal to the pin is redacted as well
+	assert.Equal(t, []interface{}{RedactedValue, int64(6), RedactedValue}, redactArgs(ctx, args))
+}
@@ -0,0 +1,141 @@
+package miniorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+type ReplicaLoadBalancing string
+
+const (
+	ReplicaLoadBalancingRoundRobin       ReplicaLoadBalancing = "roundRobin"
+	ReplicaLoadBalancingLeastConnections ReplicaLoadBalancing = "leastConnections"
+)
+
+var (
+	ErrInvalidReplicaLoadBalancing = errors.New("expected ReplicaLoadBalancing to be roundRobin or leastConnections")
+)
+
+// ReplicaConfig describes a read replica of the database of a DatabaseConfig. Its empty fields are taken from the
+// DatabaseConfig.
+type ReplicaConfig struct {
+	Host string `yaml:"host" json:"host"`
+	Port int    `yaml:"port" json:"port"`
+	URL  string `yaml:"url" json:"url"`
+}
+
+type primaryContextKey struct{}
+
+// WithPrimary returns a copy of ctx with which the reads are run on the primary database rather than on a replica,
+// e.g. to read an entry right after writing it
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryContextKey{}, true)
+}
+
+func isPrimaryForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryContextKey{}).(bool)
+	return forced
+}
+
+// replica is an open read replica, whose sqlDB is kept for its connection statistics
+type replica struct {
+	db    *goqu.Database
+	sqlDB *sql.DB
+}
+
+// replicaSet balances the reads between the replicas of a DatabaseConfig
+type replicaSet struct {
+	replicas      []replica
+	loadBalancing ReplicaLoadBalancing
+	next          uint32
+}
+
+// newReplicaSet opens the replicas of databaseConfig, or returns nil if it has none
+func newReplicaSet(databaseConfig DatabaseConfig) (*replicaSet, error) {
+	if len(databaseConfig.Replicas) == 0 {
+		return nil, nil
+	}
+
+	replicas := &replicaSet{loadBalancing: databaseConfig.ReplicaLoadBalancing}
+
+	switch replicas.loadBalancing {
+	case "":
+		replicas.loadBalancing = ReplicaLoadBalancingRoundRobin
+	case ReplicaLoadBalancingRoundRobin, ReplicaLoadBalancingLeastConnections:
+	default:
+		return nil, ErrInvalidReplicaLoadBalancing
+	}
+
+	for _, replicaConfig := range databaseConfig.Replicas {
+		replicaDatabaseConfig := databaseConfig
+		replicaDatabaseConfig.Replicas = nil
+
+		if replicaConfig.Host != "" {
+			replicaDatabaseConfig.Host = replicaConfig.Host
+		}
+
+		if replicaConfig.Port != 0 {
+			replicaDatabaseConfig.Port = replicaConfig.Port
+		}
+
+		if replicaConfig.URL != "" {
+			replicaDatabaseConfig.URL = replicaConfig.URL
+		}
+
+		sqlDB, err := NewSQLDatabase(replicaDatabaseConfig)
+		if err != nil {
+			replicas.close()
+			return nil, err
+		}
+
+		replicas.replicas = append(replicas.replicas, replica{
+			db:    newGoquDatabaseFromSQLDatabase(sqlDB, replicaDatabaseConfig),
+			sqlDB: sqlDB,
+		})
+	}
+
+	return replicas, nil
+}
+
+// pick returns the replica to run the next read on. Round robin also breaks the ties of least connections.
+func (replicas *replicaSet) pick() *goqu.Database {
+	start := int((atomic.AddUint32(&replicas.next, 1) - 1) % uint32(len(replicas.replicas)))
+	if replicas.loadBalancing != ReplicaLoadBalancingLeastConnections {
+		return replicas.replicas[start].db
+	}
+
+	picked, pickedInUse := start, replicas.replicas[start].sqlDB.Stats().InUse
+
+	for offset := 1; offset < len(replicas.replicas); offset++ {
+		i := (start + offset) % len(replicas.replicas)
+		if inUse := replicas.replicas[i].sqlDB.Stats().InUse; inUse < pickedInUse {
+			picked, pickedInUse = i, inUse
+		}
+	}
+
+	return replicas.replicas[picked].db
+}
+
+func (replicas *replicaSet) close() {
+	for _, replica := range replicas.replicas {
+		_ = replica.sqlDB.Close()
+	}
+}
+
+// getReadDB returns the database of a read which does not lock rows: a replica, unless there are none, db is a
+// transaction, or ctx was returned by WithPrimary()
+func getReadDB(ctx context.Context, db DBWrapper, replicas *replicaSet) DBWrapper {
+	if replicas == nil || isPrimaryForced(ctx) {
+		return db
+	}
+
+	if _, ok := db.(*goqu.Database); !ok {
+		return db
+	}
+
+	return replicas.pick()
+}
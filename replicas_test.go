@@ -0,0 +1,76 @@
+package miniorm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReplicaSet(t *testing.T, loadBalancing ReplicaLoadBalancing) *replicaSet {
+	replicas, err := newReplicaSet(DatabaseConfig{
+		Driver: DriverTypeSQLite3,
+		Replicas: []ReplicaConfig{
+			{URL: "file:" + filepath.Join(t.TempDir(), "test_replica.db")},
+			{URL: "file:" + filepath.Join(t.TempDir(), "test_replica.db")},
+			{URL: "file:" + filepath.Join(t.TempDir(), "test_replica.db")},
+		},
+		ReplicaLoadBalancing: loadBalancing,
+	})
+	assert.Nil(t, err)
+
+	t.Cleanup(replicas.close)
+
+	return replicas
+}
+
+func TestReplicaSetPickRoundRobin(t *testing.T) {
+	replicas := newTestReplicaSet(t, "")
+	assert.Equal(t, ReplicaLoadBalancingRoundRobin, replicas.loadBalancing)
+
+	for i := 0; i < 6; i++ {
+		assert.Same(t, replicas.replicas[i%3].db, replicas.pick())
+	}
+}
+
+func TestReplicaSetPickLeastConnections(t *testing.T) {
+	replicas := newTestReplicaSet(t, ReplicaLoadBalancingLeastConnections)
+
+	// Unread rows keep their connection in use
+	rows, err := replicas.replicas[0].sqlDB.Query("SELECT 1")
+	assert.Nil(t, err)
+
+	defer rows.Close()
+
+	for i := 0; i < 4; i++ {
+		assert.NotSame(t, replicas.replicas[0].db, replicas.pick())
+	}
+
+	assert.Same(t, replicas.replicas[1].db, replicas.pick())
+}
+
+func TestNewReplicaSet(t *testing.T) {
+	replicas, err := newReplicaSet(DatabaseConfig{Driver: DriverTypeSQLite3})
+	assert.Nil(t, err)
+	assert.Nil(t, replicas)
+
+	_, err = newReplicaSet(DatabaseConfig{
+		Driver:               DriverTypeSQLite3,
+		Replicas:             []ReplicaConfig{{URL: "file:replica.db"}},
+		ReplicaLoadBalancing: "random",
+	})
+	assert.ErrorIs(t, err, ErrInvalidReplicaLoadBalancing)
+}
+
+func TestGetReadDB(t *testing.T) {
+	replicas := newTestReplicaSet(t, ReplicaLoadBalancingRoundRobin)
+	primary := goqu.New("sqlite3", replicas.replicas[0].sqlDB)
+	tx := &goqu.TxDatabase{}
+
+	assert.Same(t, primary, getReadDB(context.Background(), primary, nil))
+	assert.Same(t, primary, getReadDB(WithPrimary(context.Background()), primary, replicas))
+	assert.Same(t, tx, getReadDB(context.Background(), tx, replicas))
+	assert.Same(t, replicas.replicas[0].db, getReadDB(context.Background(), primary, replicas))
+}
@@ -0,0 +1,217 @@
+package miniorm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 5
+	defaultRetryInitialDelay   = 50 * time.Millisecond
+	defaultRetryMaxDelay       = 2 * time.Second
+	defaultRetryMultiplier     = 2
+	defaultRetryJitterFactor   = 0.2
+	defaultRetryMaxElapsedTime = 10 * time.Second
+)
+
+// RetryPolicy decides whether and when a transaction or a single statement failing with err is retried
+type RetryPolicy interface {
+	// NextDelay returns the delay before the next attempt, after attempt (starting at 1) failed with err and elapsed
+	// time passed since the first attempt. ok is false if the operation must not be retried.
+	NextDelay(driverType DriverType, err error, attempt uint, elapsed time.Duration) (delay time.Duration, ok bool)
+}
+
+// ExponentialBackoffRetryPolicy retries the errors classified as transient by IsRetryableError, with a delay growing
+// exponentially from InitialDelay up to MaxDelay, randomized by JitterFactor. Zero limits are not enforced.
+type ExponentialBackoffRetryPolicy struct {
+	MaxAttempts    uint
+	InitialDelay   time.Duration
+	MaxDelay       time.Duration
+	Multiplier     float64
+	JitterFactor   float64
+	MaxElapsedTime time.Duration
+}
+
+// NewExponentialBackoffRetryPolicy returns an ExponentialBackoffRetryPolicy with default settings
+func NewExponentialBackoffRetryPolicy() *ExponentialBackoffRetryPolicy {
+	return &ExponentialBackoffRetryPolicy{
+		MaxAttempts:    defaultRetryMaxAttempts,
+		InitialDelay:   defaultRetryInitialDelay,
+		MaxDelay:       defaultRetryMaxDelay,
+		Multiplier:     defaultRetryMultiplier,
+		JitterFactor:   defaultRetryJitterFactor,
+		MaxElapsedTime: defaultRetryMaxElapsedTime,
+	}
+}
+
+//nolint:gosec // Random jitter does not need to be too secure
+func (policy *ExponentialBackoffRetryPolicy) NextDelay(
+	driverType DriverType,
+	err error,
+	attempt uint,
+	elapsed time.Duration,
+) (time.Duration, bool) {
+	if !IsRetryableError(driverType, err) {
+		return 0, false
+	}
+
+	if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+		return 0, false
+	}
+
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(policy.InitialDelay) * math.Pow(multiplier, float64(attempt-1))
+	if policy.MaxDelay > 0 && delay > float64(policy.MaxDelay) {
+		delay = float64(policy.MaxDelay)
+	}
+
+	if policy.JitterFactor > 0 {
+		delay += delay * policy.JitterFactor * (rand.Float64()*2 - 1)
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+
+	if policy.MaxElapsedTime > 0 && elapsed+time.Duration(delay) > policy.MaxElapsedTime {
+		return 0, false
+	}
+
+	return time.Duration(delay), true
+}
+
+// sqlite3TransactionRetryPolicy is the retry policy of SQLite3TransactionModeRetry, which retries every error other
+// than the ones returned by go-miniorm itself and by the hooks of entries, after
+// SQLite3TransactionRetryDelayInMillisecond plus or minus SQLite3TransactionRetryJitterInMillisecond
+type sqlite3TransactionRetryPolicy struct {
+	databaseConfig DatabaseConfig
+}
+
+//nolint:gosec // Random sleep time does not need to be too secure
+func (policy *sqlite3TransactionRetryPolicy) NextDelay(_ DriverType, err error, attempt uint, _ time.Duration) (time.Duration, bool) {
+	if attempt >= policy.databaseConfig.SQLite3TransactionMaxRetry ||
+		errors.Is(err, ErrNilEntry) ||
+		errors.Is(err, ErrNotFound) ||
+		errors.Is(err, ErrUpdateNotApplied) ||
+		errors.Is(err, ErrStaleEntry) ||
+		errors.Is(err, ErrGeneratedKeyNotSupported) ||
+		errors.Is(err, ErrCloseInTransaction) ||
+		errors.As(err, new(*hookError)) {
+		return 0, false
+	}
+
+	sleepTimeInMillisecond := policy.databaseConfig.SQLite3TransactionRetryDelayInMillisecond -
+		policy.databaseConfig.SQLite3TransactionRetryJitterInMillisecond +
+		rand.Intn(policy.databaseConfig.SQLite3TransactionRetryJitterInMillisecond*2+1)
+
+	return time.Duration(sleepTimeInMillisecond) * time.Millisecond, true
+}
+
+// IsRetryableError returns whether err is a transient error of the database engine, i.e. a deadlock, a lock wait
+// timeout or a serialization failure, after which the failed transaction or statement can be retried
+func IsRetryableError(driverType DriverType, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	kind, _ := classifyDriverError(driverType, err)
+
+	return kind == ErrDeadlock || kind == ErrLockTimeout || kind == ErrSerializationFailure
+}
+
+// getErrorCodeField returns the integer field fieldName of the first error in the chain of err whose struct type is
+// named typeName. Reflection is used since the driver packages are imported by the application, not by go-miniorm.
+func getErrorCodeField(err error, typeName, fieldName string) (int64, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		errorValue := reflect.ValueOf(err)
+		if errorValue.Kind() == reflect.Ptr {
+			if errorValue.IsNil() {
+				continue
+			}
+
+			errorValue = errorValue.Elem()
+		}
+
+		if errorValue.Kind() != reflect.Struct || errorValue.Type().Name() != typeName {
+			continue
+		}
+
+		field := errorValue.FieldByName(fieldName)
+
+		switch {
+		case !field.IsValid():
+			continue
+		case isIntKind(field.Kind()):
+			return field.Int(), true
+		case isUintKind(field.Kind()):
+			return int64(field.Uint()), true
+		}
+	}
+
+	return 0, false
+}
+
+// withRetry runs executeFunc until it succeeds or retryPolicy stops retrying, sleeping between the attempts.
+// executeFunc is only run once if retryPolicy is nil.
+func withRetry(ctx context.Context, retryPolicy RetryPolicy, driverType DriverType, executeFunc func() error) error {
+	if retryPolicy == nil {
+		return executeFunc()
+	}
+
+	startTime := time.Now()
+
+	for attempt := uint(1); ; attempt++ {
+		err := executeFunc()
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		delay, ok := retryPolicy.NextDelay(driverType, err, attempt, time.Since(startTime))
+		if !ok {
+			return err
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retrySQLDatabase retries the single statements executed outside of transactions according to retryPolicy. Rows
+// are only retried until the query returns, not while they are scanned.
+type retrySQLDatabase struct {
+	*sql.DB
+	retryPolicy RetryPolicy
+	driverType  DriverType
+}
+
+func (db *retrySQLDatabase) ExecContext(ctx context.Context, query string, args ...interface{}) (result sql.Result, err error) {
+	err = withRetry(ctx, db.retryPolicy, db.driverType, func() error {
+		result, err = db.DB.ExecContext(ctx, query, args...)
+		return err
+	})
+
+	return result, err
+}
+
+func (db *retrySQLDatabase) QueryContext(ctx context.Context, query string, args ...interface{}) (rows *sql.Rows, err error) {
+	err = withRetry(ctx, db.retryPolicy, db.driverType, func() error {
+		rows, err = db.DB.QueryContext(ctx, query, args...)
+		return err
+	})
+
+	return rows, err
+}
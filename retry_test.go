@@ -0,0 +1,159 @@
+package miniorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPostgresError struct {
+	sqlState string
+}
+
+func (err *testPostgresError) Error() string {
+	return "postgres error " + err.sqlState
+}
+
+func (err *testPostgresError) SQLState() string {
+	return err.sqlState
+}
+
+type testMSSQLError struct {
+	number int32
+}
+
+func (err testMSSQLError) Error() string {
+	return fmt.Sprintf("mssql error %d", err.number)
+}
+
+func (err testMSSQLError) SQLErrorNumber() int32 {
+	return err.number
+}
+
+type testRetryPolicy struct {
+	maxAttempts uint
+}
+
+func (policy *testRetryPolicy) NextDelay(_ DriverType, _ error, attempt uint, _ time.Duration) (time.Duration, bool) {
+	return time.Millisecond, attempt < policy.maxAttempts
+}
+
+func TestIsRetryableError(t *testing.T) {
+	t.Parallel()
+
+	testCaseList := []struct {
+		driverType DriverType
+		err        error
+		retryable  bool
+	}{
+		{DriverTypeMySQL, &mysql.MySQLError{Number: 1213}, true},
+		{DriverTypeMySQL, fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: 1205}), true},
+		{DriverTypeMySQL, &mysql.MySQLError{Number: 1062}, false},
+		{DriverTypePostgres, &testPostgresError{sqlState: "40001"}, true},
+		{DriverTypePostgres, &testPostgresError{sqlState: "40P01"}, true},
+		{DriverTypePostgres, &testPostgresError{sqlState: "23505"}, false},
+		{DriverTypeMSSQL, testMSSQLError{number: 1205}, true},
+		{DriverTypeMSSQL, testMSSQLError{number: 2627}, false},
+		{DriverTypeSQLite3, sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{DriverTypeSQLite3, &sqlite3.Error{Code: sqlite3.ErrLocked}, true},
+		{DriverTypeSQLite3, sqlite3.Error{Code: sqlite3.ErrConstraint}, false},
+		{DriverTypeSQLite3, &mysql.MySQLError{Number: 5}, false},
+		{DriverTypeMySQL, errors.New("error"), false},
+		{DriverTypeMySQL, nil, false},
+		{DriverTypeMySQL, ErrNotFound, false},
+	}
+
+	for _, testCase := range testCaseList {
+		assert.Equal(t, testCase.retryable, IsRetryableError(testCase.driverType, testCase.err), testCase.err)
+	}
+}
+
+func TestExponentialBackoffRetryPolicy(t *testing.T) {
+	t.Parallel()
+
+	retryPolicy := &ExponentialBackoffRetryPolicy{
+		MaxAttempts:    4,
+		InitialDelay:   100 * time.Millisecond,
+		MaxDelay:       300 * time.Millisecond,
+		Multiplier:     2,
+		MaxElapsedTime: time.Second,
+	}
+	retryableErr := &mysql.MySQLError{Number: 1213}
+
+	delay, ok := retryPolicy.NextDelay(DriverTypeMySQL, retryableErr, 1, 0)
+	assert.True(t, ok)
+	assert.Equal(t, 100*time.Millisecond, delay)
+
+	delay, ok = retryPolicy.NextDelay(DriverTypeMySQL, retryableErr, 2, 0)
+	assert.True(t, ok)
+	assert.Equal(t, 200*time.Millisecond, delay)
+
+	delay, ok = retryPolicy.NextDelay(DriverTypeMySQL, retryableErr, 3, 0)
+	assert.True(t, ok)
+	assert.Equal(t, 300*time.Millisecond, delay)
+
+	_, ok = retryPolicy.NextDelay(DriverTypeMySQL, retryableErr, 4, 0)
+	assert.False(t, ok)
+
+	_, ok = retryPolicy.NextDelay(DriverTypeMySQL, retryableErr, 1, 950*time.Millisecond)
+	assert.False(t, ok)
+
+	_, ok = retryPolicy.NextDelay(DriverTypeMySQL, ErrNotFound, 1, 0)
+	assert.False(t, ok)
+
+	retryPolicy = NewExponentialBackoffRetryPolicy()
+	for i := 0; i < 100; i++ {
+		delay, ok = retryPolicy.NextDelay(DriverTypeMySQL, retryableErr, 1, 0)
+		assert.True(t, ok)
+		assert.InDelta(t, float64(defaultRetryInitialDelay), float64(delay), float64(defaultRetryInitialDelay)*defaultRetryJitterFactor)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	t.Parallel()
+
+	attempts := 0
+	err := withRetry(context.Background(), &testRetryPolicy{maxAttempts: 3}, DriverTypeMySQL, func() error {
+		attempts++
+		return errors.New("error")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = withRetry(context.Background(), &testRetryPolicy{maxAttempts: 3}, DriverTypeMySQL, func() error {
+		attempts++
+		if attempts < 2 {
+			return errors.New("error")
+		}
+
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, attempts)
+
+	attempts = 0
+	err = withRetry(context.Background(), nil, DriverTypeMySQL, func() error {
+		attempts++
+		return errors.New("error")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, attempts)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts = 0
+	err = withRetry(ctx, &testRetryPolicy{maxAttempts: 3}, DriverTypeMySQL, func() error {
+		attempts++
+		cancel()
+
+		return errors.New("error")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, attempts)
+}
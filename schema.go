@@ -0,0 +1,459 @@
+package miniorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type SchemaMismatchKind string
+
+const (
+	// SchemaMismatchKindMissingTable is a model whose table does not exist
+	SchemaMismatchKindMissingTable SchemaMismatchKind = "missing table"
+	// SchemaMismatchKindMissingColumn is a field of a model whose column does not exist
+	SchemaMismatchKindMissingColumn SchemaMismatchKind = "missing column"
+	// SchemaMismatchKindRequiredColumn is a NOT NULL column without default which is not a field of the model, so that
+	// inserting the model fails
+	SchemaMismatchKindRequiredColumn SchemaMismatchKind = "required column"
+	// SchemaMismatchKindColumnType is a column whose type cannot store the type of its field
+	SchemaMismatchKindColumnType SchemaMismatchKind = "column type"
+	// SchemaMismatchKindMissingUniqueIndex is a unique expression of a model which is not backed by a unique index or
+	// primary key on exactly its columns
+	SchemaMismatchKindMissingUniqueIndex SchemaMismatchKind = "missing unique index"
+
+	// Type families of the data types of columns, used to check the types of the fields of models
+	schemaTypeFamilyBool    = "bool"
+	schemaTypeFamilyInteger = "integer"
+	schemaTypeFamilyFloat   = "float"
+	schemaTypeFamilyString  = "string"
+	schemaTypeFamilyBytes   = "bytes"
+	schemaTypeFamilyTime    = "time"
+)
+
+var (
+	ErrSchemaMismatch = errors.New("schema does not match the models")
+
+	// Statements returning the name, data type, whether it is nullable and whether it has a default (including auto
+	// incremented and generated columns) of the columns of a table
+	configDriverTypeToSchemaColumnsStatement = map[DriverType]string{
+		DriverTypeMSSQL: `
+			SELECT c.COLUMN_NAME, c.DATA_TYPE, CASE WHEN c.IS_NULLABLE = 'YES' THEN 1 ELSE 0 END,
+				CASE WHEN c.COLUMN_DEFAULT IS NOT NULL
+					OR COLUMNPROPERTY(OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME)), c.COLUMN_NAME, 'IsIdentity') = 1
+					OR COLUMNPROPERTY(OBJECT_ID(QUOTENAME(c.TABLE_SCHEMA) + '.' + QUOTENAME(c.TABLE_NAME)), c.COLUMN_NAME, 'IsComputed') = 1
+				THEN 1 ELSE 0 END
+			FROM INFORMATION_SCHEMA.COLUMNS c
+			WHERE c.TABLE_SCHEMA = SCHEMA_NAME() AND c.TABLE_NAME = @p1`,
+		DriverTypeMySQL: `
+			SELECT COLUMN_NAME, DATA_TYPE, CASE WHEN IS_NULLABLE = 'YES' THEN 1 ELSE 0 END,
+				CASE WHEN COLUMN_DEFAULT IS NOT NULL OR EXTRA <> '' THEN 1 ELSE 0 END
+			FROM information_schema.COLUMNS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?`,
+		DriverTypePostgres: `
+			SELECT column_name, data_type, CASE WHEN is_nullable = 'YES' THEN 1 ELSE 0 END,
+				CASE WHEN column_default IS NOT NULL OR is_identity = 'YES' OR is_generated <> 'NEVER' THEN 1 ELSE 0 END
+			FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = $1`,
+		// An INTEGER PRIMARY KEY is an alias of the rowid, which is generated
+		DriverTypeSQLite3: `
+			SELECT name, type, CASE WHEN "notnull" = 0 AND pk = 0 THEN 1 ELSE 0 END,
+				CASE WHEN dflt_value IS NOT NULL OR (pk = 1 AND UPPER(type) = 'INTEGER') THEN 1 ELSE 0 END
+			FROM pragma_table_info(?1)`,
+	}
+
+	// Statements returning the name and a column of the unique indexes of a table, including its primary key
+	configDriverTypeToSchemaUniqueIndexesStatement = map[DriverType]string{
+		DriverTypeMSSQL: `
+			SELECT i.name, c.name
+			FROM sys.indexes i
+			JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
+			JOIN sys.columns c ON c.object_id = ic.object_id AND c.column_id = ic.column_id
+			WHERE i.object_id = OBJECT_ID(QUOTENAME(SCHEMA_NAME()) + '.' + QUOTENAME(@p1))
+				AND i.is_unique = 1 AND i.has_filter = 0 AND ic.is_included_column = 0`,
+		DriverTypeMySQL: `
+			SELECT INDEX_NAME, COLUMN_NAME
+			FROM information_schema.STATISTICS
+			WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND NON_UNIQUE = 0`,
+		DriverTypePostgres: `
+			SELECT i.relname, a.attname
+			FROM pg_index x
+			JOIN pg_class t ON t.oid = x.indrelid
+			JOIN pg_class i ON i.oid = x.indexrelid
+			JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(x.indkey)
+			WHERE t.relname = $1 AND t.relnamespace = current_schema()::regnamespace
+				AND x.indisunique AND x.indpred IS NULL`,
+		// The rowid alias INTEGER PRIMARY KEY has no index
+		DriverTypeSQLite3: `
+			SELECT il.name, ii.name
+			FROM pragma_index_list(?1) il
+			JOIN pragma_index_info(il.name) ii
+			WHERE il."unique" = 1 AND il.partial = 0
+			UNION ALL
+			SELECT 'primary key', name FROM pragma_table_info(?1) WHERE pk > 0`,
+	}
+
+	// Type families of the data types reported by the database engines, lower cased and without length
+	schemaDataTypeToTypeFamily = map[string]string{
+		"bit":                         schemaTypeFamilyBool,
+		"bool":                        schemaTypeFamilyBool,
+		"boolean":                     schemaTypeFamilyBool,
+		"tinyint":                     schemaTypeFamilyInteger,
+		"smallint":                    schemaTypeFamilyInteger,
+		"mediumint":                   schemaTypeFamilyInteger,
+		"int":                         schemaTypeFamilyInteger,
+		"integer":                     schemaTypeFamilyInteger,
+		"bigint":                      schemaTypeFamilyInteger,
+		"int2":                        schemaTypeFamilyInteger,
+		"int4":                        schemaTypeFamilyInteger,
+		"int8":                        schemaTypeFamilyInteger,
+		"smallserial":                 schemaTypeFamilyInteger,
+		"serial":                      schemaTypeFamilyInteger,
+		"bigserial":                   schemaTypeFamilyInteger,
+		"real":                        schemaTypeFamilyFloat,
+		"float":                       schemaTypeFamilyFloat,
+		"float4":                      schemaTypeFamilyFloat,
+		"float8":                      schemaTypeFamilyFloat,
+		"double":                      schemaTypeFamilyFloat,
+		"double precision":            schemaTypeFamilyFloat,
+		"decimal":                     schemaTypeFamilyFloat,
+		"numeric":                     schemaTypeFamilyFloat,
+		"money":                       schemaTypeFamilyFloat,
+		"smallmoney":                  schemaTypeFamilyFloat,
+		"char":                        schemaTypeFamilyString,
+		"character":                   schemaTypeFamilyString,
+		"varchar":                     schemaTypeFamilyString,
+		"character varying":           schemaTypeFamilyString,
+		"nchar":                       schemaTypeFamilyString,
+		"nvarchar":                    schemaTypeFamilyString,
+		"text":                        schemaTypeFamilyString,
+		"tinytext":                    schemaTypeFamilyString,
+		"mediumtext":                  schemaTypeFamilyString,
+		"longtext":                    schemaTypeFamilyString,
+		"ntext":                       schemaTypeFamilyString,
+		"citext":                      schemaTypeFamilyString,
+		"clob":                        schemaTypeFamilyString,
+		"enum":                        schemaTypeFamilyString,
+		"set":                         schemaTypeFamilyString,
+		"json":                        schemaTypeFamilyString,
+		"jsonb":                       schemaTypeFamilyString,
+		"xml":                         schemaTypeFamilyString,
+		"uuid":                        schemaTypeFamilyString,
+		"uniqueidentifier":            schemaTypeFamilyString,
+		"binary":                      schemaTypeFamilyBytes,
+		"varbinary":                   schemaTypeFamilyBytes,
+		"blob":                        schemaTypeFamilyBytes,
+		"tinyblob":                    schemaTypeFamilyBytes,
+		"mediumblob":                  schemaTypeFamilyBytes,
+		"longblob":                    schemaTypeFamilyBytes,
+		"bytea":                       schemaTypeFamilyBytes,
+		"image":                       schemaTypeFamilyBytes,
+		"date":                        schemaTypeFamilyTime,
+		"datetime":                    schemaTypeFamilyTime,
+		"datetime2":                   schemaTypeFamilyTime,
+		"smalldatetime":               schemaTypeFamilyTime,
+		"datetimeoffset":              schemaTypeFamilyTime,
+		"time":                        schemaTypeFamilyTime,
+		"time with time zone":         schemaTypeFamilyTime,
+		"time without time zone":      schemaTypeFamilyTime,
+		"timestamp":                   schemaTypeFamilyTime,
+		"timestamp with time zone":    schemaTypeFamilyTime,
+		"timestamp without time zone": schemaTypeFamilyTime,
+	}
+
+	// Type families of the columns which can store each kind of field
+	columnKindToSchemaTypeFamilies = map[columnKind][]string{
+		// MySQL stores BOOLEAN as TINYINT(1)
+		columnKindBool:     {schemaTypeFamilyBool, schemaTypeFamilyInteger},
+		columnKindSmallInt: {schemaTypeFamilyInteger},
+		columnKindInt:      {schemaTypeFamilyInteger},
+		columnKindBigInt:   {schemaTypeFamilyInteger},
+		columnKindFloat:    {schemaTypeFamilyFloat},
+		columnKindDouble:   {schemaTypeFamilyFloat},
+		columnKindText:     {schemaTypeFamilyString},
+		columnKindBytes:    {schemaTypeFamilyBytes, schemaTypeFamilyString},
+		columnKindTime:     {schemaTypeFamilyTime},
+	}
+)
+
+// SchemaMismatch is a difference between a model and its table found by VerifySchema()
+type SchemaMismatch struct {
+	Kind      SchemaMismatchKind
+	TableName string
+	// Columns is the column of the mismatch, or the columns of the unique expression for
+	// SchemaMismatchKindMissingUniqueIndex
+	Columns []string
+	// FieldType and DataType are the Go type of the field and the data type of the column for
+	// SchemaMismatchKindColumnType
+	FieldType string
+	DataType  string
+}
+
+func (mismatch SchemaMismatch) String() string {
+	switch mismatch.Kind {
+	case SchemaMismatchKindMissingTable:
+		return fmt.Sprintf("%s %s", mismatch.Kind, mismatch.TableName)
+	case SchemaMismatchKindColumnType:
+		return fmt.Sprintf(
+			"%s of %s.%s: %s cannot be stored as %s",
+			mismatch.Kind,
+			mismatch.TableName,
+			strings.Join(mismatch.Columns, ", "),
+			mismatch.FieldType,
+			mismatch.DataType,
+		)
+	case SchemaMismatchKindMissingUniqueIndex:
+		return fmt.Sprintf("%s on %s (%s)", mismatch.Kind, mismatch.TableName, strings.Join(mismatch.Columns, ", "))
+	default:
+		return fmt.Sprintf("%s %s.%s", mismatch.Kind, mismatch.TableName, strings.Join(mismatch.Columns, ", "))
+	}
+}
+
+// SchemaError is returned by VerifySchema() if the tables do not match the models
+type SchemaError struct {
+	Mismatches []SchemaMismatch
+}
+
+func (err *SchemaError) Error() string {
+	mismatches := make([]string, 0, len(err.Mismatches))
+	for _, mismatch := range err.Mismatches {
+		mismatches = append(mismatches, mismatch.String())
+	}
+
+	return fmt.Sprintf("%s: %s", ErrSchemaMismatch, strings.Join(mismatches, "; "))
+}
+
+func (err *SchemaError) Is(target error) bool {
+	return target == ErrSchemaMismatch
+}
+
+type schemaColumn struct {
+	name       string
+	dataType   string
+	isNullable bool
+	hasDefault bool
+}
+
+// verifySchema compares the tables of models with their fields and unique expressions, see SchemaMismatchKind
+func verifySchema(
+	ctx context.Context,
+	db DBWrapper,
+	entryInfoProvider *entryInfoProvider,
+	driverType DriverType,
+	models []interface{},
+) error {
+	mismatches := make([]SchemaMismatch, 0)
+
+	for _, model := range models {
+		modelMismatches, err := getSchemaMismatches(ctx, db, entryInfoProvider, driverType, model)
+		if err != nil {
+			return err
+		}
+
+		mismatches = append(mismatches, modelMismatches...)
+	}
+
+	if len(mismatches) > 0 {
+		return &SchemaError{Mismatches: mismatches}
+	}
+
+	return nil
+}
+
+func getSchemaMismatches(
+	ctx context.Context,
+	db DBWrapper,
+	entryInfoProvider *entryInfoProvider,
+	driverType DriverType,
+	model interface{},
+) ([]SchemaMismatch, error) {
+	tableName, err := entryInfoProvider.GetEntryTableName(model)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, _, ok := getModelMetadata(model)
+	if !ok || len(metadata.fields) == 0 {
+		return nil, ErrColumnsNotFound
+	}
+
+	columns, err := getSchemaColumns(ctx, db, driverType, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(columns) == 0 {
+		return []SchemaMismatch{{Kind: SchemaMismatchKindMissingTable, TableName: tableName}}, nil
+	}
+
+	mismatches := make([]SchemaMismatch, 0)
+	modelColumns := make(map[string]bool, len(metadata.fields))
+
+	for _, field := range metadata.fields {
+		modelColumns[field.column] = true
+
+		column, ok := columns[field.column]
+		if !ok {
+			mismatches = append(mismatches, SchemaMismatch{
+				Kind:      SchemaMismatchKindMissingColumn,
+				TableName: tableName,
+				Columns:   []string{field.column},
+			})
+
+			continue
+		}
+
+		if !isSchemaDataTypeCompatible(driverType, column.dataType, field.fieldType) {
+			mismatches = append(mismatches, SchemaMismatch{
+				Kind:      SchemaMismatchKindColumnType,
+				TableName: tableName,
+				Columns:   []string{field.column},
+				FieldType: field.fieldType.String(),
+				DataType:  column.dataType,
+			})
+		}
+	}
+
+	requiredColumns := make([]string, 0)
+
+	for _, column := range columns {
+		if !modelColumns[column.name] && !column.isNullable && !column.hasDefault {
+			requiredColumns = append(requiredColumns, column.name)
+		}
+	}
+
+	sort.Strings(requiredColumns)
+
+	for _, requiredColumn := range requiredColumns {
+		mismatches = append(mismatches, SchemaMismatch{
+			Kind:      SchemaMismatchKindRequiredColumn,
+			TableName: tableName,
+			Columns:   []string{requiredColumn},
+		})
+	}
+
+	uniqueColumns, ok := entryInfoProvider.GetUniqueColumns(model)
+	if !ok {
+		return mismatches, nil
+	}
+
+	uniqueIndexes, err := getSchemaUniqueIndexes(ctx, db, driverType, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, indexColumns := range uniqueIndexes {
+		if isSameColumnSet(indexColumns, uniqueColumns) {
+			return mismatches, nil
+		}
+	}
+
+	return append(mismatches, SchemaMismatch{
+		Kind:      SchemaMismatchKindMissingUniqueIndex,
+		TableName: tableName,
+		Columns:   uniqueColumns,
+	}), nil
+}
+
+func getSchemaColumns(ctx context.Context, db DBWrapper, driverType DriverType, tableName string) (map[string]schemaColumn, error) {
+	rows, err := db.QueryContext(ctx, configDriverTypeToSchemaColumnsStatement[driverType], tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	columns := make(map[string]schemaColumn)
+
+	for rows.Next() {
+		column := schemaColumn{}
+		if err := rows.Scan(&column.name, &column.dataType, &column.isNullable, &column.hasDefault); err != nil {
+			return nil, err
+		}
+
+		columns[column.name] = column
+	}
+
+	return columns, rows.Err()
+}
+
+func getSchemaUniqueIndexes(ctx context.Context, db DBWrapper, driverType DriverType, tableName string) (map[string][]string, error) {
+	rows, err := db.QueryContext(ctx, configDriverTypeToSchemaUniqueIndexesStatement[driverType], tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	uniqueIndexes := make(map[string][]string)
+
+	for rows.Next() {
+		var indexName, column string
+		if err := rows.Scan(&indexName, &column); err != nil {
+			return nil, err
+		}
+
+		uniqueIndexes[indexName] = append(uniqueIndexes[indexName], column)
+	}
+
+	return uniqueIndexes, rows.Err()
+}
+
+// isSchemaDataTypeCompatible returns whether a column of dataType can store fieldType. Unknown data types and field
+// types are assumed to be compatible.
+func isSchemaDataTypeCompatible(driverType DriverType, dataType string, fieldType reflect.Type) bool {
+	kind, _, ok := getColumnKind(fieldType)
+	if !ok {
+		return true
+	}
+
+	typeFamily, ok := getSchemaTypeFamily(driverType, dataType)
+	if !ok {
+		return true
+	}
+
+	for _, compatibleTypeFamily := range columnKindToSchemaTypeFamilies[kind] {
+		if typeFamily == compatibleTypeFamily {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getSchemaTypeFamily returns the type family of dataType. SQLite3 accepts any declared type, so unknown declared
+// types fall back to the type affinity rules, see https://www.sqlite.org/datatype3.html#determination_of_column_affinity
+func getSchemaTypeFamily(driverType DriverType, dataType string) (string, bool) {
+	normalizedDataType := strings.ToLower(dataType)
+	if separatorPosition := strings.Index(normalizedDataType, "("); separatorPosition >= 0 {
+		normalizedDataType = normalizedDataType[:separatorPosition]
+	}
+
+	normalizedDataType = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(normalizedDataType), "unsigned"))
+
+	if typeFamily, ok := schemaDataTypeToTypeFamily[normalizedDataType]; ok {
+		return typeFamily, true
+	}
+
+	if driverType != DriverTypeSQLite3 {
+		return "", false
+	}
+
+	switch {
+	case strings.Contains(normalizedDataType, "int"):
+		return schemaTypeFamilyInteger, true
+	case strings.Contains(normalizedDataType, "char") ||
+		strings.Contains(normalizedDataType, "clob") ||
+		strings.Contains(normalizedDataType, "text"):
+		return schemaTypeFamilyString, true
+	case strings.Contains(normalizedDataType, "real") ||
+		strings.Contains(normalizedDataType, "floa") ||
+		strings.Contains(normalizedDataType, "doub"):
+		return schemaTypeFamilyFloat, true
+	default:
+		return "", false
+	}
+}
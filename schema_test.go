@@ -0,0 +1,63 @@
+package miniorm
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSchemaDataTypeCompatible(t *testing.T) {
+	t.Parallel()
+
+	testCaseList := []struct {
+		driverType     DriverType
+		dataType       string
+		fieldType      reflect.Type
+		expectedResult bool
+	}{
+		{driverType: DriverTypeMySQL, dataType: "tinyint", fieldType: reflect.TypeOf(false), expectedResult: true},
+		{driverType: DriverTypeMySQL, dataType: "bigint unsigned", fieldType: reflect.TypeOf(uint64(0)), expectedResult: true},
+		{driverType: DriverTypeMySQL, dataType: "text", fieldType: reflect.TypeOf(int64(0)), expectedResult: false},
+		{driverType: DriverTypeMySQL, dataType: "longblob", fieldType: reflect.TypeOf([]byte{}), expectedResult: true},
+		{driverType: DriverTypeMySQL, dataType: "datetime", fieldType: reflect.TypeOf(&time.Time{}), expectedResult: true},
+		{driverType: DriverTypePostgres, dataType: "timestamp with time zone", fieldType: reflect.TypeOf(""), expectedResult: false},
+		{driverType: DriverTypePostgres, dataType: "uuid", fieldType: reflect.TypeOf(""), expectedResult: true},
+		{driverType: DriverTypePostgres, dataType: "text", fieldType: reflect.TypeOf([]byte{}), expectedResult: true},
+		{driverType: DriverTypePostgres, dataType: "bytea", fieldType: reflect.TypeOf(""), expectedResult: false},
+		{driverType: DriverTypePostgres, dataType: "USER-DEFINED", fieldType: reflect.TypeOf(""), expectedResult: true},
+		{driverType: DriverTypeMSSQL, dataType: "bit", fieldType: reflect.TypeOf(sql.NullBool{}), expectedResult: true},
+		{driverType: DriverTypeMSSQL, dataType: "nvarchar", fieldType: reflect.TypeOf(sql.NullFloat64{}), expectedResult: false},
+		{driverType: DriverTypeSQLite3, dataType: "VARCHAR(255)", fieldType: reflect.TypeOf(""), expectedResult: true},
+		{driverType: DriverTypeSQLite3, dataType: "UNSIGNED BIG INT", fieldType: reflect.TypeOf(int64(0)), expectedResult: true},
+		{driverType: DriverTypeSQLite3, dataType: "NVARCHAR2", fieldType: reflect.TypeOf(float32(0)), expectedResult: false},
+		{driverType: DriverTypeSQLite3, dataType: "", fieldType: reflect.TypeOf(int64(0)), expectedResult: true},
+		{driverType: DriverTypeSQLite3, dataType: "TEXT", fieldType: reflect.TypeOf(struct{}{}), expectedResult: true},
+	}
+
+	for _, testCase := range testCaseList {
+		result := isSchemaDataTypeCompatible(testCase.driverType, testCase.dataType, testCase.fieldType)
+		assert.Equal(t, testCase.expectedResult, result, "%s %s %s", testCase.driverType, testCase.dataType, testCase.fieldType)
+	}
+}
+
+func TestSchemaErrorError(t *testing.T) {
+	t.Parallel()
+
+	err := &SchemaError{Mismatches: []SchemaMismatch{
+		{Kind: SchemaMismatchKindMissingTable, TableName: "table_1"},
+		{Kind: SchemaMismatchKindMissingColumn, TableName: "table_2", Columns: []string{"col_1"}},
+		{Kind: SchemaMismatchKindColumnType, TableName: "table_2", Columns: []string{"col_2"}, FieldType: "int64", DataType: "text"},
+		{Kind: SchemaMismatchKindMissingUniqueIndex, TableName: "table_2", Columns: []string{"col_1", "col_2"}},
+	}}
+
+	assert.ErrorIs(t, err, ErrSchemaMismatch)
+	assert.Equal(
+		t,
+		"schema does not match the models: missing table table_1; missing column table_2.col_1; "+
+			"column type of table_2.col_2: int64 cannot be stored as text; missing unique index on table_2 (col_1, col_2)",
+		err.Error(),
+	)
+}
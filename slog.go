@@ -0,0 +1,45 @@
+//go:build go1.21
+// +build go1.21
+
+package miniorm
+
+import (
+	"context"
+	"log/slog"
+)
+
+var (
+	logLevelToSlogLevel = map[LogLevel]slog.Level{
+		LogLevelDebug: slog.LevelDebug,
+		LogLevelInfo:  slog.LevelInfo,
+		LogLevelWarn:  slog.LevelWarn,
+		LogLevelError: slog.LevelError,
+	}
+)
+
+// NewSlogLogger returns a StructuredLogger writing to logger, with the fields as attributes
+func NewSlogLogger(logger *slog.Logger) StructuredLogger {
+	return &slogLogger{logger: logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (logger *slogLogger) Log(ctx context.Context, level LogLevel, message string, fields ...LogField) {
+	slogLevel, ok := logLevelToSlogLevel[level]
+	if !ok {
+		slogLevel = slog.LevelInfo
+	}
+
+	if !logger.logger.Enabled(ctx, slogLevel) {
+		return
+	}
+
+	attributes := make([]slog.Attr, 0, len(fields))
+	for _, field := range fields {
+		attributes = append(attributes, slog.Any(field.Key, field.Value))
+	}
+
+	logger.logger.LogAttrs(ctx, slogLevel, message, attributes...)
+}
@@ -0,0 +1,32 @@
+//go:build go1.21
+// +build go1.21
+
+package miniorm
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(buffer, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(_ []string, attribute slog.Attr) slog.Attr {
+			if attribute.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+
+			return attribute
+		},
+	})))
+
+	logger.Log(context.Background(), LogLevelDebug, "statement executed", LogField{Key: LogFieldSQL, Value: "SELECT 1"})
+	logger.Log(context.Background(), LogLevelWarn, "slow statement", LogField{Key: LogFieldSQL, Value: "SELECT 2"})
+
+	assert.Equal(t, "level=WARN msg=\"slow statement\" sql=\"SELECT 2\"\n", buffer.String())
+}
@@ -0,0 +1,139 @@
+package miniorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+// ErrSoftDeleteModelNotRegistered is returned by the operations taking a table name, e.g. Count() or DeleteWhere(), on
+// the table of a soft deleted model missing from DatabaseConfig.Models, which would include its soft deleted rows
+var ErrSoftDeleteModelNotRegistered = errors.New("expected the soft deleted model of the table to be listed in Models")
+
+// withSoftDeleteFilter returns expression, additionally excluding soft deleted rows if softDeleteColumn is not empty
+func withSoftDeleteFilter(expression exp.Expression, softDeleteColumn string) exp.Expression {
+	if softDeleteColumn == "" {
+		return expression
+	}
+
+	return goqu.And(expression, goqu.C(softDeleteColumn).IsNull())
+}
+
+// getQueryScopedExpression returns the expression of params, excluding soft deleted rows unless unscoped
+func getQueryScopedExpression(entryInfoProvider *entryInfoProvider, params QueryParams, unscoped bool) exp.Expression {
+	if params.Expression == nil {
+		// goqu renders a nil expression as WHERE NULL, which matches no rows
+		params.Expression = goqu.Ex{}
+	}
+
+	if unscoped {
+		return params.Expression
+	}
+
+	softDeleteColumn, ok := entryInfoProvider.GetEntryListSoftDeleteColumn(params.EntryList)
+	if !ok {
+		// The entries may not be the model of the table, e.g. for grouped results
+		_, softDeleteColumn, _ = entryInfoProvider.GetTableSoftDeleteModel(params.TableName)
+	}
+
+	return withSoftDeleteFilter(params.Expression, softDeleteColumn)
+}
+
+// getTableScopedExpression returns expression, excluding the soft deleted rows of tableName unless unscoped
+func getTableScopedExpression(
+	entryInfoProvider *entryInfoProvider,
+	tableName string,
+	expression exp.Expression,
+	unscoped bool,
+) (exp.Expression, error) {
+	if expression == nil {
+		expression = goqu.Ex{}
+	}
+
+	if unscoped {
+		return expression, nil
+	}
+
+	if entryInfoProvider.IsUnregisteredSoftDeleteTable(tableName) {
+		return nil, ErrSoftDeleteModelNotRegistered
+	}
+
+	_, softDeleteColumn, _ := entryInfoProvider.GetTableSoftDeleteModel(tableName)
+
+	return withSoftDeleteFilter(expression, softDeleteColumn), nil
+}
+
+// getTableSoftDeleteRecord returns the record marking the rows of tableName as soft deleted now
+func getTableSoftDeleteRecord(entryInfoProvider *entryInfoProvider, tableName string) (goqu.Record, bool, error) {
+	if entryInfoProvider.IsUnregisteredSoftDeleteTable(tableName) {
+		return nil, false, ErrSoftDeleteModelNotRegistered
+	}
+
+	model, softDeleteColumn, ok := entryInfoProvider.GetTableSoftDeleteModel(tableName)
+	if !ok {
+		return nil, false, nil
+	}
+
+	deletedAt := time.Now().UTC()
+
+	deletedModel, ok := entryInfoProvider.CopyEntry(model)
+	if !ok {
+		return goqu.Record{softDeleteColumn: deletedAt}, true, nil
+	}
+
+	entryInfoProvider.SetDeletedAt(deletedModel, deletedAt)
+
+	record := goqu.Record{softDeleteColumn: entryInfoProvider.GetSoftDeleteValue(deletedModel, softDeleteColumn, deletedAt)}
+
+	return record, true, nil
+}
+
+// softDelete sets the soft delete column of entry instead of deleting its row
+func softDelete(
+	ctx context.Context,
+	orm ORM,
+	entryInfoProvider *entryInfoProvider,
+	entry interface{},
+	softDeleteColumn string,
+	unscoped bool,
+) error {
+	entryTableName, err := entryInfoProvider.GetEntryTableName(entry)
+	if err != nil {
+		return err
+	}
+
+	selectEntryExpression, err := entryInfoProvider.GetEntryScopedSelectExpression(entry, unscoped)
+	if err != nil {
+		return err
+	}
+
+	deletedAt := time.Now().UTC()
+
+	deletedEntry, ok := entryInfoProvider.CopyEntry(entry)
+	if !ok {
+		deletedEntry = entry
+	}
+
+	entryInfoProvider.SetDeletedAt(deletedEntry, deletedAt)
+
+	// selectEntryExpression is already scoped, and the model of entry may be missing from the models
+	rowsAffected, err := orm.Unscoped().UpdateWhere(ctx, entryTableName, selectEntryExpression, goqu.Record{
+		softDeleteColumn: entryInfoProvider.GetSoftDeleteValue(deletedEntry, softDeleteColumn, deletedAt),
+	})
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	if ok {
+		entryInfoProvider.SetEntry(entry, deletedEntry)
+	}
+
+	return nil
+}
@@ -3,46 +3,104 @@ package miniorm
 
 import (
 	"context"
-	"errors"
+	"database/sql"
+	"fmt"
 	"math"
-	"math/rand"
-	"sync"
-	"time"
+	"strings"
 
 	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exp"
 )
 
 var (
-	sqlite3ORMTxLock = new(sync.Mutex)
+	// sqlite3ORMTxLock is a channel based mutex, so that waiting for the lock can be cancelled via context
+	sqlite3ORMTxLock = make(chan struct{}, 1)
 )
 
 type SQLite3ORM struct {
 	db                DBWrapper
+	pool              connectionPool
+	ownsPool          bool
+	replicas          *replicaSet
 	entryInfoProvider *entryInfoProvider
+	redactor          *logRedactor
 	databaseConfig    DatabaseConfig
+	savepointDepth    uint
+	unscoped          bool
+	preloads          []string
 }
 
 func NewSQLite3ORM(databaseConfig DatabaseConfig) (ORM, error) {
-	goquDB, err := newGoquDatabase(databaseConfig)
+	goquDB, sqlDB, err := newGoquDatabase(databaseConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	goquDB.Logger(databaseConfig.Logger)
+	orm, err := newSQLite3ORM(goquDB, sqlDB, true, databaseConfig)
+	if err != nil {
+		_ = sqlDB.Close()
+	}
 
-	return &SQLite3ORM{
+	return orm, err
+}
+
+// NewSQLite3ORMFromSQLDatabase is NewORMFromSQLDatabase for SQLite3
+func NewSQLite3ORMFromSQLDatabase(db *sql.DB, databaseConfig DatabaseConfig) (ORM, error) {
+	databaseConfig.Driver = DriverTypeSQLite3
+
+	if err := waitForDatabase(db, databaseConfig); err != nil {
+		return nil, err
+	}
+
+	return newSQLite3ORM(newGoquDatabaseFromSQLDatabase(db, databaseConfig), db, false, databaseConfig)
+}
+
+// NewSQLite3ORMFromGoquDatabase is NewORMFromGoquDatabase for SQLite3
+func NewSQLite3ORMFromGoquDatabase(goquDB *goqu.Database, databaseConfig DatabaseConfig) (ORM, error) {
+	databaseConfig.Driver = DriverTypeSQLite3
+
+	pool, err := getGoquConnectionPool(goquDB, databaseConfig.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := waitForDatabase(pool, databaseConfig); err != nil {
+		return nil, err
+	}
+
+	return newSQLite3ORM(instrumentGoquDatabase(goquDB, databaseConfig), pool, false, databaseConfig)
+}
+
+func newSQLite3ORM(goquDB *goqu.Database, pool connectionPool, ownsPool bool, databaseConfig DatabaseConfig) (ORM, error) {
+	replicas, err := newReplicaSet(databaseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInstrumentedORM(&SQLite3ORM{
 		db:                goquDB,
-		entryInfoProvider: newEntryInfoProvider(),
+		pool:              pool,
+		ownsPool:          ownsPool,
+		replicas:          replicas,
+		entryInfoProvider: newEntryInfoProvider(databaseConfig.Models...),
+		redactor:          newLogRedactor(databaseConfig),
 		databaseConfig:    databaseConfig,
-	}, nil
+	}, databaseConfig), nil
 }
 
-func (orm *SQLite3ORM) Create(ctx context.Context, entry interface{}) error {
+func (orm *SQLite3ORM) Create(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
 
+	return withCreateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*SQLite3ORM).create(ctx, entry)
+	})
+}
+
+func (orm *SQLite3ORM) create(ctx context.Context, entry interface{}) error {
 	orm.entryInfoProvider.OnCreateIfEntryIsOnCreator(entry)
 
 	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
@@ -50,6 +108,17 @@ func (orm *SQLite3ORM) Create(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, entry)
+
+	var setKey func(key int64) error
+
+	if _, keyDestination, ok := orm.entryInfoProvider.GetKeyDestination(entry); ok {
+		setKey, err = getIntegerKeySetter(keyDestination)
+		if err != nil {
+			return err
+		}
+	}
+
 	result, err := orm.GetDBWrapper().
 		Insert(entryTableName).
 		Prepared(true).
@@ -60,52 +129,272 @@ func (orm *SQLite3ORM) Create(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
-	if idSetterEntry, ok := entry.(IDSetter); ok {
+	if setKey != nil {
 		entryID, err := result.LastInsertId()
 		if err != nil {
 			return err
 		}
 
-		idSetterEntry.SetID(entryID)
+		return setKey(entryID)
 	}
 
 	return nil
 }
 
-func (orm *SQLite3ORM) CreateOrUpdate(ctx context.Context, entry interface{}) error {
+func (orm *SQLite3ORM) CreateMany(ctx context.Context, entries interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	return createMany(ctx, orm, orm.entryInfoProvider, orm.databaseConfig, entries, orm.createChunk)
+}
+
+// createChunk inserts entryList in a single INSERT, whose consecutive IDs end at LastInsertId()
+func (orm *SQLite3ORM) createChunk(ctx context.Context, db DBWrapper, tableName string, entryList []interface{}) error {
+	_, keyDestination, isKeySetterEntry := orm.entryInfoProvider.GetKeyDestination(entryList[0])
+	if isKeySetterEntry {
+		if _, err := getIntegerKeySetter(keyDestination); err != nil {
+			return err
+		}
+	}
+
+	ctx = orm.redactor.bindEntries(ctx, tableName, entryList...)
+
+	result, err := db.
+		Insert(tableName).
+		Prepared(true).
+		Rows(entryList...).
+		Executor().
+		ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !isKeySetterEntry {
+		return nil
+	}
+
+	lastEntryID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	return setIntegerKeys(orm.entryInfoProvider, entryList, lastEntryID-int64(len(entryList))+1, 1)
+}
+
+func (orm *SQLite3ORM) CreateOrUpdate(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
 
-	return orm.WithTx(func(txORM ORM) error {
+	// The hooks of entry depend on whether it is created or updated, which the upsert statements cannot tell beforehand
+	if orm.databaseConfig.CreateOrUpdateMode == CreateOrUpdateModeUpsert && !hasCreateOrUpdateHooks(entry) {
+		if uniqueColumns, ok := orm.entryInfoProvider.GetUniqueColumns(entry); ok {
+			if createEntry, updateEntry, ok := orm.entryInfoProvider.GetUpsertEntries(entry); ok {
+				return orm.upsert(ctx, entry, createEntry, updateEntry, uniqueColumns)
+			}
+		}
+	}
+
+	return orm.WithTxContext(ctx, nil, func(txCtx context.Context, txORM ORM) error {
 		entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
 		if err != nil {
 			return err
 		}
 
-		selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
+		// Soft deleted rows are included, so that they are restored by the update rather than conflicting with the insert
+		selectEntryExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
 		if err != nil {
 			return err
 		}
 
+		txCtx = orm.redactor.bindExpression(txCtx, entryTableName, selectEntryExpression)
+
 		count, err := txORM.GetDBWrapper().
 			Select().
 			From(entryTableName).
-			Where(selectEntryUniqueExpression).
-			CountContext(ctx)
+			Prepared(true).
+			Where(selectEntryExpression).
+			CountContext(txCtx)
 		if err != nil {
 			return err
 		}
 
 		if count == 0 {
-			return txORM.Create(ctx, entry)
+			return txORM.Create(txCtx, entry)
+		}
+
+		return txORM.Update(txCtx, entry)
+	})
+}
+
+// upsert creates or updates entry without a prior SELECT. SQLite cannot report whether an
+// INSERT ... ON CONFLICT DO UPDATE statement inserted or updated the row: RETURNING only sees the row after the
+// statement, and last_insert_rowid() is left unchanged by the update, so it still holds the ID of an earlier insert
+// of the connection. This is needed to apply the right hook to entry, so we use INSERT ... ON CONFLICT DO NOTHING
+// instead, followed by an UPDATE ... RETURNING if no row was inserted, in a single transaction.
+func (orm *SQLite3ORM) upsert(
+	ctx context.Context,
+	entry, createEntry, updateEntry interface{},
+	uniqueColumns []string,
+) error {
+	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, createEntry, updateEntry)
+
+	keyColumn, keyDestination, isKeySetterEntry := orm.entryInfoProvider.GetKeyDestination(entry)
+	if isKeySetterEntry {
+		if keyColumn == "" {
+			return ErrKeyGetterExpected
+		}
+
+		if _, err := getIntegerKeySetter(keyDestination); err != nil {
+			return err
+		}
+	}
+
+	sqlStatement, params, err := orm.GetDBWrapper().
+		Insert(entryTableName).
+		Prepared(true).
+		Rows(createEntry).
+		ToSQL()
+	if err != nil {
+		return err
+	}
+
+	// HACK: goqu turns every INSERT with a conflict expression into INSERT OR IGNORE for SQLite, which would also
+	// silence unrelated errors, so we have to manually add the ON CONFLICT clause
+	quotedUniqueColumns := make([]string, 0, len(uniqueColumns))
+	for _, column := range uniqueColumns {
+		quotedUniqueColumns = append(quotedUniqueColumns, fmt.Sprintf("`%s`", column))
+	}
+
+	sqlStatement += fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(quotedUniqueColumns, ", "))
+
+	return orm.WithTxContext(ctx, nil, func(txCtx context.Context, txORM ORM) error {
+		result, err := txORM.GetDBWrapper().ExecContext(txCtx, sqlStatement, params...)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 1 {
+			if isKeySetterEntry {
+				_, createKeyDestination, _ := orm.entryInfoProvider.GetKeyDestination(createEntry)
+
+				setKey, err := getIntegerKeySetter(createKeyDestination)
+				if err != nil {
+					return err
+				}
+
+				entryID, err := result.LastInsertId()
+				if err != nil {
+					return err
+				}
+
+				if err := setKey(entryID); err != nil {
+					return err
+				}
+			}
+
+			orm.entryInfoProvider.SetEntry(entry, createEntry)
+
+			return nil
+		}
+
+		selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(updateEntry)
+		if err != nil {
+			return err
+		}
+
+		updateSQLStatement, updateParams, err := txORM.GetDBWrapper().
+			Update(entryTableName).
+			Prepared(true).
+			Where(selectEntryUniqueExpression).
+			Set(updateEntry).
+			ToSQL()
+		if err != nil {
+			return err
+		}
+
+		if !isKeySetterEntry {
+			result, err := txORM.GetDBWrapper().ExecContext(txCtx, updateSQLStatement, updateParams...)
+			if err != nil {
+				return err
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			if rowsAffected == 0 {
+				return ErrUpdateNotApplied
+			}
+
+			orm.entryInfoProvider.SetEntry(entry, updateEntry)
+
+			return nil
+		}
+
+		// goqu does not support RETURNING for SQLite, although it is available since SQLite 3.35
+		updateSQLStatement += fmt.Sprintf(" RETURNING `%s`", keyColumn)
+
+		rows, err := txORM.GetDBWrapper().QueryContext(txCtx, updateSQLStatement, updateParams...)
+		if err != nil {
+			return err
+		}
+
+		defer rows.Close()
+
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			return ErrUpdateNotApplied
+		}
+
+		_, updateKeyDestination, _ := orm.entryInfoProvider.GetKeyDestination(updateEntry)
+
+		if err := rows.Scan(updateKeyDestination); err != nil {
+			return err
 		}
 
-		return txORM.Update(ctx, entry)
+		orm.entryInfoProvider.SetEntry(entry, updateEntry)
+
+		return nil
+	})
+}
+
+func (orm *SQLite3ORM) Delete(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	return withDeleteHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		if softDeleteColumn, ok := orm.entryInfoProvider.GetSoftDeleteColumn(entry); ok {
+			return softDelete(ctx, hookORM, orm.entryInfoProvider, entry, softDeleteColumn, orm.unscoped)
+		}
+
+		return hookORM.(*SQLite3ORM).hardDelete(ctx, entry)
+	})
+}
+
+func (orm *SQLite3ORM) HardDelete(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	return withDeleteHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*SQLite3ORM).hardDelete(ctx, entry)
 	})
 }
 
-func (orm *SQLite3ORM) Delete(ctx context.Context, entry interface{}) error {
+func (orm *SQLite3ORM) hardDelete(ctx context.Context, entry interface{}) error {
 	if entry == nil {
 		return ErrNilEntry
 	}
@@ -120,8 +409,11 @@ func (orm *SQLite3ORM) Delete(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
+	ctx = orm.redactor.bindExpression(ctx, entryTableName, selectEntryUniqueExpression)
+
 	result, err := orm.GetDBWrapper().
 		Delete(entryTableName).
+		Prepared(true).
 		Where(selectEntryUniqueExpression).
 		Executor().
 		ExecContext(ctx)
@@ -141,7 +433,9 @@ func (orm *SQLite3ORM) Delete(ctx context.Context, entry interface{}) error {
 	return nil
 }
 
-func (orm *SQLite3ORM) Get(ctx context.Context, entry interface{}) error {
+func (orm *SQLite3ORM) Get(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
 	if entry == nil {
 		return ErrNilEntry
 	}
@@ -151,15 +445,18 @@ func (orm *SQLite3ORM) Get(ctx context.Context, entry interface{}) error {
 		return err
 	}
 
-	selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
+	selectEntryExpression, err := orm.entryInfoProvider.GetEntryScopedSelectExpression(entry, orm.unscoped)
 	if err != nil {
 		return err
 	}
 
-	found, err := orm.GetDBWrapper().
+	ctx = orm.redactor.bindExpression(ctx, entryTableName, selectEntryExpression)
+
+	found, err := getReadDB(ctx, orm.db, orm.replicas).
 		Select().
 		From(entryTableName).
-		Where(selectEntryUniqueExpression).
+		Prepared(true).
+		Where(selectEntryExpression).
 		Limit(1).
 		ScanStructContext(ctx, entry)
 	if err != nil {
@@ -170,16 +467,31 @@ func (orm *SQLite3ORM) Get(ctx context.Context, entry interface{}) error {
 		return ErrNotFound
 	}
 
-	return nil
+	return orm.afterLoad(ctx, entry)
 }
 
-func (orm *SQLite3ORM) GetWithXLock(ctx context.Context, entry interface{}) error {
-	// SQLite actually does not support row locking, so we just do a regular Get()
-	return orm.Get(ctx, entry)
+func (orm *SQLite3ORM) GetWithXLock(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	// SQLite actually does not support row locking, so we just do a regular Get(), on the primary database
+	return orm.Get(WithPrimary(ctx), entry)
 }
 
-func (orm *SQLite3ORM) getQuerySelectDataset(params QueryParams) *goqu.SelectDataset {
-	selectDataset := orm.GetDBWrapper().Select().From(params.TableName).Where(params.Expression).Order(params.OrderBy...)
+func (orm *SQLite3ORM) getQuerySelectDataset(db DBWrapper, params QueryParams) *goqu.SelectDataset {
+	queryExpression := getQueryScopedExpression(orm.entryInfoProvider, params, orm.unscoped)
+	selectDataset := db.Select().From(params.TableName).Prepared(true).Where(queryExpression).Order(params.OrderBy...)
+
+	if len(params.Columns) > 0 {
+		selectDataset = selectDataset.Select(params.Columns...)
+	}
+
+	if len(params.GroupBy) > 0 {
+		selectDataset = selectDataset.GroupBy(params.GroupBy...)
+	}
+
+	if params.Having != nil {
+		selectDataset = selectDataset.Having(params.Having)
+	}
 
 	if params.Offset != nil {
 		selectDataset = selectDataset.Offset(uint(*params.Offset))
@@ -196,17 +508,64 @@ func (orm *SQLite3ORM) getQuerySelectDataset(params QueryParams) *goqu.SelectDat
 	return selectDataset
 }
 
-func (orm *SQLite3ORM) Query(ctx context.Context, params QueryParams) error {
-	return orm.getQuerySelectDataset(params).ScanStructsContext(ctx, params.EntryList)
+func (orm *SQLite3ORM) Query(ctx context.Context, params QueryParams) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+
+	selectDataset := orm.getQuerySelectDataset(getReadDB(ctx, orm.db, orm.replicas), params)
+	if err := selectDataset.ScanStructsContext(ctx, params.EntryList); err != nil {
+		return err
+	}
+
+	return orm.afterLoad(ctx, params.EntryList)
+}
+
+func (orm *SQLite3ORM) QueryWithXLock(ctx context.Context, params QueryParams) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	// SQLite actually does not support row locking, so we just do a regular Query(), on the primary database
+	return orm.Query(WithPrimary(ctx), params)
+}
+
+func (orm *SQLite3ORM) Iterate(ctx context.Context, params QueryParams, iterateFunc IterateFunc) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	ctx = orm.redactor.bindQueryParams(ctx, params)
+	readDB := getReadDB(ctx, orm.db, orm.replicas)
+
+	return iterate(ctx, orm, readDB, orm.entryInfoProvider, params, orm.getQuerySelectDataset(readDB, params), nil, iterateFunc)
 }
 
-func (orm *SQLite3ORM) QueryWithXLock(ctx context.Context, params QueryParams) error {
-	// SQLite actually does not support row locking, so we just do a regular Query()
-	return orm.Query(ctx, params)
+func (orm *SQLite3ORM) IterateWithXLock(ctx context.Context, params QueryParams, iterateFunc IterateFunc) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	// SQLite actually does not support row locking, so we just do a regular Iterate(), on the primary database
+	return orm.Iterate(WithPrimary(ctx), params, iterateFunc)
 }
 
-func (orm *SQLite3ORM) Count(ctx context.Context, tableName string, expression exp.Expression) (int64, error) {
-	count, err := orm.GetDBWrapper().Select().From(tableName).Where(expression).CountContext(ctx)
+func (orm *SQLite3ORM) QueryPage(ctx context.Context, params QueryParams, cursor Cursor) (nextCursor Cursor, err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	return queryPage(ctx, orm.entryInfoProvider, params, cursor, true, orm.Query)
+}
+
+func (orm *SQLite3ORM) Count(ctx context.Context, tableName string, expression exp.Expression) (count int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	countExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, countExpression)
+
+	count, err = getReadDB(ctx, orm.db, orm.replicas).
+		Select().
+		From(tableName).
+		Prepared(true).
+		Where(countExpression).
+		CountContext(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -214,28 +573,180 @@ func (orm *SQLite3ORM) Count(ctx context.Context, tableName string, expression e
 	return count, nil
 }
 
-func (orm *SQLite3ORM) Update(ctx context.Context, entry interface{}) error {
+func (orm *SQLite3ORM) Sum(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	// The sum of no rows is 0 rather than NULL
+	sumExpression := goqu.COALESCE(goqu.SUM(goqu.C(column)), 0)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, sumExpression, scopedExpression, result)
+}
+
+func (orm *SQLite3ORM) Min(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, goqu.MIN(goqu.C(column)), scopedExpression, result)
+}
+
+func (orm *SQLite3ORM) Max(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, goqu.MAX(goqu.C(column)), scopedExpression, result)
+}
+
+func (orm *SQLite3ORM) Avg(
+	ctx context.Context,
+	tableName string,
+	column string,
+	expression exp.Expression,
+	result interface{},
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return aggregate(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, goqu.AVG(goqu.C(column)), scopedExpression, result)
+}
+
+func (orm *SQLite3ORM) Exists(ctx context.Context, tableName string, expression exp.Expression) (found bool, err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	scopedExpression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return false, err
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, scopedExpression)
+
+	return exists(ctx, getReadDB(ctx, orm.db, orm.replicas), tableName, scopedExpression)
+}
+
+func (orm *SQLite3ORM) VerifySchema(ctx context.Context, models ...interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	return verifySchema(ctx, orm.db, orm.entryInfoProvider, DriverTypeSQLite3, models)
+}
+
+func (orm *SQLite3ORM) Update(ctx context.Context, entry interface{}) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	return withUpdateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*SQLite3ORM).update(ctx, entry, nil)
+	})
+}
+
+func (orm *SQLite3ORM) UpdateColumns(ctx context.Context, entry interface{}, columns ...string) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	if len(columns) == 0 {
+		return ErrColumnsExpected
+	}
+
+	return withUpdateHooks(ctx, orm, entry, func(ctx context.Context, hookORM ORM) error {
+		return hookORM.(*SQLite3ORM).update(ctx, entry, columns)
+	})
+}
+
+// update updates all columns of entry, or only columns and the columns changed by OnUpdate() if columns is not nil
+func (orm *SQLite3ORM) update(ctx context.Context, entry interface{}, columns []string) error {
 	if entry == nil {
 		return ErrNilEntry
 	}
 
-	orm.entryInfoProvider.OnUpdateIfEntryIsOnCreator(entry)
+	var updateRecord exp.Record
+
+	if columns == nil {
+		orm.entryInfoProvider.OnUpdateIfEntryIsOnCreator(entry)
+	} else {
+		var err error
+		if updateRecord, err = orm.entryInfoProvider.OnUpdateColumns(entry, columns); err != nil {
+			return err
+		}
+	}
 
 	entryTableName, err := orm.entryInfoProvider.GetEntryTableName(entry)
 	if err != nil {
 		return err
 	}
 
+	ctx = orm.redactor.bindEntries(ctx, entryTableName, entry)
+
 	selectEntryUniqueExpression, err := orm.entryInfoProvider.GetEntrySelectExpression(entry)
 	if err != nil {
 		return err
 	}
 
+	var (
+		updateExpression exp.Expression = selectEntryUniqueExpression
+		updateSource     interface{}    = entry
+	)
+
+	if updateRecord != nil {
+		updateSource = updateRecord
+	}
+
+	versionColumn, version, isVersioned := orm.entryInfoProvider.GetVersion(entry)
+	if isVersioned {
+		if updateRecord == nil {
+			if updateRecord, err = orm.entryInfoProvider.GetUpdateRecord(entry); err != nil {
+				return err
+			}
+		}
+
+		updateRecord[versionColumn] = version + 1
+		updateExpression = goqu.And(selectEntryUniqueExpression, goqu.C(versionColumn).Eq(version))
+		updateSource = updateRecord
+	}
+
 	result, err := orm.GetDBWrapper().
 		Update(entryTableName).
 		Prepared(true).
-		Where(selectEntryUniqueExpression).
-		Set(entry).
+		Where(updateExpression).
+		Set(updateSource).
 		Executor().
 		ExecContext(ctx)
 	if err != nil {
@@ -248,43 +759,231 @@ func (orm *SQLite3ORM) Update(ctx context.Context, entry interface{}) error {
 	}
 
 	if rowsAffected == 0 {
+		if isVersioned {
+			return ErrStaleEntry
+		}
+
 		return ErrUpdateNotApplied
 	}
 
+	if isVersioned {
+		orm.entryInfoProvider.SetVersion(entry, version+1)
+	}
+
 	return nil
 }
 
+func (orm *SQLite3ORM) UpdateWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	return orm.updateWhere(ctx, tableName, expression, record, nil)
+}
+
+func (orm *SQLite3ORM) UpdateWhereWithLimit(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+	limit uint32,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	if isEmptyLimit(limit) {
+		return 0, nil
+	}
+
+	return orm.updateWhere(ctx, tableName, expression, record, &limit)
+}
+
+// getLimitedWhereExpression returns the expression matching at most limit rows of the table.
+// SQLite only supports LIMIT in UPDATE and DELETE statements when compiled with
+// SQLITE_ENABLE_UPDATE_DELETE_LIMIT, so the rows are selected by their rowid in a sub-query instead
+func (orm *SQLite3ORM) getLimitedWhereExpression(
+	tableName string,
+	expression exp.Expression,
+	limit *uint32,
+) exp.Expression {
+	if limit == nil {
+		return expression
+	}
+
+	// The sub-query is passed as a literal, since goqu wraps the operand of IN with an extra pair of parentheses,
+	// which would turn it into a scalar sub-query
+	return goqu.L(
+		"? IN ?",
+		goqu.C("rowid"),
+		orm.db.Select(goqu.C("rowid")).From(tableName).Where(expression).Limit(uint(*limit)),
+	)
+}
+
+func (orm *SQLite3ORM) updateWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	record goqu.Record,
+	limit *uint32,
+) (int64, error) {
+	expression, err := getTableScopedExpression(orm.entryInfoProvider, tableName, expression, orm.unscoped)
+	if err != nil {
+		return 0, err
+	}
+	ctx = orm.redactor.bindRecord(ctx, tableName, record)
+	ctx = orm.redactor.bindExpression(ctx, tableName, expression)
+
+	result, err := orm.db.
+		Update(tableName).
+		Prepared(true).
+		Where(orm.getLimitedWhereExpression(tableName, expression, limit)).
+		Set(record).
+		Executor().
+		ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (orm *SQLite3ORM) DeleteWhere(ctx context.Context, tableName string, expression exp.Expression) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	return orm.deleteWhere(ctx, tableName, expression, nil)
+}
+
+func (orm *SQLite3ORM) DeleteWhereWithLimit(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	limit uint32,
+) (rowsAffected int64, err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	if isEmptyLimit(limit) {
+		return 0, nil
+	}
+
+	return orm.deleteWhere(ctx, tableName, expression, &limit)
+}
+
+func (orm *SQLite3ORM) deleteWhere(
+	ctx context.Context,
+	tableName string,
+	expression exp.Expression,
+	limit *uint32,
+) (int64, error) {
+	// The rows of soft deleted tables are soft deleted, like by Delete()
+	softDeleteRecord, ok, err := getTableSoftDeleteRecord(orm.entryInfoProvider, tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	if ok {
+		return orm.updateWhere(ctx, tableName, expression, softDeleteRecord, limit)
+	}
+
+	ctx = orm.redactor.bindExpression(ctx, tableName, expression)
+
+	result, err := orm.db.
+		Delete(tableName).
+		Prepared(true).
+		Where(orm.getLimitedWhereExpression(tableName, expression, limit)).
+		Executor().
+		ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
 func (orm *SQLite3ORM) GetDBWrapper() DBWrapper {
 	return orm.db
 }
 
-//nolint:gosec // Random sleep time does not need to be too secure
-func (orm *SQLite3ORM) getRandomTxRetrySleepTime() time.Duration {
-	sleepTimeInMillisecond := orm.databaseConfig.SQLite3TransactionRetryDelayInMillisecond -
-		orm.databaseConfig.SQLite3TransactionRetryJitterInMillisecond +
-		rand.Intn(orm.databaseConfig.SQLite3TransactionRetryJitterInMillisecond*2+1)
+func (orm *SQLite3ORM) Ping(ctx context.Context) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
 
-	return time.Duration(sleepTimeInMillisecond) * time.Millisecond
+	return pingDatabases(ctx, orm.pool, orm.replicas)
 }
 
-func (orm *SQLite3ORM) withTxRetry(nonTXDB *goqu.Database, executeFunc func(ORM) error) (err error) {
-	for i := uint(0); i < orm.databaseConfig.SQLite3TransactionMaxRetry; i++ {
-		err = nonTXDB.WithTx(func(td *goqu.TxDatabase) error {
-			return executeFunc(&SQLite3ORM{
-				db:                td,
-				entryInfoProvider: orm.entryInfoProvider,
-				databaseConfig:    orm.databaseConfig,
-			})
-		})
+func (orm *SQLite3ORM) HealthCheck(ctx context.Context) (status HealthStatus, err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
 
-		if err == nil || errors.Is(err, ErrNilEntry) || errors.Is(err, ErrNotFound) || errors.Is(err, ErrUpdateNotApplied) {
-			return err
-		}
+	return checkHealth(ctx, DriverTypeSQLite3, orm.pool, orm.replicas)
+}
+
+func (orm *SQLite3ORM) Close() (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	if _, ok := orm.db.(*goqu.Database); !ok {
+		return ErrCloseInTransaction
+	}
+
+	return closeDatabases(orm.pool, orm.ownsPool, orm.replicas)
+}
+
+func (orm *SQLite3ORM) Unscoped() ORM {
+	unscopedORM := *orm
+	unscopedORM.unscoped = true
+
+	return &unscopedORM
+}
+
+func (orm *SQLite3ORM) Preload(relationFields ...string) ORM {
+	preloadORM := *orm
+	preloadORM.preloads = append(append([]string{}, orm.preloads...), relationFields...)
+
+	return &preloadORM
+}
+
+// afterLoad preloads the relations of entries, a pointer to an entry or to a slice of entries, and runs their
+// AfterLoad() hooks
+func (orm *SQLite3ORM) afterLoad(ctx context.Context, entries interface{}) error {
+	if err := orm.preload(ctx, entries); err != nil {
+		return err
+	}
+
+	return runAfterLoadHooks(ctx, orm, entries)
+}
+
+// preload loads the relations passed to Preload() into entries, a pointer to an entry or to a slice of entries
+func (orm *SQLite3ORM) preload(ctx context.Context, entries interface{}) error {
+	if len(orm.preloads) == 0 {
+		return nil
+	}
+
+	queryORM := *orm
+	queryORM.preloads = nil
+
+	return preloadRelations(ctx, &queryORM, orm.entryInfoProvider, orm.databaseConfig, entries, orm.preloads)
+}
 
-		time.Sleep(orm.getRandomTxRetrySleepTime())
+func (orm *SQLite3ORM) newTxORM(td *goqu.TxDatabase) *SQLite3ORM {
+	return &SQLite3ORM{
+		db:                td,
+		pool:              orm.pool,
+		replicas:          orm.replicas,
+		entryInfoProvider: orm.entryInfoProvider,
+		redactor:          orm.redactor,
+		databaseConfig:    orm.databaseConfig,
+		unscoped:          orm.unscoped,
 	}
+}
 
-	return err
+// getTxRetryPolicy returns the configured RetryPolicy, or the retry policy of SQLite3TransactionModeRetry if none is
+// configured
+func (orm *SQLite3ORM) getTxRetryPolicy() RetryPolicy {
+	if orm.databaseConfig.RetryPolicy != nil ||
+		orm.databaseConfig.SQLite3TransactionMode != SQLite3TransactionModeRetry {
+		return orm.databaseConfig.RetryPolicy
+	}
+
+	return &sqlite3TransactionRetryPolicy{databaseConfig: orm.databaseConfig}
 }
 
 // HACK: Due to the nature of Golang's sql.DB, we cannot properly ensure that we only have one database
@@ -292,27 +991,57 @@ func (orm *SQLite3ORM) withTxRetry(nonTXDB *goqu.Database, executeFunc func(ORM)
 // prevent the "database is locked" error during transactions.
 //
 // Refer to https://github.com/mattn/go-sqlite3/issues/274#issuecomment-192131441.
-func (orm *SQLite3ORM) withTxMutex(nonTXDB *goqu.Database, executeFunc func(ORM) error) error {
-	sqlite3ORMTxLock.Lock()
-	defer sqlite3ORMTxLock.Unlock()
-
-	return nonTXDB.WithTx(func(td *goqu.TxDatabase) error {
-		return executeFunc(&SQLite3ORM{
-			db:                td,
-			entryInfoProvider: orm.entryInfoProvider,
-			databaseConfig:    orm.databaseConfig,
-		})
+func (orm *SQLite3ORM) withTxMutex(
+	ctx context.Context,
+	nonTXDB *goqu.Database,
+	opts *sql.TxOptions,
+	executeFunc func(context.Context, ORM) error,
+) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case sqlite3ORMTxLock <- struct{}{}:
+	}
+
+	defer func() { <-sqlite3ORMTxLock }()
+
+	return withGoquTx(ctx, nonTXDB, opts, func(td *goqu.TxDatabase) error {
+		return executeFunc(ctx, orm.newTxORM(td))
+	})
+}
+
+func (orm *SQLite3ORM) WithTx(executeFunc func(ORM) error) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
+	return orm.WithTxContext(context.Background(), nil, func(_ context.Context, txORM ORM) error {
+		return executeFunc(txORM)
 	})
 }
 
-func (orm *SQLite3ORM) WithTx(executeFunc func(ORM) error) error {
+func (orm *SQLite3ORM) WithTxContext(
+	ctx context.Context,
+	opts *sql.TxOptions,
+	executeFunc func(context.Context, ORM) error,
+) (err error) {
+	defer wrapReturnedDriverError(DriverTypeSQLite3, &err)
+
 	if nonTXDB, ok := orm.db.(*goqu.Database); ok {
-		if orm.databaseConfig.SQLite3TransactionMode == SQLite3TransactionModeRetry {
-			return orm.withTxRetry(nonTXDB, executeFunc)
-		}
+		return withRetry(ctx, orm.getTxRetryPolicy(), DriverTypeSQLite3, func() error {
+			if orm.databaseConfig.SQLite3TransactionMode == SQLite3TransactionModeRetry {
+				return withGoquTx(ctx, nonTXDB, opts, func(td *goqu.TxDatabase) error {
+					return executeFunc(ctx, orm.newTxORM(td))
+				})
+			}
 
-		return orm.withTxMutex(nonTXDB, executeFunc)
+			return orm.withTxMutex(ctx, nonTXDB, opts, executeFunc)
+		})
 	}
 
-	return executeFunc(orm)
+	savepointORM := *orm
+	savepointORM.savepointDepth++
+	savepointORM.preloads = nil
+
+	return withSavepoint(ctx, orm.db, DriverTypeSQLite3, savepointORM.savepointDepth, func() error {
+		return executeFunc(ctx, &savepointORM)
+	})
 }
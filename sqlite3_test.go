@@ -1,9 +1,14 @@
 package miniorm
 
 import (
+	"context"
+	"database/sql"
 	"log"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/doug-martin/goqu/v9"
 	"github.com/go-testfixtures/testfixtures/v3"
 	_ "github.com/mattn/go-sqlite3" // For SQLite driver
 	"github.com/stretchr/testify/assert"
@@ -29,11 +34,18 @@ var (
 )
 
 func prepareSQLite3TestEntryTable(fixtureFile string) error {
-	db, err := newSQLDatabase(sqlite3TestConfigMutex)
+	return prepareSQLite3TestDatabase(sqlite3TestConfigMutex, fixtureFile)
+}
+
+// prepareSQLite3TestDatabase creates the test tables in the database of databaseConfig and loads fixtureFile into them
+func prepareSQLite3TestDatabase(databaseConfig DatabaseConfig, fixtureFile string) error {
+	db, err := NewSQLDatabase(databaseConfig)
 	if err != nil {
 		return err
 	}
 
+	defer db.Close()
+
 	if _, err = db.Exec(`
 		BEGIN TRANSACTION;
 
@@ -43,7 +55,9 @@ func prepareSQLite3TestEntryTable(fixtureFile string) error {
 			string_col TEXT NOT NULL,
 			bytes_col BYTEA NOT NULL,
 			on_create_count INTEGER NOT NULL,
-			on_update_count INTEGER NOT NULL
+			on_update_count INTEGER NOT NULL,
+			version INTEGER NOT NULL DEFAULT 0,
+			deleted_at INTEGER NULL
 		);
 
 		DROP TABLE IF EXISTS get_unique_entries;
@@ -54,9 +68,24 @@ func prepareSQLite3TestEntryTable(fixtureFile string) error {
 			bytes_col BYTEA NOT NULL,
 			on_create_count INTEGER NOT NULL,
 			on_update_count INTEGER NOT NULL,
+			version INTEGER NOT NULL DEFAULT 0,
+			deleted_at INTEGER NULL,
 			PRIMARY KEY (id_1, id_2)
 		);
 
+		DROP TABLE IF EXISTS get_key_entries;
+		CREATE TABLE get_key_entries (
+			id TEXT PRIMARY KEY,
+			string_col TEXT NOT NULL
+		);
+
+		DROP TABLE IF EXISTS get_child_entries;
+		CREATE TABLE get_child_entries (
+			id INTEGER PRIMARY KEY,
+			parent_id INTEGER NOT NULL,
+			string_col TEXT NOT NULL
+		);
+
 		COMMIT;
 	`); err != nil {
 		return err
@@ -182,110 +211,1056 @@ func TestSQLite3WithTXRetry(t *testing.T) {
 	testWithTX(t, orm)
 }
 
-func TestSQLite3CreateMutex(t *testing.T) {
-	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create.yml")
+func TestSQLite3WithTxContextRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
 	assert.Nil(t, err)
 
-	orm, err := NewORM(sqlite3TestConfigMutex)
+	orm, err := NewORM(sqlite3TestConfigRetry)
 	assert.Nil(t, err)
 
-	testCreate(t, orm, 0)
+	testWithTxContext(t, orm)
 }
 
-func TestSQLite3GetMutex(t *testing.T) {
+func TestSQLite3NestedWithTxRetry(t *testing.T) {
 	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
 	assert.Nil(t, err)
 
-	orm, err := NewORM(sqlite3TestConfigMutex)
+	orm, err := NewORM(sqlite3TestConfigRetry)
 	assert.Nil(t, err)
 
-	testGet(t, orm)
+	testNestedWithTx(t, orm)
 }
 
-func TestSQLite3GetWithXLockMutex(t *testing.T) {
+func TestSQLite3CreateOrUpdateUpsertRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigRetry
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	// SQLite's auto increment ID takes the largest existing value + 1, so we set this value to be 100 to match the test data
+	testCreateOrUpdateUpsert(t, orm, 100)
+}
+
+func TestSQLite3CreateManyRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigRetry
+	databaseConfig.CreateManyChunkSize = 2
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testCreateMany(t, orm, 0)
+}
+
+func TestSQLite3UpdateWhereRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testUpdateWhere(t, orm)
+}
+
+func TestSQLite3DeleteWhereRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testDeleteWhere(t, orm)
+}
+
+func TestSQLite3OptimisticLockingRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testOptimisticLocking(t, orm)
+}
+
+func TestSQLite3OptimisticLockingUpsertRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigRetry
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testOptimisticLocking(t, orm)
+}
+
+func TestSQLite3SoftDeleteRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigRetry
+	databaseConfig.Models = []interface{}{&softDeleteIDEntry{}}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testSoftDelete(t, orm)
+}
+
+func TestSQLite3UnregisteredSoftDeleteRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testUnregisteredSoftDelete(t, orm)
+}
+
+func TestSQLite3TaggedModelRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testTaggedModel(t, orm, 0)
+}
+
+func TestSQLite3TaggedModelUpsertRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigRetry
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testTaggedModel(t, orm, 0)
+}
+
+func TestSQLite3KeysRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testKeys(t, orm)
+}
+
+func TestSQLite3KeysUpsertRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigRetry
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testKeys(t, orm)
+}
+
+func TestSQLite3GeneratedKeysRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testGeneratedKeysNotSupported(t, orm)
+}
+
+func TestSQLite3WithTxRetryPolicyRetry(t *testing.T) {
 	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
 	assert.Nil(t, err)
 
-	orm, err := NewORM(sqlite3TestConfigMutex)
+	databaseConfig := sqlite3TestConfigRetry
+	databaseConfig.RetryPolicy = NewExponentialBackoffRetryPolicy()
+
+	orm, err := NewORM(databaseConfig)
 	assert.Nil(t, err)
 
-	testGetWithXLock(t, orm)
+	testWithTX(t, orm)
 }
 
-func TestSQLite3QueryMutex(t *testing.T) {
+func TestSQLite3IterateRetry(t *testing.T) {
 	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
 	assert.Nil(t, err)
 
-	orm, err := NewORM(sqlite3TestConfigMutex)
+	orm, err := NewORM(sqlite3TestConfigRetry)
 	assert.Nil(t, err)
 
-	testQuery(t, orm)
+	testIterate(t, orm)
 }
 
-func TestSQLite3QueryWithXLockMutex(t *testing.T) {
+func TestSQLite3IterateWithXLockRetry(t *testing.T) {
 	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
 	assert.Nil(t, err)
 
-	orm, err := NewORM(sqlite3TestConfigMutex)
+	orm, err := NewORM(sqlite3TestConfigRetry)
 	assert.Nil(t, err)
 
-	testQueryWithXLock(t, orm)
+	testIterateWithXLock(t, orm)
 }
 
-func TestSQLite3CountMutex(t *testing.T) {
+func TestSQLite3QueryPageRetry(t *testing.T) {
 	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
 	assert.Nil(t, err)
 
-	orm, err := NewORM(sqlite3TestConfigMutex)
+	orm, err := NewORM(sqlite3TestConfigRetry)
 	assert.Nil(t, err)
 
-	testCount(t, orm)
+	testQueryPage(t, orm)
 }
 
-func TestSQLite3CreateOrUpdateMutex(t *testing.T) {
+func TestSQLite3QueryColumnsRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testQueryColumns(t, orm)
+}
+
+func TestSQLite3UpdateColumnsRetry(t *testing.T) {
 	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create_or_update.yml")
 	assert.Nil(t, err)
 
-	orm, err := NewORM(sqlite3TestConfigMutex)
+	orm, err := NewORM(sqlite3TestConfigRetry)
 	assert.Nil(t, err)
 
-	// SQLite's auto increment ID takes the largest existing value + 1, so we set this value to be 100 to match the test data
-	testCreateOrUpdate(t, orm, 100)
+	testUpdateColumns(t, orm)
 }
 
-func TestSQLite3UpdateMutex(t *testing.T) {
-	err := prepareSQLite3TestEntryTable("testing/fixtures/test_update.yml")
+func TestSQLite3AggregateRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
 	assert.Nil(t, err)
 
-	orm, err := NewORM(sqlite3TestConfigMutex)
+	orm, err := NewORM(sqlite3TestConfigRetry)
 	assert.Nil(t, err)
 
-	testUpdate(t, orm)
+	testAggregate(t, orm)
 }
 
-func TestSQLite3DeleteMutex(t *testing.T) {
-	err := prepareSQLite3TestEntryTable("testing/fixtures/test_delete.yml")
+func TestSQLite3QueryGroupByRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testQueryGroupBy(t, orm)
+}
+
+func TestSQLite3PreloadRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_preload.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigRetry
+	databaseConfig.PreloadChunkSize = 2
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testPreload(t, orm)
+}
+
+func TestSQLite3GenerateCreateTableStatementRetry(t *testing.T) {
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testGenerateCreateTableStatement(t, orm, DriverTypeSQLite3)
+}
+
+func TestSQLite3VerifySchemaRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testVerifySchema(t, orm, DriverTypeSQLite3)
+}
+
+func TestSQLite3HooksRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_hooks.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testHooks(t, orm)
+}
+
+func TestSQLite3InstrumentationRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigRetry
+	databaseConfig.Instrumentation = &recordingInstrumentation{}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testInstrumentation(t, orm)
+}
+
+func TestSQLite3StructuredLoggingRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigRetry
+	databaseConfig.StructuredLogger = &recordingStructuredLogger{}
+	databaseConfig.RedactedColumns = []string{"on_update_count"}
+	databaseConfig.Models = []interface{}{&sensitiveEntry{}}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testStructuredLogging(t, orm)
+}
+
+func TestSQLite3ReplicasRetry(t *testing.T) {
+	testSQLite3Replicas(t, sqlite3TestConfigRetry)
+}
+
+func TestSQLite3HealthRetry(t *testing.T) {
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testHealth(t, orm, DriverTypeSQLite3)
+}
+
+func TestSQLite3FromSQLDatabaseRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	testFromSQLDatabase(t, sqlite3TestConfigRetry)
+}
+
+func TestSQLite3WhereWithZeroLimitRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testWhereWithZeroLimit(t, orm)
+}
+
+func TestSQLite3AggregateEmptyTableRetry(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigRetry)
+	assert.Nil(t, err)
+
+	testAggregateEmptyTable(t, orm)
+}
+
+func TestSQLite3CreateMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create.yml")
 	assert.Nil(t, err)
 
 	orm, err := NewORM(sqlite3TestConfigMutex)
 	assert.Nil(t, err)
 
-	testDelete(t, orm)
+	testCreate(t, orm, 0)
 }
 
-func TestSQLite3GetDBWrapperMutex(t *testing.T) {
+func TestSQLite3GetMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
 	orm, err := NewORM(sqlite3TestConfigMutex)
 	assert.Nil(t, err)
 
-	testGetDBWrapper(t, orm)
+	testGet(t, orm)
 }
 
-func TestSQLite3WithTXMutex(t *testing.T) {
+func TestSQLite3GetWithXLockMutex(t *testing.T) {
 	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
 	assert.Nil(t, err)
 
 	orm, err := NewORM(sqlite3TestConfigMutex)
 	assert.Nil(t, err)
 
-	testWithTX(t, orm)
+	testGetWithXLock(t, orm)
+}
+
+func TestSQLite3QueryMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testQuery(t, orm)
+}
+
+func TestSQLite3QueryWithXLockMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testQueryWithXLock(t, orm)
+}
+
+func TestSQLite3CountMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testCount(t, orm)
+}
+
+func TestSQLite3CreateOrUpdateMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	// SQLite's auto increment ID takes the largest existing value + 1, so we set this value to be 100 to match the test data
+	testCreateOrUpdate(t, orm, 100)
+}
+
+func TestSQLite3UpdateMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testUpdate(t, orm)
+}
+
+func TestSQLite3DeleteMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_delete.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testDelete(t, orm)
+}
+
+func TestSQLite3GetDBWrapperMutex(t *testing.T) {
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testGetDBWrapper(t, orm)
+}
+
+func TestSQLite3WithTXMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testWithTX(t, orm)
+}
+
+func TestSQLite3WithTxContextMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testWithTxContext(t, orm)
+}
+
+func TestSQLite3WithTxContextMutexWaitCancelled(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	lockAcquired := make(chan struct{})
+	releaseLock := make(chan struct{})
+	txDone := make(chan error)
+
+	go func() {
+		txDone <- orm.WithTx(func(o ORM) error {
+			close(lockAcquired)
+			<-releaseLock
+			return nil
+		})
+	}()
+
+	<-lockAcquired
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = orm.WithTxContext(ctx, nil, func(context.Context, ORM) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(releaseLock)
+	assert.Nil(t, <-txDone)
+}
+
+func TestSQLite3CreateOrUpdateMutexWaitCancelled(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	lockAcquired := make(chan struct{})
+	releaseLock := make(chan struct{})
+	txDone := make(chan error)
+
+	go func() {
+		txDone <- orm.WithTx(func(o ORM) error {
+			close(lockAcquired)
+			<-releaseLock
+			return nil
+		})
+	}()
+
+	<-lockAcquired
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = orm.CreateOrUpdate(ctx, &getIDEntry{ID: 100, StringCol: "value 1"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(releaseLock)
+	assert.Nil(t, <-txDone)
+}
+
+func TestSQLite3NestedWithTxMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testNestedWithTx(t, orm)
+}
+
+func TestSQLite3CreateOrUpdateUpsertMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigMutex
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	// SQLite's auto increment ID takes the largest existing value + 1, so we set this value to be 100 to match the test data
+	testCreateOrUpdateUpsert(t, orm, 100)
+}
+
+func TestSQLite3CreateOrUpdateUpsertKeyMutex(t *testing.T) {
+	databaseConfig := sqlite3TestConfigMutex
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	_, err = orm.GetDBWrapper().Exec(`
+		DROP TABLE IF EXISTS unique_key_entries;
+		CREATE TABLE unique_key_entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE,
+			string_col TEXT NOT NULL
+		);
+	`)
+	assert.Nil(t, err)
+
+	entry := &uniqueKeyEntry{Name: "name 1", StringCol: "value 1"}
+	err = orm.CreateOrUpdate(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), entry.ID)
+
+	err = orm.Create(context.Background(), &uniqueKeyEntry{Name: "name 2", StringCol: "value 2"})
+	assert.Nil(t, err)
+
+	entry = &uniqueKeyEntry{Name: "name 1", StringCol: "updated value 1"}
+	err = orm.CreateOrUpdate(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, &uniqueKeyEntry{ID: 1, Name: "name 1", StringCol: "updated value 1"}, entry)
+}
+
+func TestSQLite3CreateManyMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigMutex
+	databaseConfig.CreateManyChunkSize = 2
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testCreateMany(t, orm, 0)
+}
+
+func TestSQLite3UpdateWhereMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testUpdateWhere(t, orm)
+}
+
+func TestSQLite3DeleteWhereMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testDeleteWhere(t, orm)
+}
+
+func TestSQLite3OptimisticLockingMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testOptimisticLocking(t, orm)
+}
+
+func TestSQLite3OptimisticLockingUpsertMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigMutex
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testOptimisticLocking(t, orm)
+}
+
+func TestSQLite3SoftDeleteMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigMutex
+	databaseConfig.Models = []interface{}{&softDeleteIDEntry{}}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testSoftDelete(t, orm)
+}
+
+func TestSQLite3UnregisteredSoftDeleteMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testUnregisteredSoftDelete(t, orm)
+}
+
+func TestSQLite3TaggedModelMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testTaggedModel(t, orm, 0)
+}
+
+func TestSQLite3TaggedModelUpsertMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigMutex
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testTaggedModel(t, orm, 0)
+}
+
+func TestSQLite3KeysMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testKeys(t, orm)
+}
+
+func TestSQLite3KeysUpsertMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigMutex
+	databaseConfig.CreateOrUpdateMode = CreateOrUpdateModeUpsert
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testKeys(t, orm)
+}
+
+func TestSQLite3GeneratedKeysMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_keys.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testGeneratedKeysNotSupported(t, orm)
+}
+
+func TestSQLite3WithTxRetryPolicyMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigMutex
+	databaseConfig.RetryPolicy = NewExponentialBackoffRetryPolicy()
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testWithTX(t, orm)
+}
+
+func TestSQLite3IterateMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testIterate(t, orm)
+}
+
+func TestSQLite3IterateWithXLockMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testIterateWithXLock(t, orm)
+}
+
+func TestSQLite3QueryPageMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testQueryPage(t, orm)
+}
+
+func TestSQLite3QueryColumnsMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testQueryColumns(t, orm)
+}
+
+func TestSQLite3UpdateColumnsMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create_or_update.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testUpdateColumns(t, orm)
+}
+
+func TestSQLite3AggregateMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testAggregate(t, orm)
+}
+
+func TestSQLite3QueryGroupByMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testQueryGroupBy(t, orm)
+}
+
+func TestSQLite3PreloadMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_preload.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigMutex
+	databaseConfig.PreloadChunkSize = 2
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testPreload(t, orm)
+}
+
+func TestSQLite3GenerateCreateTableStatementMutex(t *testing.T) {
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testGenerateCreateTableStatement(t, orm, DriverTypeSQLite3)
+}
+
+func TestSQLite3VerifySchemaMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testVerifySchema(t, orm, DriverTypeSQLite3)
+}
+
+func TestSQLite3HooksMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_hooks.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testHooks(t, orm)
+}
+
+func TestSQLite3InstrumentationMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigMutex
+	databaseConfig.Instrumentation = &recordingInstrumentation{}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testInstrumentation(t, orm)
+}
+
+func TestSQLite3StructuredLoggingMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig := sqlite3TestConfigMutex
+	databaseConfig.StructuredLogger = &recordingStructuredLogger{}
+	databaseConfig.RedactedColumns = []string{"on_update_count"}
+	databaseConfig.Models = []interface{}{&sensitiveEntry{}}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	testStructuredLogging(t, orm)
+}
+
+func TestSQLite3ReplicasMutex(t *testing.T) {
+	testSQLite3Replicas(t, sqlite3TestConfigMutex)
+}
+
+// testSQLite3Replicas checks the routing to two replicas, which are separate databases with different values
+func testSQLite3Replicas(t *testing.T, databaseConfig DatabaseConfig) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	databaseConfig.Replicas = nil
+
+	for i, replicaStringCol := range []string{"replica value 1", "replica value 2"} {
+		replicaConfig := ReplicaConfig{URL: "file:" + filepath.Join(t.TempDir(), "test_replica.db")}
+		databaseConfig.Replicas = append(databaseConfig.Replicas, replicaConfig)
+
+		replicaDatabaseConfig := sqlite3TestConfigMutex
+		replicaDatabaseConfig.URL = replicaConfig.URL
+
+		err = prepareSQLite3TestDatabase(replicaDatabaseConfig, "testing/fixtures/test_get.yml")
+		assert.Nil(t, err, "replica %d", i)
+
+		db, err := NewSQLDatabase(replicaDatabaseConfig)
+		assert.Nil(t, err)
+
+		_, err = db.Exec("UPDATE get_id_entries SET string_col = ?", replicaStringCol)
+		assert.Nil(t, err)
+		assert.Nil(t, db.Close())
+	}
+
+	orm, err := NewORM(databaseConfig)
+	assert.Nil(t, err)
+
+	getStringCol := func(ctx context.Context, orm ORM) string {
+		entry := &getIDEntry{ID: 1}
+		assert.Nil(t, orm.Get(ctx, entry))
+
+		return entry.StringCol
+	}
+
+	// The reads are balanced between the replicas
+	assert.Equal(t, "replica value 1", getStringCol(context.Background(), orm))
+	assert.Equal(t, "replica value 2", getStringCol(context.Background(), orm))
+	assert.Equal(t, "value 1", getStringCol(WithPrimary(context.Background()), orm))
+
+	entries := make([]getIDEntry, 0)
+	err = orm.Query(context.Background(), QueryParams{TableName: getIDEntryTableName, EntryList: &entries})
+	assert.Nil(t, err)
+	assert.Equal(t, []getIDEntry{{
+		ID:            1,
+		StringCol:     "replica value 1",
+		BytesCol:      []byte("bytes value 1"),
+		OnCreateCount: 1,
+	}}, entries)
+
+	lockedEntry := &getIDEntry{ID: 1}
+	err = orm.GetWithXLock(context.Background(), lockedEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, "value 1", lockedEntry.StringCol)
+
+	// The writes, and the reads in transactions, run on the primary
+	err = orm.Create(context.Background(), &getIDEntry{StringCol: "value 2", BytesCol: []byte("bytes value 2")})
+	assert.Nil(t, err)
+
+	count, err := orm.Count(context.Background(), getIDEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	count, err = orm.Count(WithPrimary(context.Background()), getIDEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+
+	err = orm.WithTx(func(txORM ORM) error {
+		assert.Equal(t, "value 1", getStringCol(context.Background(), txORM))
+
+		count, err := txORM.Count(context.Background(), getIDEntryTableName, goqu.Ex{})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+
+		return nil
+	})
+	assert.Nil(t, err)
+
+	status, err := orm.HealthCheck(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, status.Replicas, 2)
+
+	for _, replicaStatus := range status.Replicas {
+		assert.Nil(t, replicaStatus.Err)
+		assert.NotEmpty(t, replicaStatus.ServerVersion)
+	}
+
+	assert.Nil(t, orm.Close())
+}
+
+func TestSQLite3HealthMutex(t *testing.T) {
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testHealth(t, orm, DriverTypeSQLite3)
+}
+
+func TestSQLite3FromSQLDatabaseMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_get.yml")
+	assert.Nil(t, err)
+
+	testFromSQLDatabase(t, sqlite3TestConfigMutex)
+}
+
+func TestSQLite3FromSQLDatabaseInMemory(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	assert.Nil(t, err)
+
+	defer db.Close()
+
+	// Every connection opens its own in-memory database
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`
+		CREATE TABLE get_id_entries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			string_col TEXT NOT NULL,
+			bytes_col BYTEA NOT NULL,
+			on_create_count INTEGER NOT NULL,
+			on_update_count INTEGER NOT NULL
+		);
+	`)
+	assert.Nil(t, err)
+
+	orm, err := NewSQLite3ORMFromSQLDatabase(db, DatabaseConfig{SQLite3TransactionMode: SQLite3TransactionModeMutex})
+	assert.Nil(t, err)
+
+	entry := &getIDEntry{StringCol: "value 1", BytesCol: []byte("bytes value 1")}
+	err = orm.Create(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), entry.ID)
+
+	err = orm.WithTx(func(txORM ORM) error {
+		entry.StringCol = "value 2"
+		return txORM.Update(context.Background(), entry)
+	})
+	assert.Nil(t, err)
+
+	loadedEntry := &getIDEntry{ID: entry.ID}
+	err = orm.Get(context.Background(), loadedEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, "value 2", loadedEntry.StringCol)
+
+	status, err := orm.HealthCheck(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, DriverTypeSQLite3, status.Driver)
+	assert.Equal(t, 1, status.Stats.MaxOpenConnections)
+}
+
+func TestSQLite3WhereWithZeroLimitMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_query.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testWhereWithZeroLimit(t, orm)
+}
+
+func TestSQLite3AggregateEmptyTableMutex(t *testing.T) {
+	err := prepareSQLite3TestEntryTable("testing/fixtures/test_create.yml")
+	assert.Nil(t, err)
+
+	orm, err := NewORM(sqlite3TestConfigMutex)
+	assert.Nil(t, err)
+
+	testAggregateEmptyTable(t, orm)
 }
@@ -0,0 +1,85 @@
+package miniorm
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Attributes of the spans of NewTracingInstrumentation()
+const (
+	SpanAttributeDBSystem        = "db.system"
+	SpanAttributeDBOperation     = "db.operation"
+	SpanAttributeDBTable         = "db.sql.table"
+	SpanAttributeDBStatement     = "db.statement"
+	SpanAttributeDBArgCount      = "db.miniorm.arg_count"
+	SpanAttributeDBRowsAffected  = "db.miniorm.rows_affected"
+	SpanAttributeDBEntryNotFound = "db.miniorm.not_found"
+)
+
+var (
+	configDriverTypeToDBSystem = map[DriverType]string{
+		DriverTypeMSSQL:    "mssql",
+		DriverTypeMySQL:    "mysql",
+		DriverTypePostgres: "postgresql",
+		DriverTypeSQLite3:  "sqlite",
+	}
+)
+
+// Tracer starts the spans of NewTracingInstrumentation(), e.g. an adapted OpenTelemetry trace.Tracer
+type Tracer interface {
+	StartSpan(ctx context.Context, name string) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+type spanContextKey struct{}
+
+type tracingInstrumentation struct {
+	tracer Tracer
+}
+
+// NewTracingInstrumentation returns an Instrumentation starting a span named "<operation> <table>" per operation
+func NewTracingInstrumentation(tracer Tracer) Instrumentation {
+	return &tracingInstrumentation{tracer: tracer}
+}
+
+func (instrumentation *tracingInstrumentation) OnQueryStart(ctx context.Context, event *QueryEvent) context.Context {
+	ctx, span := instrumentation.tracer.StartSpan(ctx, strings.TrimSpace(event.Operation+" "+event.TableName))
+
+	span.SetAttribute(SpanAttributeDBSystem, configDriverTypeToDBSystem[event.Driver])
+	span.SetAttribute(SpanAttributeDBOperation, event.Operation)
+
+	if event.TableName != "" {
+		span.SetAttribute(SpanAttributeDBTable, event.TableName)
+	}
+
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+func (instrumentation *tracingInstrumentation) OnQueryEnd(ctx context.Context, event *QueryEvent) {
+	span, ok := ctx.Value(spanContextKey{}).(Span)
+	if !ok {
+		return
+	}
+
+	if event.SQL != "" {
+		span.SetAttribute(SpanAttributeDBStatement, event.SQL)
+	}
+
+	span.SetAttribute(SpanAttributeDBArgCount, event.ArgCount)
+	span.SetAttribute(SpanAttributeDBRowsAffected, event.RowsAffected)
+
+	// An entry not found is an expected outcome rather than a failure of the operation
+	if errors.Is(event.Err, ErrNotFound) {
+		span.SetAttribute(SpanAttributeDBEntryNotFound, true)
+	} else if event.Err != nil {
+		span.RecordError(event.Err)
+	}
+
+	span.End()
+}
@@ -0,0 +1,99 @@
+package miniorm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSpan struct {
+	Name       string
+	Attributes map[string]interface{}
+	Errors     []error
+	Ended      bool
+}
+
+func (span *testSpan) SetAttribute(key string, value interface{}) {
+	span.Attributes[key] = value
+}
+
+func (span *testSpan) RecordError(err error) {
+	span.Errors = append(span.Errors, err)
+}
+
+func (span *testSpan) End() {
+	span.Ended = true
+}
+
+type testTracer struct {
+	Spans []*testSpan
+}
+
+func (tracer *testTracer) StartSpan(ctx context.Context, name string) (context.Context, Span) {
+	span := &testSpan{Name: name, Attributes: make(map[string]interface{})}
+	tracer.Spans = append(tracer.Spans, span)
+
+	return ctx, span
+}
+
+func TestTracingInstrumentation(t *testing.T) {
+	tracer := &testTracer{}
+	instrumentation := NewTracingInstrumentation(tracer)
+	errFailed := errors.New("failed")
+
+	for _, event := range []*QueryEvent{
+		{
+			Driver:       DriverTypeSQLite3,
+			Operation:    OperationGet,
+			TableName:    "entry",
+			SQL:          "SELECT 1",
+			ArgCount:     1,
+			RowsAffected: 1,
+		},
+		{Driver: DriverTypePostgres, Operation: OperationGet, TableName: "entry", Err: ErrNotFound},
+		{Driver: DriverTypeMySQL, Operation: OperationWithTx, Err: errFailed},
+	} {
+		ctx := instrumentation.OnQueryStart(context.Background(), event)
+		instrumentation.OnQueryEnd(ctx, event)
+	}
+
+	assert.Equal(t, []*testSpan{
+		{
+			Name: "Get entry",
+			Attributes: map[string]interface{}{
+				SpanAttributeDBSystem:       "sqlite",
+				SpanAttributeDBOperation:    OperationGet,
+				SpanAttributeDBTable:        "entry",
+				SpanAttributeDBStatement:    "SELECT 1",
+				SpanAttributeDBArgCount:     1,
+				SpanAttributeDBRowsAffected: int64(1),
+			},
+			Ended: true,
+		},
+		{
+			Name: "Get entry",
+			Attributes: map[string]interface{}{
+				SpanAttributeDBSystem:        "postgresql",
+				SpanAttributeDBOperation:     OperationGet,
+				SpanAttributeDBTable:         "entry",
+				SpanAttributeDBArgCount:      0,
+				SpanAttributeDBRowsAffected:  int64(0),
+				SpanAttributeDBEntryNotFound: true,
+			},
+			Ended: true,
+		},
+		{
+			Name: "WithTx",
+			Attributes: map[string]interface{}{
+				SpanAttributeDBSystem:       "mysql",
+				SpanAttributeDBOperation:    OperationWithTx,
+				SpanAttributeDBArgCount:     0,
+				SpanAttributeDBRowsAffected: int64(0),
+			},
+			Errors: []error{errFailed},
+			Ended:  true,
+		},
+	}, tracer.Spans)
+}
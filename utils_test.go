@@ -2,7 +2,11 @@ package miniorm
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
 
@@ -107,7 +111,7 @@ func testCreate(t *testing.T, orm ORM, sequenceStart int64) {
 	}, getUniqueEntry1)
 
 	err = orm.Create(context.Background(), getUniqueEntry1)
-	assert.NotNil(t, err)
+	assert.ErrorIs(t, err, ErrUniqueViolation)
 }
 
 func testGet(t *testing.T, orm ORM) {
@@ -566,6 +570,250 @@ func testQueryWithXLock(t *testing.T, orm ORM) {
 	}
 }
 
+func testIterate(t *testing.T, orm ORM) {
+	errStop := errors.New("error to stop iterating")
+
+	var entryList []getIDEntryWithOnCreateAndOnUpdate
+	err := orm.Iterate(context.Background(), QueryParams{
+		TableName:  getIDEntryTableName,
+		EntryList:  &entryList,
+		Expression: goqu.Ex{},
+		OrderBy: []exp.OrderedExpression{
+			goqu.C(getIDEntryOnCreateCountColumnName).Desc(),
+			goqu.C(getIDEntryIDColumnName).Desc(),
+		},
+		Offset: proto.Uint32(1),
+		Limit:  proto.Uint32(3),
+	}, func(entry interface{}) error {
+		entryList = append(entryList, entry.(getIDEntryWithOnCreateAndOnUpdate))
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []getIDEntryWithOnCreateAndOnUpdate{
+		{ID: 4, StringCol: "value 4", BytesCol: ([]byte)("bytes value 4"), OnCreateCount: 10, OnUpdateCount: 0},
+		{ID: 3, StringCol: "value 3", BytesCol: ([]byte)("bytes value 3"), OnCreateCount: 1, OnUpdateCount: 0},
+		{ID: 2, StringCol: "value 2", BytesCol: ([]byte)("bytes value 2"), OnCreateCount: 1, OnUpdateCount: 0},
+	}, entryList)
+
+	var entryPointerList []*getIDEntryWithOnCreateAndOnUpdate
+	err = orm.Iterate(context.Background(), QueryParams{
+		TableName:  getIDEntryTableName,
+		EntryList:  &entryPointerList,
+		Expression: goqu.C(getIDEntryOnCreateCountColumnName).Lt(10),
+		OrderBy:    []exp.OrderedExpression{goqu.C(getIDEntryIDColumnName).Asc()},
+	}, func(entry interface{}) error {
+		entryPointerList = append(entryPointerList, entry.(*getIDEntryWithOnCreateAndOnUpdate))
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []*getIDEntryWithOnCreateAndOnUpdate{
+		{ID: 1, StringCol: "value 1", BytesCol: ([]byte)("bytes value 1"), OnCreateCount: 1, OnUpdateCount: 0},
+		{ID: 2, StringCol: "value 2", BytesCol: ([]byte)("bytes value 2"), OnCreateCount: 1, OnUpdateCount: 0},
+		{ID: 3, StringCol: "value 3", BytesCol: ([]byte)("bytes value 3"), OnCreateCount: 1, OnUpdateCount: 0},
+	}, entryPointerList)
+
+	iterateCount := 0
+	err = orm.Iterate(context.Background(), QueryParams{
+		TableName:  getIDEntryTableName,
+		EntryList:  &[]getIDEntryWithOnCreateAndOnUpdate{},
+		Expression: goqu.Ex{},
+	}, func(entry interface{}) error {
+		iterateCount++
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, iterateCount)
+
+	err = orm.Iterate(context.Background(), QueryParams{
+		TableName:  getIDEntryTableName,
+		EntryList:  &[]int64{},
+		Expression: goqu.Ex{},
+	}, func(entry interface{}) error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrSliceExpected)
+}
+
+func testIterateWithXLock(t *testing.T, orm ORM) {
+	params := QueryParams{
+		TableName:  getIDEntryTableName,
+		EntryList:  &[]*getIDEntryWithOnCreateAndOnUpdate{},
+		Expression: goqu.C(getIDEntryOnCreateCountColumnName).Gte(10),
+	}
+
+	err := orm.WithTx(func(o ORM) error {
+		var entryList []*getIDEntryWithOnCreateAndOnUpdate
+		if err := o.IterateWithXLock(context.Background(), params, func(entry interface{}) error {
+			entryList = append(entryList, entry.(*getIDEntryWithOnCreateAndOnUpdate))
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		// The connection of the transaction is busy while iterating, so the entries are updated afterwards
+		for _, entry := range entryList {
+			if err := o.Update(context.Background(), entry); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	assert.Nil(t, err)
+
+	entryList := make([]getIDEntryWithOnCreateAndOnUpdate, 0)
+	params.EntryList = &entryList
+	err = orm.Query(context.Background(), params)
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []getIDEntryWithOnCreateAndOnUpdate{
+		{ID: 4, StringCol: "value 4", BytesCol: ([]byte)("bytes value 4"), OnCreateCount: 10, OnUpdateCount: 1},
+		{ID: 5, StringCol: "value 5", BytesCol: ([]byte)("bytes value 5"), OnCreateCount: 10, OnUpdateCount: 1},
+	}, entryList)
+}
+
+func testQueryPage(t *testing.T, orm ORM) {
+	testCases := []struct {
+		OrderBy             []exp.OrderedExpression
+		ExpectedEntryIDList [][]int64
+	}{
+		{
+			OrderBy:             nil,
+			ExpectedEntryIDList: [][]int64{{1, 2}, {3, 4}, {5}},
+		},
+		{
+			OrderBy:             []exp.OrderedExpression{goqu.C(getIDEntryOnCreateCountColumnName).Asc()},
+			ExpectedEntryIDList: [][]int64{{1, 2}, {3, 4}, {5}},
+		},
+		{
+			OrderBy:             []exp.OrderedExpression{goqu.C(getIDEntryOnCreateCountColumnName).Desc()},
+			ExpectedEntryIDList: [][]int64{{4, 5}, {1, 2}, {3}},
+		},
+		{
+			OrderBy: []exp.OrderedExpression{
+				goqu.C(getIDEntryOnCreateCountColumnName).Desc(),
+				goqu.C(getIDEntryIDColumnName).Desc(),
+			},
+			ExpectedEntryIDList: [][]int64{{5, 4}, {3, 2}, {1}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		cursor := Cursor("")
+
+		for i, expectedEntryIDs := range testCase.ExpectedEntryIDList {
+			entryList := make([]getIDEntryWithOnCreateAndOnUpdate, 0)
+			nextCursor, err := orm.QueryPage(context.Background(), QueryParams{
+				TableName: getIDEntryTableName,
+				EntryList: &entryList,
+				OrderBy:   testCase.OrderBy,
+				Limit:     proto.Uint32(2),
+			}, cursor)
+			assert.Nil(t, err)
+
+			entryIDs := make([]int64, 0, len(entryList))
+			for _, entry := range entryList {
+				entryIDs = append(entryIDs, entry.ID)
+			}
+
+			assert.Equal(t, expectedEntryIDs, entryIDs)
+
+			if i == len(testCase.ExpectedEntryIDList)-1 {
+				assert.Empty(t, nextCursor)
+			} else {
+				assert.NotEmpty(t, nextCursor)
+			}
+
+			cursor = nextCursor
+		}
+	}
+
+	entryList := make([]getIDEntryWithOnCreateAndOnUpdate, 0)
+	params := QueryParams{
+		TableName:  getIDEntryTableName,
+		EntryList:  &entryList,
+		Expression: goqu.C(getIDEntryOnCreateCountColumnName).Lt(10),
+		Limit:      proto.Uint32(2),
+	}
+	cursor, err := orm.QueryPage(context.Background(), params, "")
+	assert.Nil(t, err)
+	assert.Len(t, entryList, 2)
+
+	cursor, err = orm.QueryPage(context.Background(), params, cursor)
+	assert.Nil(t, err)
+	assert.Empty(t, cursor)
+	assert.Len(t, entryList, 3)
+
+	_, err = orm.QueryPage(context.Background(), params, "invalid")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	descendingCursor, err := orm.QueryPage(context.Background(), QueryParams{
+		TableName: getIDEntryTableName,
+		EntryList: &[]getIDEntryWithOnCreateAndOnUpdate{},
+		OrderBy:   []exp.OrderedExpression{goqu.C(getIDEntryIDColumnName).Desc()},
+		Limit:     proto.Uint32(1),
+	}, "")
+	assert.Nil(t, err)
+
+	_, err = orm.QueryPage(context.Background(), params, descendingCursor)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	params.Offset = proto.Uint32(1)
+	_, err = orm.QueryPage(context.Background(), params, "")
+	assert.ErrorIs(t, err, ErrOffsetNotSupported)
+}
+
+func testQueryColumns(t *testing.T, orm ORM) {
+	entryList := make([]getIDEntryWithOnCreateAndOnUpdate, 0)
+	err := orm.Query(context.Background(), QueryParams{
+		TableName:  getIDEntryTableName,
+		EntryList:  &entryList,
+		Expression: goqu.C(getIDEntryOnCreateCountColumnName).Gte(10),
+		OrderBy:    []exp.OrderedExpression{goqu.C(getIDEntryIDColumnName).Asc()},
+		Columns:    []interface{}{getIDEntryIDColumnName, "string_col"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []getIDEntryWithOnCreateAndOnUpdate{
+		{ID: 4, StringCol: "value 4"},
+		{ID: 5, StringCol: "value 5"},
+	}, entryList)
+
+	var iteratedEntryList []*getIDEntryWithOnCreateAndOnUpdate
+	err = orm.Iterate(context.Background(), QueryParams{
+		TableName:  getIDEntryTableName,
+		EntryList:  &[]*getIDEntryWithOnCreateAndOnUpdate{},
+		Expression: goqu.C(getIDEntryIDColumnName).Eq(1),
+		Columns:    []interface{}{"bytes_col"},
+	}, func(entry interface{}) error {
+		iteratedEntryList = append(iteratedEntryList, entry.(*getIDEntryWithOnCreateAndOnUpdate))
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []*getIDEntryWithOnCreateAndOnUpdate{
+		{BytesCol: ([]byte)("bytes value 1")},
+	}, iteratedEntryList)
+
+	// The keyset columns are selected as well, since the cursor is read from them
+	entryList = make([]getIDEntryWithOnCreateAndOnUpdate, 0)
+	params := QueryParams{
+		TableName: getIDEntryTableName,
+		EntryList: &entryList,
+		OrderBy:   []exp.OrderedExpression{goqu.C(getIDEntryOnCreateCountColumnName).Desc()},
+		Limit:     proto.Uint32(2),
+		Columns:   []interface{}{"string_col"},
+	}
+	cursor, err := orm.QueryPage(context.Background(), params, "")
+	assert.Nil(t, err)
+
+	_, err = orm.QueryPage(context.Background(), params, cursor)
+	assert.Nil(t, err)
+	assert.Equal(t, []getIDEntryWithOnCreateAndOnUpdate{
+		{ID: 4, StringCol: "value 4", OnCreateCount: 10},
+		{ID: 5, StringCol: "value 5", OnCreateCount: 10},
+		{ID: 1, StringCol: "value 1", OnCreateCount: 1},
+		{ID: 2, StringCol: "value 2", OnCreateCount: 1},
+	}, entryList)
+}
+
 func testCount(t *testing.T, orm ORM) {
 	testCases := []struct {
 		Expression    goqu.Expression
@@ -596,6 +844,365 @@ func testCount(t *testing.T, orm ORM) {
 	}
 }
 
+func testAggregate(t *testing.T, orm ORM) {
+	var sum int64
+	err := orm.Sum(context.Background(), getIDEntryTableName, getIDEntryOnCreateCountColumnName, nil, &sum)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(23), sum)
+
+	var emptySum sql.NullInt64
+	err = orm.Sum(
+		context.Background(),
+		getIDEntryTableName,
+		getIDEntryOnCreateCountColumnName,
+		goqu.C(getIDEntryIDColumnName).Gt(5),
+		&emptySum,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, sql.NullInt64{Int64: 0, Valid: true}, emptySum)
+
+	var min, max int64
+	err = orm.Min(context.Background(), getIDEntryTableName, getIDEntryOnCreateCountColumnName, nil, &min)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), min)
+
+	err = orm.Max(
+		context.Background(),
+		getIDEntryTableName,
+		getIDEntryOnCreateCountColumnName,
+		goqu.C(getIDEntryIDColumnName).Lt(4),
+		&max,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), max)
+
+	var avg float64
+	err = orm.Avg(context.Background(), getIDEntryTableName, getIDEntryOnCreateCountColumnName, goqu.Ex{}, &avg)
+	assert.Nil(t, err)
+	assert.InDelta(t, 4.6, avg, 0.000001)
+
+	err = orm.Sum(context.Background(), getIDEntryTableName, getIDEntryOnCreateCountColumnName, nil, nil)
+	assert.ErrorIs(t, err, ErrNilEntry)
+
+	found, err := orm.Exists(context.Background(), getIDEntryTableName, goqu.C(getIDEntryIDColumnName).Eq(1))
+	assert.Nil(t, err)
+	assert.True(t, found)
+
+	found, err = orm.Exists(context.Background(), getIDEntryTableName, goqu.C(getIDEntryIDColumnName).Eq(999))
+	assert.Nil(t, err)
+	assert.False(t, found)
+}
+
+func testQueryGroupBy(t *testing.T, orm ORM) {
+	groupList := make([]onCreateCountGroup, 0)
+	params := QueryParams{
+		TableName: getIDEntryTableName,
+		EntryList: &groupList,
+		Columns:   []interface{}{getIDEntryOnCreateCountColumnName, goqu.COUNT(goqu.Star()).As("entry_count")},
+		GroupBy:   []interface{}{getIDEntryOnCreateCountColumnName},
+		OrderBy:   []exp.OrderedExpression{goqu.C(getIDEntryOnCreateCountColumnName).Asc()},
+	}
+	err := orm.Query(context.Background(), params)
+	assert.Nil(t, err)
+	assert.Equal(t, []onCreateCountGroup{
+		{OnCreateCount: 1, EntryCount: 3},
+		{OnCreateCount: 10, EntryCount: 2},
+	}, groupList)
+
+	groupList = make([]onCreateCountGroup, 0)
+	params.EntryList = &groupList
+	params.Having = goqu.COUNT(goqu.Star()).Gt(2)
+	err = orm.Query(context.Background(), params)
+	assert.Nil(t, err)
+	assert.Equal(t, []onCreateCountGroup{
+		{OnCreateCount: 1, EntryCount: 3},
+	}, groupList)
+}
+
+func testPreload(t *testing.T, orm ORM) {
+	parentList := make([]*getParentEntry, 0)
+	err := orm.Preload("Children").Query(context.Background(), QueryParams{
+		TableName: getIDEntryTableName,
+		EntryList: &parentList,
+		OrderBy:   []exp.OrderedExpression{goqu.C(getIDEntryIDColumnName).Asc()},
+	})
+	assert.Nil(t, err)
+
+	if !assert.Len(t, parentList, 3) {
+		return
+	}
+
+	assert.Equal(t, []*getChildEntry{
+		{ID: 1, ParentID: 1, StringCol: "child 1"},
+		{ID: 2, ParentID: 1, StringCol: "child 2"},
+	}, sortChildEntries(parentList[0].Children))
+	assert.Equal(t, []*getChildEntry{{ID: 3, ParentID: 2, StringCol: "child 3"}}, parentList[1].Children)
+	assert.Equal(t, []*getChildEntry{}, parentList[2].Children)
+
+	childEntry := &getChildEntry{ID: 3}
+	err = orm.Preload("Parent").GetWithXLock(context.Background(), childEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, &getParentEntry{ID: 2, StringCol: "value 2"}, childEntry.Parent)
+
+	childList := make([]*getChildEntry, 0)
+	err = orm.Preload("Parent").QueryWithXLock(context.Background(), QueryParams{
+		TableName: getChildEntryTableName,
+		EntryList: &childList,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, childList, 3)
+
+	for _, childEntry := range childList {
+		if assert.NotNil(t, childEntry.Parent) {
+			assert.Equal(t, childEntry.ParentID, childEntry.Parent.ID)
+		}
+	}
+
+	parentEntry := &getParentEntryWithRelations{ID: 1}
+	err = orm.Preload("Children").Get(context.Background(), parentEntry)
+	assert.Nil(t, err)
+	assert.Len(t, parentEntry.Children, 2)
+
+	parentEntry = &getParentEntryWithRelations{ID: 1}
+	err = orm.Get(context.Background(), parentEntry)
+	assert.Nil(t, err)
+	assert.Nil(t, parentEntry.Children)
+
+	err = orm.Preload("StringCol").Get(context.Background(), &getParentEntry{ID: 1})
+	assert.ErrorIs(t, err, ErrRelationNotFound)
+}
+
+func sortChildEntries(childList []*getChildEntry) []*getChildEntry {
+	sort.Slice(childList, func(i, j int) bool {
+		return childList[i].ID < childList[j].ID
+	})
+
+	return childList
+}
+
+func testGenerateCreateTableStatement(t *testing.T, orm ORM, driverType DriverType) {
+	_, err := orm.GetDBWrapper().Exec("DROP TABLE IF EXISTS " + ddlEntryTableName)
+	assert.Nil(t, err)
+
+	statement, err := GenerateCreateTableStatement(driverType, &ddlEntry{})
+	assert.Nil(t, err)
+
+	_, err = orm.GetDBWrapper().Exec(statement)
+	assert.Nil(t, err)
+
+	entry := &ddlEntry{
+		Code:       "code 1",
+		BoolCol:    true,
+		Int32Col:   32,
+		FloatCol:   1.5,
+		StringCol:  "value 1",
+		BytesCol:   []byte("bytes value 1"),
+		NullIntCol: sql.NullInt64{Int64: 64, Valid: true},
+	}
+	err = orm.Create(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.NotZero(t, entry.ID)
+
+	createdEntry := &ddlEntry{Code: entry.Code}
+	err = orm.Get(context.Background(), createdEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, entry, createdEntry)
+
+	err = orm.Create(context.Background(), &ddlEntry{Code: entry.Code, BytesCol: []byte{}})
+	assert.ErrorIs(t, err, ErrUniqueViolation)
+}
+
+func testVerifySchema(t *testing.T, orm ORM, driverType DriverType) {
+	_, err := orm.GetDBWrapper().Exec("DROP TABLE IF EXISTS " + ddlEntryTableName)
+	assert.Nil(t, err)
+
+	statement, err := GenerateCreateTableStatement(driverType, &ddlEntry{})
+	assert.Nil(t, err)
+
+	_, err = orm.GetDBWrapper().Exec(statement)
+	assert.Nil(t, err)
+
+	err = orm.VerifySchema(
+		context.Background(),
+		&getIDEntry{},
+		&getUniqueEntryWithOnCreateAndOnUpdate{},
+		&getKeyEntry{},
+		&getChildEntry{},
+		&ddlEntry{},
+	)
+	assert.Nil(t, err)
+
+	err = orm.VerifySchema(context.Background(), struct{}{})
+	assert.ErrorIs(t, err, ErrTableNameGetterExpected)
+
+	err = orm.VerifySchema(context.Background(), &schemaDriftEntry{}, &schemaMissingTableEntry{})
+	assert.ErrorIs(t, err, ErrSchemaMismatch)
+
+	var schemaError *SchemaError
+	if !assert.ErrorAs(t, err, &schemaError) {
+		return
+	}
+
+	assert.Len(t, schemaError.Mismatches, 5)
+
+	for index := range schemaError.Mismatches {
+		schemaError.Mismatches[index].DataType = ""
+	}
+
+	assert.Equal(t, []SchemaMismatch{
+		{
+			Kind:      SchemaMismatchKindColumnType,
+			TableName: getIDEntryTableName,
+			Columns:   []string{"string_col"},
+			FieldType: "int64",
+		},
+		{Kind: SchemaMismatchKindMissingColumn, TableName: getIDEntryTableName, Columns: []string{"missing_col"}},
+		{Kind: SchemaMismatchKindRequiredColumn, TableName: getIDEntryTableName, Columns: []string{"bytes_col"}},
+		{
+			Kind:      SchemaMismatchKindMissingUniqueIndex,
+			TableName: getIDEntryTableName,
+			Columns:   []string{getIDEntryOnCreateCountColumnName},
+		},
+		{Kind: SchemaMismatchKindMissingTable, TableName: schemaMissingTableEntryTableName},
+	}, schemaError.Mismatches)
+}
+
+func testHooks(t *testing.T, orm ORM) {
+	countEntries := func(tableName string) int64 {
+		count, err := orm.Count(context.Background(), tableName, goqu.Ex{})
+		assert.Nil(t, err)
+
+		return count
+	}
+
+	// A failing hook rolls back the operation and the writes of the other hooks
+	entry := &hookEntry{StringCol: "value 1", BytesCol: []byte("bytes value 1"), FailingHook: "AfterCreate"}
+	err := orm.Create(context.Background(), entry)
+	assert.ErrorIs(t, err, errHookFailed)
+	assert.Equal(t, []string{"BeforeCreate", "AfterCreate"}, entry.Hooks)
+	assert.Equal(t, int64(0), countEntries(getIDEntryTableName))
+	assert.Equal(t, int64(0), countEntries(getChildEntryTableName))
+
+	entry = &hookEntry{StringCol: "value 1", BytesCol: []byte("bytes value 1"), FailingHook: "BeforeCreate"}
+	err = orm.Create(context.Background(), entry)
+	assert.ErrorIs(t, err, errHookFailed)
+	assert.Equal(t, []string{"BeforeCreate"}, entry.Hooks)
+	assert.Equal(t, int64(0), entry.OnCreateCount)
+
+	entry = &hookEntry{StringCol: "value 1", BytesCol: []byte("bytes value 1")}
+	err = orm.Create(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"BeforeCreate", "AfterCreate"}, entry.Hooks)
+
+	childEntry := &getChildEntry{ID: entry.ID}
+	err = orm.Get(context.Background(), childEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, "value 1", childEntry.StringCol)
+
+	loadedEntry := &hookEntry{ID: entry.ID}
+	err = orm.Get(context.Background(), loadedEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"AfterLoad"}, loadedEntry.Hooks)
+
+	err = orm.Get(context.Background(), &hookEntry{ID: entry.ID, FailingHook: "AfterLoad"})
+	assert.ErrorIs(t, err, errHookFailed)
+
+	// The hooks are called with the transaction ORM, so their writes are rolled back along with the transaction
+	txEntry := &hookEntry{StringCol: "value 2", BytesCol: []byte("bytes value 2")}
+	err = orm.WithTx(func(txORM ORM) error {
+		if err := txORM.Create(context.Background(), txEntry); err != nil {
+			return err
+		}
+
+		return errHookFailed
+	})
+	assert.ErrorIs(t, err, errHookFailed)
+	assert.Equal(t, int64(1), countEntries(getIDEntryTableName))
+	assert.Equal(t, int64(1), countEntries(getChildEntryTableName))
+
+	entries := []hookEntry{
+		{StringCol: "value 3", BytesCol: []byte("bytes value 3")},
+		{StringCol: "value 4", BytesCol: []byte("bytes value 4")},
+	}
+	err = orm.CreateMany(context.Background(), &entries)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), countEntries(getChildEntryTableName))
+
+	for _, createdEntry := range entries {
+		assert.Equal(t, []string{"BeforeCreate", "AfterCreate"}, createdEntry.Hooks)
+	}
+
+	loadedEntries := make([]hookEntry, 0)
+	err = orm.Query(context.Background(), QueryParams{
+		TableName: getIDEntryTableName,
+		EntryList: &loadedEntries,
+	})
+	assert.Nil(t, err)
+	assert.Len(t, loadedEntries, 3)
+
+	for _, queriedEntry := range loadedEntries {
+		assert.Equal(t, []string{"AfterLoad"}, queriedEntry.Hooks)
+	}
+
+	err = orm.Iterate(context.Background(), QueryParams{
+		TableName: getIDEntryTableName,
+		EntryList: &[]*hookEntry{},
+	}, func(iteratedEntry interface{}) error {
+		assert.Equal(t, []string{"AfterLoad"}, iteratedEntry.(*hookEntry).Hooks)
+
+		return nil
+	})
+	assert.Nil(t, err)
+
+	entry.Hooks = nil
+	entry.StringCol = "value 5"
+	entry.FailingHook = "AfterUpdate"
+	err = orm.Update(context.Background(), entry)
+	assert.ErrorIs(t, err, errHookFailed)
+	assert.Equal(t, []string{"BeforeUpdate", "AfterUpdate"}, entry.Hooks)
+
+	childEntry = &getChildEntry{ID: entry.ID}
+	err = orm.Get(context.Background(), childEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, "value 1", childEntry.StringCol)
+
+	entry.Hooks = nil
+	entry.FailingHook = ""
+	err = orm.UpdateColumns(context.Background(), entry, "string_col")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"BeforeUpdate", "AfterUpdate"}, entry.Hooks)
+
+	childEntry = &getChildEntry{ID: entry.ID}
+	err = orm.Get(context.Background(), childEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, "value 5", childEntry.StringCol)
+
+	entry.Hooks = nil
+	err = orm.CreateOrUpdate(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"BeforeUpdate", "AfterUpdate"}, entry.Hooks)
+
+	entry.Hooks = nil
+	entry.FailingHook = "BeforeDelete"
+	err = orm.Delete(context.Background(), entry)
+	assert.ErrorIs(t, err, errHookFailed)
+	assert.Equal(t, []string{"BeforeDelete"}, entry.Hooks)
+	assert.Equal(t, int64(3), countEntries(getIDEntryTableName))
+
+	entry.Hooks = nil
+	entry.FailingHook = ""
+	err = orm.HardDelete(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"BeforeDelete", "AfterDelete"}, entry.Hooks)
+	assert.Equal(t, int64(2), countEntries(getIDEntryTableName))
+	assert.Equal(t, int64(2), countEntries(getChildEntryTableName))
+
+	entry.Hooks = nil
+	err = orm.Delete(context.Background(), entry)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, []string{"BeforeDelete"}, entry.Hooks)
+}
+
 func testCreateOrUpdate(t *testing.T, orm ORM, sequenceStart int64) {
 	err := orm.CreateOrUpdate(context.Background(), nil)
 	assert.ErrorIs(t, err, ErrNilEntry)
@@ -887,6 +1494,57 @@ func testUpdate(t *testing.T, orm ORM) {
 	assert.ErrorIs(t, err, ErrUpdateNotApplied)
 }
 
+func testUpdateColumns(t *testing.T, orm ORM) {
+	entry := &getIDEntryWithOnCreateAndOnUpdate{ID: 100}
+	err := orm.Get(context.Background(), entry)
+	assert.Nil(t, err)
+
+	// bytes_col is not written, while on_update_count is written since OnUpdate() changed it
+	entry.StringCol = "updated value 1"
+	entry.BytesCol = ([]byte)("updated bytes value 1")
+	err = orm.UpdateColumns(context.Background(), entry, "string_col")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), entry.OnUpdateCount)
+
+	entry = &getIDEntryWithOnCreateAndOnUpdate{ID: 100}
+	err = orm.Get(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, &getIDEntryWithOnCreateAndOnUpdate{
+		ID:            100,
+		StringCol:     "updated value 1",
+		BytesCol:      ([]byte)("bytes value 1"),
+		OnCreateCount: 1,
+		OnUpdateCount: 1,
+	}, entry)
+
+	versionedEntry := &versionedIDEntry{ID: 100}
+	err = orm.Get(context.Background(), versionedEntry)
+	assert.Nil(t, err)
+
+	staleEntry := *versionedEntry
+
+	versionedEntry.BytesCol = ([]byte)("updated bytes value 2")
+	err = orm.UpdateColumns(context.Background(), versionedEntry, "bytes_col")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), versionedEntry.Version)
+
+	staleEntry.StringCol = "updated value 2"
+	err = orm.UpdateColumns(context.Background(), &staleEntry, "string_col")
+	assert.ErrorIs(t, err, ErrStaleEntry)
+
+	err = orm.UpdateColumns(context.Background(), entry)
+	assert.ErrorIs(t, err, ErrColumnsExpected)
+
+	err = orm.UpdateColumns(context.Background(), entry, getIDEntryOnCreateCountColumnName)
+	assert.ErrorIs(t, err, ErrColumnNotUpdatable)
+
+	err = orm.UpdateColumns(context.Background(), nil, "string_col")
+	assert.ErrorIs(t, err, ErrNilEntry)
+
+	err = orm.UpdateColumns(context.Background(), &getIDEntryWithOnCreateAndOnUpdate{ID: 999}, "string_col")
+	assert.ErrorIs(t, err, ErrUpdateNotApplied)
+}
+
 func testDelete(t *testing.T, orm ORM) {
 	err := orm.Delete(context.Background(), nil)
 	assert.ErrorIs(t, err, ErrNilEntry)
@@ -973,3 +1631,1062 @@ func testWithTX(t *testing.T, orm ORM) {
 		OnUpdateCount: 0,
 	}, entry)
 }
+
+func testWithTxContext(t *testing.T, orm ORM) {
+	rollbackErr := errors.New("error to trigger rollback")
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	err := orm.WithTxContext(ctx, nil, func(txCtx context.Context, o ORM) error {
+		assert.Equal(t, "value", txCtx.Value(ctxKey{}))
+
+		entry := &getIDEntryWithOnCreateAndOnUpdate{ID: 1}
+		if err := o.GetWithXLock(txCtx, entry); err != nil {
+			return err
+		}
+
+		if err := o.Update(txCtx, entry); err != nil {
+			return err
+		}
+
+		return rollbackErr
+	})
+	assert.ErrorIs(t, err, rollbackErr)
+
+	entry := &getIDEntryWithOnCreateAndOnUpdate{ID: 1}
+	err = orm.Get(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), entry.OnUpdateCount)
+
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	executed := false
+	err = orm.WithTxContext(cancelledCtx, nil, func(txCtx context.Context, o ORM) error {
+		executed = true
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, executed)
+
+	err = orm.WithTxContext(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}, func(txCtx context.Context, o ORM) error {
+		entry := &getIDEntryWithOnCreateAndOnUpdate{ID: 1}
+		if err := o.GetWithXLock(txCtx, entry); err != nil {
+			return err
+		}
+
+		return o.Update(txCtx, entry)
+	})
+	assert.Nil(t, err)
+
+	entry = &getIDEntryWithOnCreateAndOnUpdate{ID: 1}
+	err = orm.Get(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), entry.OnUpdateCount)
+
+	err = orm.WithTxContext(ctx, nil, func(txCtx context.Context, o1 ORM) error {
+		return o1.WithTxContext(txCtx, nil, func(innerCtx context.Context, o2 ORM) error {
+			assert.Equal(t, "value", innerCtx.Value(ctxKey{}))
+			return nil
+		})
+	})
+	assert.Nil(t, err)
+}
+
+func testNestedWithTx(t *testing.T, orm ORM) {
+	rollbackErr := errors.New("error to trigger rollback")
+
+	updateEntry := func(o ORM) error {
+		entry := &getIDEntryWithOnCreateAndOnUpdate{ID: 1}
+		if err := o.GetWithXLock(context.Background(), entry); err != nil {
+			return err
+		}
+
+		return o.Update(context.Background(), entry)
+	}
+
+	err := orm.WithTx(func(o1 ORM) error {
+		if err := updateEntry(o1); err != nil {
+			return err
+		}
+
+		innerErr := o1.WithTx(func(o2 ORM) error {
+			if err := updateEntry(o2); err != nil {
+				return err
+			}
+
+			return rollbackErr
+		})
+		assert.ErrorIs(t, innerErr, rollbackErr)
+
+		return o1.WithTx(func(o2 ORM) error {
+			if err := updateEntry(o2); err != nil {
+				return err
+			}
+
+			innerErr := o2.WithTx(func(o3 ORM) error {
+				if err := updateEntry(o3); err != nil {
+					return err
+				}
+
+				return rollbackErr
+			})
+			assert.ErrorIs(t, innerErr, rollbackErr)
+
+			return nil
+		})
+	})
+	assert.Nil(t, err)
+
+	entry := &getIDEntryWithOnCreateAndOnUpdate{ID: 1}
+	err = orm.Get(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, &getIDEntryWithOnCreateAndOnUpdate{
+		ID:            1,
+		StringCol:     "value 1",
+		BytesCol:      ([]byte)("bytes value 1"),
+		OnCreateCount: 1,
+		OnUpdateCount: 2,
+	}, entry)
+
+	err = orm.WithTx(func(o1 ORM) error {
+		if err := o1.WithTx(updateEntry); err != nil {
+			return err
+		}
+
+		return rollbackErr
+	})
+	assert.ErrorIs(t, err, rollbackErr)
+
+	entry = &getIDEntryWithOnCreateAndOnUpdate{ID: 1}
+	err = orm.Get(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), entry.OnUpdateCount)
+}
+
+func testCreateOrUpdateUpsert(t *testing.T, orm ORM, sequenceStart int64) {
+	testCreateOrUpdate(t, orm, sequenceStart)
+
+	getUniqueEntry1 := &getUniqueEntryWithOnCreateAndOnUpdate{ID1: 1, ID2: 2}
+	err := orm.Get(context.Background(), getUniqueEntry1)
+	assert.Nil(t, err)
+	assert.Equal(t, &getUniqueEntryWithOnCreateAndOnUpdate{
+		ID1:           1,
+		ID2:           2,
+		StringCol:     "value 1",
+		BytesCol:      ([]byte)("bytes value 1"),
+		OnCreateCount: 1,
+		OnUpdateCount: 1,
+	}, getUniqueEntry1)
+
+	getUniqueEntry2 := &getUniqueEntryWithOnCreateAndOnUpdate{ID1: 2, ID2: 2}
+	err = orm.Get(context.Background(), getUniqueEntry2)
+	assert.Nil(t, err)
+	assert.Equal(t, &getUniqueEntryWithOnCreateAndOnUpdate{
+		ID1:           2,
+		ID2:           2,
+		StringCol:     "value 2",
+		BytesCol:      ([]byte)("bytes value 2"),
+		OnCreateCount: 1,
+		OnUpdateCount: 0,
+	}, getUniqueEntry2)
+
+	getUniqueEntry2.StringCol = "value 2 updated"
+	err = orm.CreateOrUpdate(context.Background(), getUniqueEntry2)
+	assert.Nil(t, err)
+	assert.Equal(t, &getUniqueEntryWithOnCreateAndOnUpdate{
+		ID1:           2,
+		ID2:           2,
+		StringCol:     "value 2 updated",
+		BytesCol:      ([]byte)("bytes value 2"),
+		OnCreateCount: 1,
+		OnUpdateCount: 1,
+	}, getUniqueEntry2)
+
+	// Upserting the values that the row already has changes nothing, but is still an update
+	unchangedEntry := &getUniqueEntryWithOnCreateAndOnUpdate{
+		ID1:       2,
+		ID2:       2,
+		StringCol: "value 2 updated",
+		BytesCol:  ([]byte)("bytes value 2"),
+	}
+	err = orm.CreateOrUpdate(context.Background(), unchangedEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, &getUniqueEntryWithOnCreateAndOnUpdate{
+		ID1:           2,
+		ID2:           2,
+		StringCol:     "value 2 updated",
+		BytesCol:      ([]byte)("bytes value 2"),
+		OnCreateCount: 0,
+		OnUpdateCount: 1,
+	}, unchangedEntry)
+
+	count, err := orm.Count(context.Background(), getUniqueEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func testCreateMany(t *testing.T, orm ORM, sequenceStart int64) {
+	err := orm.CreateMany(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrNilEntry)
+
+	err = orm.CreateMany(context.Background(), &getIDEntry{})
+	assert.ErrorIs(t, err, ErrSliceExpected)
+
+	err = orm.CreateMany(context.Background(), []*getIDEntry{nil})
+	assert.ErrorIs(t, err, ErrNilEntry)
+
+	err = orm.CreateMany(context.Background(), []struct{}{{}})
+	assert.ErrorIs(t, err, ErrTableNameGetterExpected)
+
+	err = orm.CreateMany(context.Background(), []*getIDEntry{})
+	assert.Nil(t, err)
+
+	getIDEntryList1 := []*getIDEntryWithOnCreateAndOnUpdate{
+		{StringCol: "value 1", BytesCol: ([]byte)("bytes value 1")},
+		{StringCol: "value 2", BytesCol: ([]byte)("bytes value 2")},
+		{StringCol: "value 3", BytesCol: ([]byte)("bytes value 3")},
+	}
+	err = orm.CreateMany(context.Background(), getIDEntryList1)
+	assert.Nil(t, err)
+	assert.Equal(t, []*getIDEntryWithOnCreateAndOnUpdate{
+		{ID: sequenceStart + 1, StringCol: "value 1", BytesCol: ([]byte)("bytes value 1"), OnCreateCount: 1},
+		{ID: sequenceStart + 2, StringCol: "value 2", BytesCol: ([]byte)("bytes value 2"), OnCreateCount: 1},
+		{ID: sequenceStart + 3, StringCol: "value 3", BytesCol: ([]byte)("bytes value 3"), OnCreateCount: 1},
+	}, getIDEntryList1)
+
+	getIDEntryList2 := []getIDEntry{
+		{StringCol: "value 4", BytesCol: ([]byte)("bytes value 4")},
+		{StringCol: "value 5", BytesCol: ([]byte)("bytes value 5")},
+	}
+	err = orm.CreateMany(context.Background(), getIDEntryList2)
+	assert.Nil(t, err)
+	assert.Equal(t, []getIDEntry{
+		{ID: sequenceStart + 4, StringCol: "value 4", BytesCol: ([]byte)("bytes value 4")},
+		{ID: sequenceStart + 5, StringCol: "value 5", BytesCol: ([]byte)("bytes value 5")},
+	}, getIDEntryList2)
+
+	for _, expectedEntry := range getIDEntryList1 {
+		entry := &getIDEntryWithOnCreateAndOnUpdate{ID: expectedEntry.ID}
+		err = orm.Get(context.Background(), entry)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedEntry, entry)
+	}
+
+	getUniqueEntryList := []*getUniqueEntryWithOnCreateAndOnUpdate{
+		{ID1: 1, ID2: 1, StringCol: "value 1", BytesCol: ([]byte)("bytes value 1")},
+		{ID1: 1, ID2: 2, StringCol: "value 2", BytesCol: ([]byte)("bytes value 2")},
+		{ID1: 1, ID2: 3, StringCol: "value 3", BytesCol: ([]byte)("bytes value 3")},
+	}
+	err = orm.CreateMany(context.Background(), getUniqueEntryList)
+	assert.Nil(t, err)
+
+	count, err := orm.Count(context.Background(), getUniqueEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// Entries are created in a single transaction, so a failing chunk rolls back the whole operation
+	err = orm.CreateMany(context.Background(), []*getUniqueEntryWithOnCreateAndOnUpdate{
+		{ID1: 2, ID2: 1, StringCol: "value 1", BytesCol: ([]byte)("bytes value 1")},
+		{ID1: 2, ID2: 2, StringCol: "value 2", BytesCol: ([]byte)("bytes value 2")},
+		{ID1: 1, ID2: 1, StringCol: "value 3", BytesCol: ([]byte)("bytes value 3")},
+	})
+	assert.NotNil(t, err)
+
+	count, err = orm.Count(context.Background(), getUniqueEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func testUpdateWhere(t *testing.T, orm ORM) {
+	rowsAffected, err := orm.UpdateWhere(
+		context.Background(),
+		getIDEntryTableName,
+		goqu.C(getIDEntryOnCreateCountColumnName).Lt(10),
+		goqu.Record{getIDEntryOnUpdateCountColumnName: 1},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), rowsAffected)
+
+	rowsAffected, err = orm.UpdateWhereWithLimit(
+		context.Background(),
+		getIDEntryTableName,
+		goqu.C(getIDEntryOnCreateCountColumnName).Gte(10),
+		goqu.Record{getIDEntryOnUpdateCountColumnName: 2},
+		1,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), rowsAffected)
+
+	rowsAffected, err = orm.UpdateWhere(
+		context.Background(),
+		getIDEntryTableName,
+		goqu.C(getIDEntryOnCreateCountColumnName).Gt(100),
+		goqu.Record{getIDEntryOnUpdateCountColumnName: 3},
+	)
+	assert.Nil(t, err)
+	assert.Zero(t, rowsAffected)
+
+	testCases := []struct {
+		Expression    goqu.Expression
+		ExpectedCount int64
+	}{
+		{
+			Expression:    goqu.C(getIDEntryOnUpdateCountColumnName).Eq(0),
+			ExpectedCount: 1,
+		},
+		{
+			Expression:    goqu.C(getIDEntryOnUpdateCountColumnName).Eq(1),
+			ExpectedCount: 3,
+		},
+		{
+			Expression:    goqu.C(getIDEntryOnUpdateCountColumnName).Eq(2),
+			ExpectedCount: 1,
+		},
+	}
+
+	for _, testCase := range testCases {
+		count, err := orm.Count(context.Background(), getIDEntryTableName, testCase.Expression)
+		assert.Equal(t, testCase.ExpectedCount, count)
+		assert.Nil(t, err)
+	}
+
+	_, err = orm.UpdateWhere(context.Background(), getIDEntryTableName, goqu.Ex{}, goqu.Record{"unknown_col": 1})
+	assert.NotNil(t, err)
+}
+
+func testDeleteWhere(t *testing.T, orm ORM) {
+	rowsAffected, err := orm.DeleteWhereWithLimit(
+		context.Background(),
+		getIDEntryTableName,
+		goqu.C(getIDEntryOnCreateCountColumnName).Lt(10),
+		2,
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), rowsAffected)
+
+	count, err := orm.Count(context.Background(), getIDEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+
+	rowsAffected, err = orm.DeleteWhere(
+		context.Background(),
+		getIDEntryTableName,
+		goqu.C(getIDEntryOnCreateCountColumnName).Gte(10),
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), rowsAffected)
+
+	rowsAffected, err = orm.DeleteWhere(
+		context.Background(),
+		getIDEntryTableName,
+		goqu.C(getIDEntryOnCreateCountColumnName).Gte(10),
+	)
+	assert.Nil(t, err)
+	assert.Zero(t, rowsAffected)
+
+	count, err = orm.Count(context.Background(), getIDEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func testOptimisticLocking(t *testing.T, orm ORM) {
+	entry1 := &versionedIDEntry{ID: 100}
+	err := orm.Get(context.Background(), entry1)
+	assert.Nil(t, err)
+
+	entry2 := &versionedIDEntry{ID: 100}
+	err = orm.Get(context.Background(), entry2)
+	assert.Nil(t, err)
+
+	entry1.StringCol = "updated value 1"
+	err = orm.Update(context.Background(), entry1)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), entry1.Version)
+	assert.Equal(t, int64(1), entry1.OnUpdateCount)
+
+	entry2.StringCol = "updated value 2"
+	err = orm.Update(context.Background(), entry2)
+	assert.ErrorIs(t, err, ErrStaleEntry)
+	assert.Equal(t, int64(0), entry2.Version)
+
+	entry2 = &versionedIDEntry{ID: 100}
+	err = orm.Get(context.Background(), entry2)
+	assert.Nil(t, err)
+	assert.Equal(t, entry1, entry2)
+
+	entry2.StringCol = "updated value 2"
+	err = orm.CreateOrUpdate(context.Background(), entry2)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), entry2.Version)
+
+	err = orm.Update(context.Background(), &versionedIDEntry{ID: 999})
+	assert.ErrorIs(t, err, ErrStaleEntry)
+
+	uniqueEntry := &versionedUniqueEntry{ID1: 1, ID2: 2, StringCol: "updated value 1", BytesCol: []byte{}, Revision: 5}
+	err = orm.CreateOrUpdate(context.Background(), uniqueEntry)
+	assert.ErrorIs(t, err, ErrStaleEntry)
+	assert.Equal(t, int64(5), uniqueEntry.Revision)
+
+	uniqueEntry = &versionedUniqueEntry{ID1: 1, ID2: 2, StringCol: "updated value 1", BytesCol: []byte{}}
+	err = orm.CreateOrUpdate(context.Background(), uniqueEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), uniqueEntry.Revision)
+
+	newUniqueEntry := &versionedUniqueEntry{ID1: 1, ID2: 3, StringCol: "value 2", BytesCol: []byte{}}
+	err = orm.CreateOrUpdate(context.Background(), newUniqueEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), newUniqueEntry.Revision)
+	assert.Equal(t, int64(1), newUniqueEntry.OnCreateCount)
+
+	fetchedUniqueEntry := &versionedUniqueEntry{ID1: 1, ID2: 2}
+	err = orm.Get(context.Background(), fetchedUniqueEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, "updated value 1", fetchedUniqueEntry.StringCol)
+	assert.Equal(t, int64(1), fetchedUniqueEntry.Revision)
+	assert.Equal(t, int64(1), fetchedUniqueEntry.OnUpdateCount)
+}
+
+// testSoftDelete expects softDeleteIDEntry to be one of the Models of orm
+func testSoftDelete(t *testing.T, orm ORM) {
+	entry := &softDeleteIDEntry{ID: 1}
+	err := orm.Delete(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.NotNil(t, entry.DeletedAt)
+
+	err = orm.Delete(context.Background(), &softDeleteIDEntry{ID: 1})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = orm.Get(context.Background(), &softDeleteIDEntry{ID: 1})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = orm.WithTx(func(txORM ORM) error {
+		return txORM.GetWithXLock(context.Background(), &softDeleteIDEntry{ID: 1})
+	})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	deletedEntry := &softDeleteIDEntry{ID: 1}
+	err = orm.Unscoped().Get(context.Background(), deletedEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, entry.DeletedAt, deletedEntry.DeletedAt)
+
+	err = orm.Unscoped().WithTx(func(txORM ORM) error {
+		return txORM.GetWithXLock(context.Background(), &softDeleteIDEntry{ID: 1})
+	})
+	assert.Nil(t, err)
+
+	entryList := []softDeleteIDEntry{}
+	err = orm.Query(context.Background(), QueryParams{TableName: getIDEntryTableName, EntryList: &entryList})
+	assert.Nil(t, err)
+	assert.Len(t, entryList, 4)
+
+	entryList = []softDeleteIDEntry{}
+	err = orm.WithTx(func(txORM ORM) error {
+		return txORM.QueryWithXLock(context.Background(), QueryParams{
+			TableName:  getIDEntryTableName,
+			EntryList:  &entryList,
+			Expression: goqu.C(getIDEntryOnCreateCountColumnName).Lt(10),
+		})
+	})
+	assert.Nil(t, err)
+	assert.Len(t, entryList, 2)
+
+	unscopedEntryList := []*softDeleteIDEntry{}
+	err = orm.Unscoped().Query(context.Background(), QueryParams{TableName: getIDEntryTableName, EntryList: &unscopedEntryList})
+	assert.Nil(t, err)
+	assert.Len(t, unscopedEntryList, 5)
+
+	count, err := orm.Count(context.Background(), getIDEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), count)
+
+	count, err = orm.Unscoped().Count(context.Background(), getIDEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), count)
+
+	found, err := orm.Exists(context.Background(), getIDEntryTableName, goqu.Ex{"id": 1})
+	assert.Nil(t, err)
+	assert.False(t, found)
+
+	found, err = orm.Unscoped().Exists(context.Background(), getIDEntryTableName, goqu.Ex{"id": 1})
+	assert.Nil(t, err)
+	assert.True(t, found)
+
+	var sum int64
+	err = orm.Sum(context.Background(), getIDEntryTableName, getIDEntryOnCreateCountColumnName, goqu.Ex{}, &sum)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(22), sum)
+
+	rowsAffected, err := orm.UpdateWhere(context.Background(), getIDEntryTableName, goqu.Ex{"id": 1}, goqu.Record{
+		"string_col": "updated value 1",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), rowsAffected)
+
+	rowsAffected, err = orm.Unscoped().UpdateWhere(context.Background(), getIDEntryTableName, goqu.Ex{"id": 1}, goqu.Record{
+		"string_col": "updated value 1",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), rowsAffected)
+
+	// DeleteWhere() soft deletes the rows, like Delete()
+	rowsAffected, err = orm.DeleteWhere(context.Background(), getIDEntryTableName, goqu.Ex{"id": 3})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), rowsAffected)
+
+	deletedEntry = &softDeleteIDEntry{ID: 3}
+	err = orm.Unscoped().Get(context.Background(), deletedEntry)
+	assert.Nil(t, err)
+	assert.NotNil(t, deletedEntry.DeletedAt)
+
+	count, err = orm.Count(context.Background(), getIDEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+
+	// The soft deleted entry is restored by the update rather than created again
+	restoredEntry := &softDeleteIDEntry{ID: 1, StringCol: "restored value 1", BytesCol: []byte{}}
+	err = orm.CreateOrUpdate(context.Background(), restoredEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), restoredEntry.ID)
+
+	deletedEntry = &softDeleteIDEntry{ID: 1}
+	err = orm.Get(context.Background(), deletedEntry)
+	assert.Nil(t, err)
+	assert.Nil(t, deletedEntry.DeletedAt)
+	assert.Equal(t, "restored value 1", deletedEntry.StringCol)
+
+	err = orm.HardDelete(context.Background(), &softDeleteIDEntry{ID: 1})
+	assert.Nil(t, err)
+
+	err = orm.Unscoped().Get(context.Background(), &softDeleteIDEntry{ID: 1})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	count, err = orm.Unscoped().Count(context.Background(), getIDEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), count)
+
+	uniqueEntry := &softDeleteUniqueEntry{ID1: 1, ID2: 1, StringCol: "value 1", BytesCol: []byte{}}
+	err = orm.Create(context.Background(), uniqueEntry)
+	assert.Nil(t, err)
+
+	err = orm.Delete(context.Background(), uniqueEntry)
+	assert.Nil(t, err)
+	assert.NotNil(t, uniqueEntry.RemovedAt)
+
+	err = orm.Get(context.Background(), &softDeleteUniqueEntry{ID1: 1, ID2: 1})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	fetchedUniqueEntry := &softDeleteUniqueEntry{ID1: 1, ID2: 1}
+	err = orm.Unscoped().Get(context.Background(), fetchedUniqueEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, uniqueEntry.RemovedAt, fetchedUniqueEntry.RemovedAt)
+
+	// The unique columns of the soft deleted entry are still taken, so it is restored rather than created again
+	err = orm.CreateOrUpdate(context.Background(), &softDeleteUniqueEntry{ID1: 1, ID2: 1, StringCol: "restored value 1", BytesCol: []byte{}})
+	assert.Nil(t, err)
+
+	fetchedUniqueEntry = &softDeleteUniqueEntry{ID1: 1, ID2: 1}
+	err = orm.Get(context.Background(), fetchedUniqueEntry)
+	assert.Nil(t, err)
+	assert.Nil(t, fetchedUniqueEntry.RemovedAt)
+	assert.Equal(t, "restored value 1", fetchedUniqueEntry.StringCol)
+
+	err = orm.Unscoped().Delete(context.Background(), &softDeleteUniqueEntry{ID1: 1, ID2: 1})
+	assert.Nil(t, err)
+
+	err = orm.Delete(context.Background(), &getIDEntry{ID: 2})
+	assert.Nil(t, err)
+
+	count, err = orm.Unscoped().Count(context.Background(), getIDEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+// testUnregisteredSoftDelete expects softDeleteIDEntry to be missing from the Models of orm
+func testUnregisteredSoftDelete(t *testing.T, orm ORM) {
+	err := orm.Get(context.Background(), &softDeleteIDEntry{ID: 1})
+	assert.Nil(t, err)
+
+	_, err = orm.Count(context.Background(), getIDEntryTableName, goqu.Ex{})
+	assert.ErrorIs(t, err, ErrSoftDeleteModelNotRegistered)
+
+	_, err = orm.DeleteWhere(context.Background(), getIDEntryTableName, goqu.Ex{"id": 1})
+	assert.ErrorIs(t, err, ErrSoftDeleteModelNotRegistered)
+
+	_, err = orm.Unscoped().DeleteWhere(context.Background(), getIDEntryTableName, goqu.Ex{"id": 1})
+	assert.ErrorIs(t, err, ErrSoftDeleteModelNotRegistered)
+
+	count, err := orm.Unscoped().Count(context.Background(), getIDEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), count)
+}
+
+func testTaggedModel(t *testing.T, orm ORM, sequenceStart int64) {
+	entry := &taggedIDEntry{StringCol: "value 1", BytesCol: ([]byte)("bytes value 1")}
+	err := orm.Create(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, sequenceStart+1, entry.ID)
+
+	fetchedEntry := &taggedIDEntry{ID: entry.ID}
+	err = orm.Get(context.Background(), fetchedEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, entry, fetchedEntry)
+
+	fetchedEntry.StringCol = "updated value 1"
+	err = orm.Update(context.Background(), fetchedEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), fetchedEntry.Version)
+
+	err = orm.Update(context.Background(), entry)
+	assert.ErrorIs(t, err, ErrStaleEntry)
+
+	entryList := []*taggedIDEntry{
+		{StringCol: "value 2", BytesCol: ([]byte)("bytes value 2")},
+		{StringCol: "value 3", BytesCol: ([]byte)("bytes value 3")},
+	}
+	err = orm.CreateMany(context.Background(), entryList)
+	assert.Nil(t, err)
+	assert.Equal(t, sequenceStart+2, entryList[0].ID)
+	assert.Equal(t, sequenceStart+3, entryList[1].ID)
+
+	queriedEntryList := []*taggedIDEntry{}
+	err = orm.Query(context.Background(), QueryParams{
+		TableName:  getIDEntryTableName,
+		EntryList:  &queriedEntryList,
+		Expression: goqu.Ex{},
+		OrderBy:    []exp.OrderedExpression{goqu.C(getIDEntryIDColumnName).Asc()},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []*taggedIDEntry{fetchedEntry, entryList[0], entryList[1]}, queriedEntryList)
+
+	err = orm.Delete(context.Background(), &taggedIDEntry{ID: entry.ID})
+	assert.Nil(t, err)
+
+	err = orm.Get(context.Background(), &taggedIDEntry{ID: entry.ID})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	uniqueEntry := &taggedUniqueEntry{
+		taggedBaseEntry: taggedBaseEntry{ID1: 1, ID2: 2},
+		StringCol:       "value 1",
+		BytesCol:        ([]byte)("bytes value 1"),
+	}
+	err = orm.CreateOrUpdate(context.Background(), uniqueEntry)
+	assert.Nil(t, err)
+
+	uniqueEntry.StringCol = "updated value 1"
+	err = orm.CreateOrUpdate(context.Background(), uniqueEntry)
+	assert.Nil(t, err)
+
+	fetchedUniqueEntry := &taggedUniqueEntry{taggedBaseEntry: taggedBaseEntry{ID1: 1, ID2: 2}}
+	err = orm.Get(context.Background(), fetchedUniqueEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, uniqueEntry, fetchedUniqueEntry)
+
+	err = orm.Delete(context.Background(), fetchedUniqueEntry)
+	assert.Nil(t, err)
+
+	count, err := orm.Count(context.Background(), getUniqueEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Zero(t, count)
+}
+
+func testKeys(t *testing.T, orm ORM) {
+	entry := &taggedKeyEntry{ID: "key 1", StringCol: "value 1"}
+	err := orm.Create(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.Equal(t, "key 1", entry.ID)
+
+	fetchedEntry := &taggedKeyEntry{ID: "key 1"}
+	err = orm.Get(context.Background(), fetchedEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, entry, fetchedEntry)
+
+	fetchedEntry.StringCol = "updated value 1"
+	err = orm.Update(context.Background(), fetchedEntry)
+	assert.Nil(t, err)
+
+	entryList := []*taggedKeyEntry{
+		{ID: "key 2", StringCol: "value 2"},
+		{ID: "key 3", StringCol: "value 3"},
+	}
+	err = orm.CreateMany(context.Background(), entryList)
+	assert.Nil(t, err)
+
+	entryList[1].StringCol = "updated value 3"
+	err = orm.CreateOrUpdate(context.Background(), entryList[1])
+	assert.Nil(t, err)
+
+	queriedEntryList := []*taggedKeyEntry{}
+	err = orm.Query(context.Background(), QueryParams{
+		TableName:  getKeyEntryTableName,
+		EntryList:  &queriedEntryList,
+		Expression: goqu.Ex{},
+		OrderBy:    []exp.OrderedExpression{goqu.C(getKeyEntryIDColumnName).Asc()},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []*taggedKeyEntry{fetchedEntry, entryList[0], entryList[1]}, queriedEntryList)
+
+	err = orm.Delete(context.Background(), &taggedKeyEntry{ID: "key 1"})
+	assert.Nil(t, err)
+
+	err = orm.Get(context.Background(), &taggedKeyEntry{ID: "key 1"})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func testGeneratedKeys(t *testing.T, orm ORM) {
+	entry := &getKeyEntry{StringCol: "value 1"}
+	err := orm.Create(context.Background(), entry)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, entry.ID)
+
+	fetchedEntry := &getKeyEntry{ID: entry.ID}
+	err = orm.Get(context.Background(), fetchedEntry)
+	assert.Nil(t, err)
+	assert.Equal(t, entry, fetchedEntry)
+
+	fetchedEntry.StringCol = "updated value 1"
+	err = orm.Update(context.Background(), fetchedEntry)
+	assert.Nil(t, err)
+
+	entryList := []*getKeyEntry{
+		{StringCol: "value 2"},
+		{StringCol: "value 3"},
+	}
+	err = orm.CreateMany(context.Background(), entryList)
+	assert.Nil(t, err)
+	assert.NotEmpty(t, entryList[0].ID)
+	assert.NotEmpty(t, entryList[1].ID)
+	assert.NotEqual(t, entryList[0].ID, entryList[1].ID)
+
+	for _, createdEntry := range entryList {
+		fetchedEntry := &getKeyEntry{ID: createdEntry.ID}
+		err = orm.Get(context.Background(), fetchedEntry)
+		assert.Nil(t, err)
+		assert.Equal(t, createdEntry, fetchedEntry)
+	}
+
+	err = orm.Delete(context.Background(), &getKeyEntry{ID: entry.ID})
+	assert.Nil(t, err)
+
+	count, err := orm.Count(context.Background(), getKeyEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), count)
+}
+
+func testGeneratedKeysNotSupported(t *testing.T, orm ORM) {
+	err := orm.Create(context.Background(), &getKeyEntry{StringCol: "value 1"})
+	assert.ErrorIs(t, err, ErrGeneratedKeyNotSupported)
+
+	err = orm.CreateMany(context.Background(), []*getKeyEntry{{StringCol: "value 2"}})
+	assert.ErrorIs(t, err, ErrGeneratedKeyNotSupported)
+
+	count, err := orm.Count(context.Background(), getKeyEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Zero(t, count)
+}
+
+func testInstrumentation(t *testing.T, orm ORM) {
+	instrumentation := orm.(*instrumentedORM).instrumentation.(*recordingInstrumentation)
+
+	entry := &getIDEntry{StringCol: "value 2", BytesCol: []byte("bytes value 2")}
+	err := orm.Create(context.Background(), entry)
+	assert.Nil(t, err)
+
+	err = orm.Get(context.Background(), &getIDEntry{ID: 1})
+	assert.Nil(t, err)
+
+	err = orm.Get(context.Background(), &getIDEntry{ID: 1000})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = orm.WithTx(func(txORM ORM) error {
+		entry.StringCol = "value 3"
+		return txORM.Update(context.Background(), entry)
+	})
+	assert.Nil(t, err)
+
+	entries := make([]getIDEntry, 0)
+	err = orm.Query(context.Background(), QueryParams{
+		TableName: getIDEntryTableName,
+		EntryList: &entries,
+	})
+	assert.Nil(t, err)
+
+	rowsAffected, err := orm.DeleteWhere(context.Background(), getIDEntryTableName, goqu.Ex{})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), rowsAffected)
+
+	events := instrumentation.getEvents()
+	operations := make([]string, 0, len(events))
+
+	for _, event := range events {
+		operations = append(operations, event.Operation)
+		assert.True(t, event.Duration >= 0)
+		assert.False(t, event.StartTime.IsZero())
+	}
+
+	// The operations run in WithTx() end before it
+	if !assert.Equal(t, []string{
+		OperationCreate,
+		OperationGet,
+		OperationGet,
+		OperationUpdate,
+		OperationWithTx,
+		OperationQuery,
+		OperationDeleteWhere,
+	}, operations) {
+		return
+	}
+
+	assert.Equal(t, getIDEntryTableName, events[0].TableName)
+	assert.Contains(t, events[0].SQL, getIDEntryTableName)
+	assert.Greater(t, events[0].ArgCount, 0)
+	assert.Equal(t, int64(1), events[0].RowsAffected)
+	assert.Nil(t, events[0].Err)
+
+	assert.Equal(t, int64(1), events[1].RowsAffected)
+	assert.Nil(t, events[1].Err)
+
+	assert.Equal(t, int64(0), events[2].RowsAffected)
+	assert.ErrorIs(t, events[2].Err, ErrNotFound)
+
+	assert.Equal(t, getIDEntryTableName, events[3].TableName)
+	assert.Contains(t, events[3].SQL, getIDEntryTableName)
+	assert.Equal(t, int64(1), events[3].RowsAffected)
+
+	assert.Equal(t, "", events[4].TableName)
+	assert.Nil(t, events[4].Err)
+
+	assert.Equal(t, int64(2), events[5].RowsAffected)
+	assert.Equal(t, int64(2), events[6].RowsAffected)
+}
+
+func testStructuredLogging(t *testing.T, orm ORM) {
+	statementLogger := orm.GetDBWrapper().(*goqu.Database).Db.(*loggingSQLDatabase).statementLogger
+	logger := statementLogger.logger.(*recordingStructuredLogger)
+
+	// Redacted before sensitiveEntry is used, since it is one of the Models
+	_, err := orm.UpdateWhere(context.Background(), getIDEntryTableName, goqu.Ex{"id": 1000}, goqu.Record{
+		"bytes_col": []byte("secret bytes"),
+	})
+	assert.Nil(t, err)
+
+	entry := &sensitiveEntry{StringCol: "value 2", BytesCol: []byte("secret bytes")}
+	err = orm.Create(context.Background(), entry)
+	assert.Nil(t, err)
+
+	_, err = orm.UpdateWhere(context.Background(), getIDEntryTableName, goqu.Ex{"id": entry.ID}, goqu.Record{
+		"on_update_count": 4242,
+	})
+	assert.Nil(t, err)
+
+	// The values compared to a function of a redacted column, or on the left of the comparison, are redacted too
+	count, err := orm.Count(context.Background(), getIDEntryTableName, goqu.Or(
+		goqu.Func("ABS", goqu.C("on_update_count")).Eq(4242),
+		goqu.V(4242).Eq(goqu.C("on_update_count")),
+	))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), count)
+
+	err = orm.Get(context.Background(), &getIDEntry{ID: 1000})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = orm.Query(context.Background(), QueryParams{
+		TableName: "missing_table",
+		EntryList: &[]getIDEntry{},
+	})
+	assert.NotNil(t, err)
+
+	entries := logger.getEntries()
+	findEntry := func(sqlPrefix string) *recordedLogEntry {
+		for i := range entries {
+			if strings.HasPrefix(entries[i].Fields[LogFieldSQL].(string), sqlPrefix) {
+				return &entries[i]
+			}
+		}
+
+		return nil
+	}
+
+	for _, entry := range entries {
+		logged := fmt.Sprint(entry.Fields)
+		assert.NotContains(t, logged, "secret bytes")
+		assert.NotContains(t, logged, "4242")
+		assert.Equal(t, string(statementLogger.driverType), entry.Fields[LogFieldDriver])
+	}
+
+	insertEntry := findEntry("INSERT")
+	if assert.NotNil(t, insertEntry) {
+		assert.Equal(t, LogLevelDebug, insertEntry.Level)
+		assert.Contains(t, fmt.Sprint(insertEntry.Fields), RedactedValue)
+	}
+
+	updateEntry := findEntry("UPDATE")
+	if assert.NotNil(t, updateEntry) {
+		assert.Contains(t, fmt.Sprint(updateEntry.Fields), RedactedValue)
+	}
+
+	// An entry not found is not a failure of the statement
+	selectEntry := findEntry("SELECT")
+	if assert.NotNil(t, selectEntry) {
+		assert.Equal(t, LogLevelDebug, selectEntry.Level)
+	}
+
+	if !assert.NotEmpty(t, entries) {
+		return
+	}
+
+	lastEntry := entries[len(entries)-1]
+	assert.Equal(t, LogLevelError, lastEntry.Level)
+	assert.Equal(t, "statement failed", lastEntry.Message)
+	assert.NotNil(t, lastEntry.Fields[LogFieldError])
+}
+
+func testHealth(t *testing.T, orm ORM, driverType DriverType) {
+	err := orm.Ping(context.Background())
+	assert.Nil(t, err)
+
+	status, err := orm.HealthCheck(context.Background())
+	assert.Nil(t, err)
+	assert.Nil(t, status.Err)
+	assert.Equal(t, driverType, status.Driver)
+	assert.NotEmpty(t, status.ServerVersion)
+	assert.Positive(t, status.Latency)
+	assert.Zero(t, status.Stats.InUse)
+	assert.Empty(t, status.Replicas)
+
+	err = orm.WithTx(func(txORM ORM) error {
+		return txORM.Close()
+	})
+	assert.ErrorIs(t, err, ErrCloseInTransaction)
+
+	err = orm.Close()
+	assert.Nil(t, err)
+
+	err = orm.Ping(context.Background())
+	assert.NotNil(t, err)
+
+	status, err = orm.HealthCheck(context.Background())
+	assert.NotNil(t, err)
+	assert.Equal(t, err, status.Err)
+	assert.Empty(t, status.ServerVersion)
+}
+
+// sqlDatabaseOnly hides the methods of a *sql.DB other than the ones of goqu.SQLDatabase
+type sqlDatabaseOnly struct {
+	goqu.SQLDatabase
+}
+
+func testFromSQLDatabase(t *testing.T, databaseConfig DatabaseConfig) {
+	db, err := NewSQLDatabase(databaseConfig)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	defer db.Close()
+
+	testSharedDatabase := func(orm ORM) {
+		entry := &getIDEntry{ID: 1}
+		err := orm.Get(context.Background(), entry)
+		assert.Nil(t, err)
+		assert.Equal(t, "value 1", entry.StringCol)
+
+		// The shared database is left open, and can be used by another ORM
+		err = orm.Close()
+		assert.Nil(t, err)
+		assert.Nil(t, db.Ping())
+		assert.Nil(t, orm.Ping(context.Background()))
+	}
+
+	// The connection settings of databaseConfig are ignored
+	sharedDatabaseConfig := databaseConfig
+	sharedDatabaseConfig.Host, sharedDatabaseConfig.Port, sharedDatabaseConfig.URL = "", 0, ""
+
+	orm, err := NewORMFromSQLDatabase(db, sharedDatabaseConfig)
+	assert.Nil(t, err)
+	testSharedDatabase(orm)
+
+	orm, err = NewORMFromGoquDatabase(goqu.New(configDriverTypeToDialect[databaseConfig.Driver], db), sharedDatabaseConfig)
+	assert.Nil(t, err)
+	testSharedDatabase(orm)
+
+	_, err = NewORMFromGoquDatabase(goqu.New("default", db), sharedDatabaseConfig)
+	assert.ErrorIs(t, err, ErrDialectMismatch)
+
+	_, err = NewORMFromGoquDatabase(
+		goqu.New(configDriverTypeToDialect[databaseConfig.Driver], &sqlDatabaseOnly{SQLDatabase: db}),
+		sharedDatabaseConfig,
+	)
+	assert.ErrorIs(t, err, ErrUnsupportedGoquDatabase)
+
+	// The statements are recorded for the instrumentation, while goqu still logs with the logger of the shared database
+	instrumentation := &recordingInstrumentation{}
+	instrumentedDatabaseConfig := sharedDatabaseConfig
+	instrumentedDatabaseConfig.Instrumentation = instrumentation
+
+	goquLogger := &printfLogger{}
+	goquDB := goqu.New(configDriverTypeToDialect[databaseConfig.Driver], db)
+	goquDB.Logger(goquLogger)
+
+	orm, err = NewORMFromGoquDatabase(goquDB, instrumentedDatabaseConfig)
+	if !assert.Nil(t, err) {
+		return
+	}
+
+	err = orm.Get(context.Background(), &getIDEntry{ID: 1})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, goquLogger.lines)
+
+	events := instrumentation.getEvents()
+	if assert.Len(t, events, 1) {
+		assert.Contains(t, events[0].SQL, getIDEntryTableName)
+		assert.NotZero(t, events[0].ArgCount)
+	}
+}
+
+func testWhereWithZeroLimit(t *testing.T, orm ORM) {
+	rowsAffected, err := orm.UpdateWhereWithLimit(
+		context.Background(),
+		getIDEntryTableName,
+		goqu.Ex{},
+		goqu.Record{getIDEntryOnUpdateCountColumnName: 1},
+		0,
+	)
+	assert.Nil(t, err)
+	assert.Zero(t, rowsAffected)
+
+	rowsAffected, err = orm.DeleteWhereWithLimit(context.Background(), getIDEntryTableName, goqu.Ex{}, 0)
+	assert.Nil(t, err)
+	assert.Zero(t, rowsAffected)
+
+	count, err := orm.Count(context.Background(), getIDEntryTableName, goqu.C(getIDEntryOnUpdateCountColumnName).Eq(0))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), count)
+}
+
+func testAggregateEmptyTable(t *testing.T, orm ORM) {
+	sum := int64(1)
+	err := orm.Sum(context.Background(), getIDEntryTableName, getIDEntryOnCreateCountColumnName, nil, &sum)
+	assert.Nil(t, err)
+	assert.Zero(t, sum)
+
+	// The other aggregates of no rows are NULL, which only nullable results can hold
+	min := int64(1)
+	err = orm.Min(context.Background(), getIDEntryTableName, getIDEntryOnCreateCountColumnName, nil, &min)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Equal(t, int64(1), min)
+
+	max := sql.NullInt64{Int64: 1, Valid: true}
+	err = orm.Max(context.Background(), getIDEntryTableName, getIDEntryOnCreateCountColumnName, nil, &max)
+	assert.Nil(t, err)
+	assert.False(t, max.Valid)
+
+	var avg float64
+	err = orm.Avg(context.Background(), getIDEntryTableName, getIDEntryOnCreateCountColumnName, nil, &avg)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	avgPointer := new(float64)
+	err = orm.Avg(context.Background(), getIDEntryTableName, getIDEntryOnCreateCountColumnName, nil, &avgPointer)
+	assert.Nil(t, err)
+	assert.Nil(t, avgPointer)
+}